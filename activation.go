@@ -1,7 +1,6 @@
 package goDeep
 
 import (
-	"fmt"
 	"math"
 )
 
@@ -26,7 +25,8 @@ type Sigmoid struct{}
 func (s *Sigmoid) activate(x float64) (float64, error) {
 	exp := math.Exp(-x)
 	if exp == 0 || math.IsInf(exp, 0) {
-		return 0, fmt.Errorf("The activation value is too large: %f", x)
+		// Out of a domain of float64 exponents a sigmoid is not distinguishable from 0 or 1.
+		return 0, &NumericError{Stage: "activations", Value: x}
 	}
 	return 1 / (1 + exp), nil
 }
@@ -38,3 +38,57 @@ func (s *Sigmoid) actDerivative(n float64) (float64, error) {
 	}
 	return actVal * (1 - actVal), err
 }
+
+// Relu activation passes positive inputs and zeroes negative ones: max(0, x).
+type Relu struct{}
+
+func (r *Relu) activate(x float64) (float64, error) {
+	return math.Max(0, x), nil
+}
+
+func (r *Relu) actDerivative(x float64) (float64, error) {
+	if x > 0 {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// Tanh activation is a hyperbolic tangent, a sigmoid curve between -1 and 1.
+type Tanh struct{}
+
+func (t *Tanh) activate(x float64) (float64, error) {
+	return math.Tanh(x), nil
+}
+
+func (t *Tanh) actDerivative(x float64) (float64, error) {
+	th := math.Tanh(x)
+	return 1 - th*th, nil
+}
+
+/*
+Softmax activation normalizes a layer into probabilities summing to one:
+
+	   e^xi
+	---------
+	 ∑j e^xj
+
+It depends on all the neurons of a layer, so it activates layers, not single values.
+*/
+type Softmax struct{}
+
+func (s *Softmax) activate(x float64) (float64, error) {
+	return 0, &ConfigError{Field: "activation", Value: "softmax", Reason: "activates a whole layer, not a value"}
+}
+
+func (s *Softmax) actDerivative(x float64) (float64, error) {
+	return 0, &ConfigError{Field: "activation", Value: "softmax", Reason: "activates a whole layer, not a value"}
+}
+
+func (s *Softmax) layerOp() operation {
+	return softmaxOp{}
+}
+
+// layerActivation is an activation of a whole layer by an operation.
+type layerActivation interface {
+	layerOp() operation
+}
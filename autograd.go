@@ -0,0 +1,184 @@
+package goDeep
+
+import "math"
+
+/*
+operation is a differentiable computation of a graph node.
+
+forward computes a node value out of values of its inputs. gradient is a registered
+gradient of the operation: given a gradient of a loss with respect to the node value
+it returns gradients with respect to each of the inputs, nil for inputs which are not
+differentiable. Thus computations composed of operations only need forward definitions,
+a backward pass is obtained automatically.
+*/
+type operation interface {
+	forward(inputs ...*Tensor) (*Tensor, error)
+	gradient(grad, output *Tensor, inputs ...*Tensor) ([]*Tensor, error)
+}
+
+/*
+variable is a node of a computational graph.
+
+The graph is defined by run: every operation applied to variables computes its value
+immediately and remembers its inputs. Leaf variables (without an operation) are inputs
+and parameters of a computation, their gradients are accumulated by each backward pass.
+*/
+type variable struct {
+	value, grad *Tensor
+	op          operation
+	parents     []*variable
+}
+
+func newVariable(value *Tensor) *variable {
+	return &variable{value: value}
+}
+
+func scalarVariable(value float64) *variable {
+	return newVariable(Scalar(value))
+}
+
+// apply an operation to variables producing a new graph node.
+func apply(op operation, inputs ...*variable) (*variable, error) {
+	values := make([]*Tensor, len(inputs))
+	for i, in := range inputs {
+		values[i] = in.value
+	}
+	value, err := op.forward(values...)
+	if err != nil {
+		return nil, err
+	}
+	return &variable{value: value, op: op, parents: inputs}, nil
+}
+
+// topology returns nodes of a graph ending in a variable in a topological order.
+func (v *variable) topology() (order []*variable) {
+	visited := make(map[*variable]bool)
+	var visit func(*variable)
+	visit = func(n *variable) {
+		if visited[n] {
+			return
+		}
+		visited[n] = true
+		for _, p := range n.parents {
+			visit(p)
+		}
+		order = append(order, n)
+	}
+	visit(v)
+	return
+}
+
+// backward propagates a seed gradient of a variable value to all the nodes of its graph.
+func (v *variable) backward(seed *Tensor) (err error) {
+	if !sameShape(seed.shape, v.value.shape) {
+		return &ShapeMismatchError{Op: "Backward", Expected: v.value.Shape(), Actual: seed.Shape()}
+	}
+
+	order := v.topology()
+	// Intermediate results belong to a single pass, unlike leaves.
+	for _, n := range order {
+		if n.op != nil {
+			n.grad = nil
+		}
+	}
+	if v.grad, err = accumulate(v.grad, seed, v.value.Precision()); err != nil {
+		return
+	}
+
+	var grads []*Tensor
+	for i := len(order) - 1; i >= 0; i-- {
+		n := order[i]
+		if n.op == nil || n.grad == nil {
+			continue
+		}
+		values := make([]*Tensor, len(n.parents))
+		for j, p := range n.parents {
+			values[j] = p.value
+		}
+		if grads, err = n.op.gradient(n.grad, n.value, values...); err != nil {
+			return
+		}
+		for j, p := range n.parents {
+			if grads[j] == nil {
+				continue
+			}
+			if p.grad, err = accumulate(p.grad, grads[j], p.value.Precision()); err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+// backprop propagates a gradient of a scalar variable, e.g. a loss.
+func (v *variable) backprop() error {
+	if v.value.Size() != 1 {
+		return &ShapeMismatchError{Op: "Backward", Expected: nil, Actual: v.value.Shape()}
+	}
+	return v.backward(newTensor([]float64{1}, v.value.shape))
+}
+
+// accumulate adds a gradient to an accumulated one. Nil accumulated gradient is allocated
+// of a precision of a variable.
+func accumulate(acc, grad *Tensor, p Precision) (*Tensor, error) {
+	if acc == nil {
+		return grad.cloneAs(p), nil
+	}
+	return acc, acc.addInPlace(grad)
+}
+
+/*
+graphActivation is an activation defined by a forward computation on a graph.
+
+The derivative is obtained by the automatic differentiation, so a new activation
+only needs a forward definition.
+*/
+type graphActivation func(*variable) (*variable, error)
+
+func (f graphActivation) activate(x float64) (float64, error) {
+	out, err := f(scalarVariable(x))
+	if err != nil {
+		return 0, err
+	}
+	return out.value.At(), nil
+}
+
+func (f graphActivation) actDerivative(x float64) (float64, error) {
+	in := scalarVariable(x)
+	out, err := f(in)
+	if err != nil {
+		return 0, err
+	}
+	if err = out.backprop(); err != nil {
+		return 0, err
+	}
+	return in.grad.At(), nil
+}
+
+/*
+graphCost is a cost defined by a forward computation on a graph.
+
+The cost takes a prediction and labels vectors and returns a scalar. The derivative
+is obtained by the automatic differentiation, so a new cost only needs a forward definition.
+*/
+type graphCost func(prediction, labels *variable) (*variable, error)
+
+func (f graphCost) countCost(al, er []float64) float64 {
+	out, err := f(newVariable(Vector(al)), newVariable(Vector(er)))
+	if err != nil || out.value.Size() != 1 {
+		return math.NaN()
+	}
+	return out.value.Data()[0]
+}
+
+func (f graphCost) costDerivative(a, e float64) float64 {
+	pred := scalarVariable(a)
+	out, err := f(pred, scalarVariable(e))
+	if err != nil {
+		return math.NaN()
+	}
+	if err = out.backprop(); err != nil {
+		return math.NaN()
+	}
+	return pred.grad.At()
+}
@@ -0,0 +1,150 @@
+package goDeep
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func sigmoidGraph(x *variable) (out *variable, err error) {
+	if out, err = apply(scaleOp(-1), x); err != nil {
+		return
+	}
+	if out, err = apply(expOp, out); err != nil {
+		return
+	}
+	if out, err = apply(shiftOp(1), out); err != nil {
+		return
+	}
+	return apply(reciprocalOp, out)
+}
+
+func quadraticGraph(prediction, labels *variable) (out *variable, err error) {
+	if out, err = apply(subOp{}, prediction, labels); err != nil {
+		return
+	}
+	if out, err = apply(squareOp, out); err != nil {
+		return
+	}
+	if out, err = apply(sumOp{}, out); err != nil {
+		return
+	}
+	return apply(scaleOp(.5), out)
+}
+
+func Test_graphActivation(t *testing.T) {
+	tests := []struct {
+		name string
+		x    float64
+	}{
+		{name: "zero", x: 0},
+		{name: "positive", x: 1.5},
+		{name: "negative", x: -3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			act := graphActivation(sigmoidGraph)
+			sigmoid := new(Sigmoid)
+
+			got, err := act.activate(tt.x)
+			if err != nil {
+				t.Fatalf("graphActivation.activate() error = %v", err)
+			}
+			want, _ := sigmoid.activate(tt.x)
+			if math.Abs(got-want) > 1e-12 {
+				t.Errorf("graphActivation.activate() = %v, want %v", got, want)
+			}
+
+			got, err = act.actDerivative(tt.x)
+			if err != nil {
+				t.Fatalf("graphActivation.actDerivative() error = %v", err)
+			}
+			want, _ = sigmoid.actDerivative(tt.x)
+			if math.Abs(got-want) > 1e-12 {
+				t.Errorf("graphActivation.actDerivative() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func Test_graphCost(t *testing.T) {
+	tests := []struct {
+		name       string
+		prediction []float64
+		labels     []float64
+	}{
+		{name: "vector", prediction: []float64{.2, .7, 1}, labels: []float64{0, 1, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := graphCost(quadraticGraph)
+			quadratic := new(Quadratic)
+
+			if got, want := c.countCost(tt.prediction, tt.labels), quadratic.countCost(tt.prediction, tt.labels); math.Abs(got-want) > 1e-12 {
+				t.Errorf("graphCost.countCost() = %v, want %v", got, want)
+			}
+			for i, a := range tt.prediction {
+				if got, want := c.costDerivative(a, tt.labels[i]), quadratic.costDerivative(a, tt.labels[i]); math.Abs(got-want) > 1e-12 {
+					t.Errorf("graphCost.costDerivative() = %v, want %v", got, want)
+				}
+			}
+		})
+	}
+}
+
+func Test_variable_backward(t *testing.T) {
+	tests := []struct {
+		name      string
+		left      [][]float64
+		right     [][]float64
+		wantLeft  [][]float64
+		wantRight [][]float64
+		wantErr   bool
+	}{
+		{
+			name:      "matMulSum",
+			left:      [][]float64{{1, 2}, {3, 4}},
+			right:     [][]float64{{5}, {6}},
+			wantLeft:  [][]float64{{5, 6}, {5, 6}},
+			wantRight: [][]float64{{4}, {6}},
+		},
+		{
+			name:    "inconsistentShapes",
+			left:    [][]float64{{1, 2}, {3, 4}},
+			right:   [][]float64{{5}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			left, right := newVariable(rowsOf(tt.left)), newVariable(rowsOf(tt.right))
+			product, err := apply(matMulOp{}, left, right)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("apply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			sum, err := apply(sumOp{}, product)
+			if err != nil {
+				t.Fatalf("apply() error = %v", err)
+			}
+			if err = sum.backprop(); err != nil {
+				t.Fatalf("variable.backprop() error = %v", err)
+			}
+			if !reflect.DeepEqual(left.grad.Rows(), tt.wantLeft) {
+				t.Errorf("left.grad = %v, want %v", left.grad.Rows(), tt.wantLeft)
+			}
+			if !reflect.DeepEqual(right.grad.Rows(), tt.wantRight) {
+				t.Errorf("right.grad = %v, want %v", right.grad.Rows(), tt.wantRight)
+			}
+		})
+	}
+}
+
+func Test_variable_backprop(t *testing.T) {
+	v := newVariable(Vector([]float64{1, 2}))
+	if err := v.backprop(); err == nil {
+		t.Errorf("variable.backprop() of a vector error = nil, want error")
+	}
+}
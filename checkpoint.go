@@ -0,0 +1,232 @@
+package goDeep
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+/*
+Checkpoint is a resumable state of learning.
+
+Epoch is a current epoch and Batch is a number of learned batches of it, Step is a number
+of learned batches of all the epochs. Parameters are weights of layers by names and pruning
+masks of them, e.g. "input.synapses.mask". Rates are learning rates of layers set by
+shapes, Scheduler is a state of a stateful schedule and Seed is a seed of a shuffled order
+of samples.
+*/
+type Checkpoint struct {
+	Epoch, Batch, Step int
+	BatchSize          int
+	Parameters         map[string]*Tensor
+	Rates              []float64
+	Scheduler          []float64
+	Shuffle            bool
+	Seed               int64
+	History            History
+}
+
+// CheckpointPolicy defines when a checkpoint is saved: every Epochs epochs, every Batches
+// batches or on every epoch improving a validation cost if BestValidation is set.
+// Zero values disable a trigger.
+type CheckpointPolicy struct {
+	Epochs, Batches int
+	BestValidation  bool
+}
+
+type checkpointConfig struct {
+	path   string
+	policy CheckpointPolicy
+	best   float64
+}
+
+// statefulScheduler is a schedule depending on observed costs. Its state is saved to
+// checkpoints.
+type statefulScheduler interface {
+	state() []float64
+	restore([]float64)
+}
+
+/*
+WithCheckpoints saves a checkpoint to a file of a path by a policy. A file is replaced by
+every next checkpoint. BestValidation policy requires WithValidation option.
+*/
+func WithCheckpoints(path string, policy CheckpointPolicy) LearnOption {
+	return func(c *learnConfig) {
+		c.checkpoints = &checkpointConfig{path: path, policy: policy, best: math.Inf(1)}
+	}
+}
+
+/*
+WithResume continues learning from a checkpoint: weights, learning rates, schedule state,
+shuffled order and a history are restored and learning starts from a batch following the
+last learned one. A network, data and a batch size must be the same as of a checkpoint.
+*/
+func WithResume(c *Checkpoint) LearnOption {
+	return func(config *learnConfig) {
+		config.resume = c
+	}
+}
+
+func (c *checkpointConfig) everyBatch(step int) bool {
+	return c != nil && c.policy.Batches > 0 && step%c.policy.Batches == 0
+}
+
+func (c *checkpointConfig) everyEpoch(epoch int) bool {
+	return c != nil && c.policy.Epochs > 0 && epoch%c.policy.Epochs == 0
+}
+
+// improved reports a best validation cost to be saved.
+func (c *checkpointConfig) improved(cost float64) bool {
+	if c == nil || !c.policy.BestValidation || !(cost < c.best) {
+		return false
+	}
+	c.best = cost
+	return true
+}
+
+// SaveCheckpoint to a file. A file is written at once, so a crash while saving keeps
+// a previous checkpoint.
+func SaveCheckpoint(path string, c *Checkpoint) (err error) {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if err = gob.NewEncoder(tmp).Encode(c); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// LoadCheckpoint from a file.
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	c := new(Checkpoint)
+	if err = gob.NewDecoder(f).Decode(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (n *Perceptron) saveCheckpoint(config *learnConfig, c *Checkpoint) error {
+	if config.checkpoints == nil {
+		return nil
+	}
+	c.Parameters = parametersOf(n)
+	for _, p := range n.parameters() {
+		if p.mask != nil && *p.mask != nil {
+			c.Parameters[p.name+maskSuffix] = *p.mask
+		}
+	}
+	if s, ok := config.scheduler.(statefulScheduler); ok {
+		c.Scheduler = s.state()
+	}
+	c.Shuffle, c.Seed = config.shuffle, config.seed
+	return SaveCheckpoint(config.checkpoints.path, c)
+}
+
+// restore a state of a network and learning from a checkpoint.
+func (n *Perceptron) restore(c *Checkpoint, batchSize int, config *learnConfig) error {
+	if c.BatchSize != batchSize {
+		return &ConfigError{Field: "batchSize", Value: batchSize, Reason: fmt.Sprintf("a checkpoint is learned by batches of %d", c.BatchSize)}
+	}
+	if len(c.Rates) != len(n.rates()) {
+		return &ConfigError{Field: "Checkpoint.Rates", Value: c.Rates, Reason: fmt.Sprintf("a network has %d layers learned", len(n.rates()))}
+	}
+	if err := loadParameters(n.parameters(), c.Parameters); err != nil {
+		return err
+	}
+	if err := loadMasks(n.parameters(), c.Parameters); err != nil {
+		return err
+	}
+	n.setRates(c.Rates, 1)
+
+	if s, ok := config.scheduler.(statefulScheduler); ok && c.Scheduler != nil {
+		s.restore(c.Scheduler)
+	}
+	config.shuffle, config.seed = c.Shuffle, c.Seed
+	if config.checkpoints != nil {
+		for _, cost := range c.History.ValidationCost {
+			config.checkpoints.best = math.Min(config.checkpoints.best, cost)
+		}
+	}
+	return nil
+}
+
+// maskSuffix of a name of a pruning mask of a parameter in a checkpoint.
+const maskSuffix = ".mask"
+
+// loadMasks of parameters, a parameter without a saved mask is not pruned.
+func loadMasks(params []parameter, saved map[string]*Tensor) error {
+	for _, p := range params {
+		if p.mask == nil {
+			continue
+		}
+		mask, ok := saved[p.name+maskSuffix]
+		if !ok {
+			*p.mask = nil
+			continue
+		}
+		if !sameShape(mask.Shape(), p.value.Shape()) {
+			return &ShapeMismatchError{Op: "Mask " + p.name, Expected: p.value.Shape(), Actual: mask.Shape()}
+		}
+		*p.mask = mask.Clone()
+	}
+	return nil
+}
+
+// tensorData is a serialized form of a tensor, elements of float32 tensors are Data32.
+type tensorData struct {
+	Shape  []int
+	Data   []float64
+	Data32 []float32
+}
+
+// GobEncode serializes a tensor in a row-major order keeping its precision.
+func (t *Tensor) GobEncode() ([]byte, error) {
+	d := tensorData{Shape: t.Shape()}
+	if t.Precision() == Float32 {
+		d.Data32 = DataOf[float32](t)
+	} else {
+		d.Data = t.Data()
+	}
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(d)
+	return buf.Bytes(), err
+}
+
+// GobDecode restores a tensor serialized by GobEncode.
+func (t *Tensor) GobDecode(b []byte) error {
+	var d tensorData
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&d); err != nil {
+		return err
+	}
+	decoded, err := NewTensor(d.Data, d.Shape...)
+	if d.Data32 != nil {
+		decoded, err = NewTensorOf(d.Data32, d.Shape...)
+	}
+	if err != nil {
+		return err
+	}
+	*t = *decoded
+	return nil
+}
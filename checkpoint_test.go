@@ -0,0 +1,142 @@
+package goDeep
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// newCheckpointedPerceptron with weights copied from a network if it is given.
+func newCheckpointedPerceptron(t *testing.T, weights backwardPropagation) *Perceptron {
+	t.Helper()
+	n, err := NewPerceptron(
+		InputShape{Size: 3, LearningRate: .5, Bias: 1},
+		[]HiddenShape{{Size: 4, LearningRate: .5, Bias: 1, Activation: new(Sigmoid)}},
+		OutputShape{Size: 1, Activation: new(Sigmoid), Cost: new(Quadratic)},
+	)
+	if err != nil {
+		t.Fatalf("NewPerceptron() error = %v", err)
+	}
+	if weights == nil {
+		return n.(*Perceptron)
+	}
+	for i, p := range weights.parameters() {
+		if err = n.parameters()[i].value.copyFrom(p.value); err != nil {
+			t.Fatalf("Tensor.copyFrom() error = %v", err)
+		}
+	}
+	return n.(*Perceptron)
+}
+
+func TestPerceptron_Learn_resume(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checkpoint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "learning.gob")
+
+	set := rowsOf([][]float64{{0, 0, 0}, {0, 1, 0}, {1, 0, 0}, {1, 1, 0}, {.5, .5, 0}})
+	labels := rowsOf([][]float64{{0}, {1}, {1}, {0}, {1}})
+	initial := newCheckpointedPerceptron(t, nil)
+
+	uninterrupted := newCheckpointedPerceptron(t, initial)
+	want, err := uninterrupted.Learn(set, labels, 2, 2, WithShuffle(7))
+	if err != nil {
+		t.Fatalf("Perceptron.Learn() error = %v", err)
+	}
+
+	// Learning is interrupted after a fourth batch, a checkpoint is saved every two batches.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	interrupted := newCheckpointedPerceptron(t, initial)
+	_, err = interrupted.LearnContext(
+		ctx, set, labels, 2, 2,
+		WithShuffle(7), WithScheduler(cancelScheduler{cancel, 3}, PerBatch), WithCheckpoints(path, CheckpointPolicy{Batches: 2}),
+	)
+	if err != context.Canceled {
+		t.Fatalf("Perceptron.LearnContext() error = %v, want %v", err, context.Canceled)
+	}
+
+	checkpoint, err := LoadCheckpoint(path)
+	if err != nil {
+		t.Fatalf("LoadCheckpoint() error = %v", err)
+	}
+	if checkpoint.Epoch != 1 || checkpoint.Batch != 1 || checkpoint.Step != 4 {
+		t.Errorf("Checkpoint counters = %d, %d, %d, want 1, 1, 4", checkpoint.Epoch, checkpoint.Batch, checkpoint.Step)
+	}
+
+	resumed := newCheckpointedPerceptron(t, initial)
+	got, err := resumed.Learn(set, labels, 2, 2, WithResume(checkpoint))
+	if err != nil {
+		t.Fatalf("Perceptron.Learn() resumed error = %v", err)
+	}
+	if !reflect.DeepEqual(got.Cost, want.Cost) {
+		t.Errorf("resumed History.Cost = %v, want %v", got.Cost, want.Cost)
+	}
+	wantParams := uninterrupted.parameters()
+	for i, p := range resumed.parameters() {
+		if !reflect.DeepEqual(p.value.Data(), wantParams[i].value.Data()) {
+			t.Errorf("resumed %s = %v, want %v", p.name, p.value.Data(), wantParams[i].value.Data())
+		}
+	}
+
+	if _, err = resumed.Learn(set, labels, 2, 3, WithResume(checkpoint)); err == nil {
+		t.Errorf("Perceptron.Learn() resumed with another batch size error = nil, want error")
+	}
+}
+
+func TestPerceptron_Learn_resumePruning(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checkpoint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "learning.gob")
+
+	set := rowsOf([][]float64{{0, 0, 0}, {0, 1, 0}, {1, 0, 0}, {1, 1, 0}, {.5, .5, 0}})
+	labels := rowsOf([][]float64{{0}, {1}, {1}, {0}, {1}})
+	// Synapses are pruned after batches 1, 3 and 5, learning is interrupted after a fourth one.
+	pruning := PruningSchedule{PruneOptions: PruneOptions{Sparsity: .5}, Begin: 1, End: 5, Frequency: 2}
+	initial := newCheckpointedPerceptron(t, nil)
+
+	uninterrupted := newCheckpointedPerceptron(t, initial)
+	if _, err = uninterrupted.Learn(set, labels, 2, 2, WithPruning(pruning)); err != nil {
+		t.Fatalf("Perceptron.Learn() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	interrupted := newCheckpointedPerceptron(t, initial)
+	_, err = interrupted.LearnContext(
+		ctx, set, labels, 2, 2,
+		WithPruning(pruning), WithScheduler(cancelScheduler{cancel, 3}, PerBatch), WithCheckpoints(path, CheckpointPolicy{Batches: 2}),
+	)
+	if err != context.Canceled {
+		t.Fatalf("Perceptron.LearnContext() error = %v, want %v", err, context.Canceled)
+	}
+	checkpoint, err := LoadCheckpoint(path)
+	if err != nil {
+		t.Fatalf("LoadCheckpoint() error = %v", err)
+	}
+	if checkpoint.Parameters["input.synapses.mask"] == nil {
+		t.Fatalf("Checkpoint.Parameters = %v, want a mask of input synapses", checkpoint.Parameters)
+	}
+
+	resumed := newCheckpointedPerceptron(t, initial)
+	if _, err = resumed.Learn(set, labels, 2, 2, WithPruning(pruning), WithResume(checkpoint)); err != nil {
+		t.Fatalf("Perceptron.Learn() resumed error = %v", err)
+	}
+	wantParams := uninterrupted.parameters()
+	for i, p := range resumed.parameters() {
+		if !reflect.DeepEqual(p.value.Data(), wantParams[i].value.Data()) {
+			t.Errorf("resumed %s = %v, want %v", p.name, p.value.Data(), wantParams[i].value.Data())
+		}
+		if !reflect.DeepEqual((*p.mask).Data(), (*wantParams[i].mask).Data()) {
+			t.Errorf("resumed mask of %s = %v, want %v", p.name, (*p.mask).Data(), (*wantParams[i].mask).Data())
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package goDeep
+
+import "math"
+
+/*
+Clipping of mean gradients of a batch applied before corrections. Gradient elements are
+clipped by Value first, then gradients of every layer are scaled down to LayerNorm and
+all the gradients are scaled down to GlobalNorm of L2 norm. Zero thresholds disable
+a clipping.
+
+A number of triggered clippings is recorded to a history for every batch: a clipped
+element, a layer scaled by its norm and a global scaling count once each.
+*/
+type Clipping struct {
+	Value      float64 `json:"value,omitempty"`
+	LayerNorm  float64 `json:"layerNorm,omitempty"`
+	GlobalNorm float64 `json:"globalNorm,omitempty"`
+}
+
+// clip accumulated corrections of layers. Thresholds are scaled by a batch size because
+// corrections are sums of gradients of a batch.
+func (c Clipping) clip(layers [][]parameter, batchSize float64) (clipped int) {
+	if c.Value > 0 {
+		limit := c.Value * batchSize
+		for _, params := range layers {
+			for _, p := range params {
+				p.mapCorrections(func(g float64) float64 {
+					if math.Abs(g) <= limit {
+						return g
+					}
+					clipped++
+					return math.Copysign(limit, g)
+				})
+			}
+		}
+	}
+
+	if c.LayerNorm > 0 {
+		for _, params := range layers {
+			if scaleToNorm(params, c.LayerNorm*batchSize) {
+				clipped++
+			}
+		}
+	}
+
+	if c.GlobalNorm > 0 {
+		var all []parameter
+		for _, params := range layers {
+			all = append(all, params...)
+		}
+		if scaleToNorm(all, c.GlobalNorm*batchSize) {
+			clipped++
+		}
+	}
+	return
+}
+
+// correctionsNorm is L2 norm of corrections of parameters.
+func correctionsNorm(params []parameter) float64 {
+	var sum float64
+	for _, p := range params {
+		corr := p.corrections()
+		if corr == nil {
+			continue
+		}
+		corr.each(func(pos int) {
+			v := corr.data.at(pos)
+			sum += v * v
+		})
+	}
+	return math.Sqrt(sum)
+}
+
+// scaleToNorm scales corrections of parameters down if their norm exceeds a limit.
+func scaleToNorm(params []parameter, limit float64) bool {
+	norm := correctionsNorm(params)
+	if norm <= limit {
+		return false
+	}
+	scale := limit / norm
+	for _, p := range params {
+		p.mapCorrections(func(g float64) float64 {
+			return g * scale
+		})
+	}
+	return true
+}
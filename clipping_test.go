@@ -0,0 +1,86 @@
+package goDeep
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestClipping_clip(t *testing.T) {
+	tests := []struct {
+		name        string
+		clipping    Clipping
+		layers      [][][]float64
+		batchSize   float64
+		want        [][][]float64
+		wantClipped int
+	}{
+		{
+			name:        "value",
+			clipping:    Clipping{Value: 1},
+			layers:      [][][]float64{{{3, -.5}, {-4, 1}}},
+			batchSize:   2,
+			want:        [][][]float64{{{2, -.5}, {-2, 1}}},
+			wantClipped: 2,
+		},
+		{
+			name:        "layerNorm",
+			clipping:    Clipping{LayerNorm: 1},
+			layers:      [][][]float64{{{3, 4}}, {{.3, .4}}},
+			batchSize:   1,
+			want:        [][][]float64{{{.6, .8}}, {{.3, .4}}},
+			wantClipped: 1,
+		},
+		{
+			name:        "globalNorm",
+			clipping:    Clipping{GlobalNorm: 2.5},
+			layers:      [][][]float64{{{3, 4}}, {{0, 0}}},
+			batchSize:   1,
+			want:        [][][]float64{{{1.5, 2}}, {{0, 0}}},
+			wantClipped: 1,
+		},
+		{
+			name:      "disabled",
+			layers:    [][][]float64{{{300, 400}}},
+			batchSize: 1,
+			want:      [][][]float64{{{300, 400}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var layers [][]parameter
+			corrections := make([]*Tensor, len(tt.layers))
+			for i, rows := range tt.layers {
+				corrections[i] = rowsOf(rows)
+				layers = append(layers, []parameter{denseParameter("synapses", nil, &corrections[i], nil, nil)})
+			}
+			if got := tt.clipping.clip(layers, tt.batchSize); got != tt.wantClipped {
+				t.Errorf("Clipping.clip() = %v, want %v", got, tt.wantClipped)
+			}
+			for i, corr := range corrections {
+				got, want := corr.Data(), rowsOf(tt.want[i]).Data()
+				for j := range got {
+					if math.Abs(got[j]-want[j]) > 1e-12 {
+						t.Errorf("Clipping.clip() layer %d corrections = %v, want %v", i, corr.Rows(), tt.want[i])
+						break
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestPerceptron_Learn_clipped(t *testing.T) {
+	fields := mockPerceptronFields()
+	n := &Perceptron{input: fields.input, hidden: fields.hidden, output: fields.output}
+	set := rowsOf([][]float64{{1, 0, 0}, {0, 1, 0}, {1, 1, 0}})
+	labels := rowsOf([][]float64{{10}, {10}, {10}})
+
+	history, err := n.Learn(set, labels, 1, 2, WithClipping(Clipping{GlobalNorm: 1e-9}))
+	if err != nil {
+		t.Fatalf("Perceptron.Learn() error = %v", err)
+	}
+	if want := []int{1, 1}; !reflect.DeepEqual(history.Clipped, want) {
+		t.Errorf("History.Clipped = %v, want %v", history.Clipped, want)
+	}
+}
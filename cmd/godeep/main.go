@@ -0,0 +1,478 @@
+/*
+Godeep learns and runs perceptrons without writing Go code.
+
+Usage:
+
+	godeep train -config model.json -data set.csv -labels labels.csv -model model.gob
+	godeep predict -model model.gob -data set.csv [-out predictions.csv]
+	godeep evaluate -model model.gob -data set.csv -labels labels.csv
+	godeep summary (-model model.gob | -config model.json)
+	godeep serve -model model.gob [-addr :8080] [-max-batch 32 -batch-delay 5ms]
+	godeep generate -model model.gob [-package model] [-out model.go]
+	godeep quantize -model model.gob -data set.csv -labels labels.csv [-per-channel]
+	godeep graph (-model model.gob | -config model.json) [-format dot|svg] [-neurons] [-out graph.dot]
+
+Configs are JSON or, by .yaml and .yml extensions, YAML files. Training options of
+a config, e.g. epochs and a batch size, are defaults of train flags.
+Data are CSV files, a sample per line, NumPy .npy arrays or IDX files, e.g. MNIST images and labels.
+Samples of more than one dimension are flattened. Labels of a single column are
+encoded one-hot if a network has several outputs. A bias slot is appended to samples
+if an input layer has a bias and samples lack it.
+
+Serve answers predictions over HTTP until it is interrupted, see package serve for
+endpoints. Concurrent requests are predicted by batches if -max-batch is set. Generate
+writes a dependency free Go source predicting by a model. Quantize calibrates int8
+synapses by a set and reports an accuracy of them against float ones. Graph draws a network
+as a Graphviz DOT graph or an SVG image, by neurons of small networks if -neurons is set.
+*/
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	goDeep "github.com/I159/go_deep"
+	"github.com/I159/go_deep/serve"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "godeep:", err)
+		os.Exit(1)
+	}
+}
+
+var errUsage = errors.New("usage: godeep train|predict|evaluate|summary|serve|generate|quantize|graph [flags]")
+
+func run(args []string, stdout io.Writer) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	commands := map[string]func([]string, io.Writer) error{
+		"train":    train,
+		"predict":  predict,
+		"evaluate": evaluate,
+		"summary":  summary,
+		"serve":    serveModel,
+		"generate": generate,
+		"graph":    graph,
+		"quantize": quantize,
+	}
+	command, ok := commands[args[0]]
+	if !ok {
+		return errUsage
+	}
+	return command(args[1:], stdout)
+}
+
+func train(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("train", flag.ContinueOnError)
+	configPath := flags.String("config", "", "model config `file`, JSON or YAML")
+	dataPath := flags.String("data", "", "samples `file`, CSV, NPY or IDX")
+	labelsPath := flags.String("labels", "", "labels `file`, CSV, NPY or IDX")
+	validationData := flags.String("validation-data", "", "validation samples `file`")
+	validationLabels := flags.String("validation-labels", "", "validation labels `file`")
+	modelPath := flags.String("model", "model.gob", "trained model `file` to write")
+	epochs := flags.Int("epochs", 10, "number of epochs, a config one by default")
+	batchSize := flags.Int("batch", 32, "batch size, a config one by default")
+	shuffle := flags.Int64("shuffle", 0, "shuffle samples with a `seed`, zero keeps an order")
+	scale := flags.Float64("scale", 1, "divide samples by a `factor`, e.g. 255 for images")
+	precision := flags.String("precision", "", "`precision` of weights, float32 or float64, a config one by default")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	config, err := readConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	if *precision != "" {
+		config.Precision = goDeep.Precision(*precision)
+	}
+	n, err := goDeep.NewFromConfig(config)
+	if err != nil {
+		return err
+	}
+	set, labels, err := readLabeled(config, *dataPath, *labelsPath, *scale)
+	if err != nil {
+		return err
+	}
+
+	var options []goDeep.LearnOption
+	if t := config.Training; t != nil {
+		if options, err = t.Options(); err != nil {
+			return err
+		}
+		set := make(map[string]bool)
+		flags.Visit(func(f *flag.Flag) { set[f.Name] = true })
+		if t.Epochs > 0 && !set["epochs"] {
+			*epochs = t.Epochs
+		}
+		if t.BatchSize > 0 && !set["batch"] {
+			*batchSize = t.BatchSize
+		}
+	}
+	if *shuffle != 0 {
+		options = append(options, goDeep.WithShuffle(*shuffle))
+	}
+	if *validationData != "" {
+		validSet, validLabels, err := readLabeled(config, *validationData, *validationLabels, *scale)
+		if err != nil {
+			return err
+		}
+		options = append(options, goDeep.WithValidation(validSet, validLabels))
+	}
+
+	history, err := n.Learn(set, labels, *epochs, *batchSize, options...)
+	if err != nil {
+		return err
+	}
+	if len(history.Cost) > 0 {
+		fmt.Fprintf(stdout, "cost: %g\n", history.Cost[len(history.Cost)-1])
+	}
+	if len(history.ValidationCost) > 0 {
+		fmt.Fprintf(stdout, "validation cost: %g\n", history.ValidationCost[len(history.ValidationCost)-1])
+	}
+
+	f, err := os.Create(*modelPath)
+	if err != nil {
+		return err
+	}
+	if err = goDeep.SaveModel(f, n); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func predict(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("predict", flag.ContinueOnError)
+	modelPath := flags.String("model", "model.gob", "trained model `file`")
+	dataPath := flags.String("data", "", "samples `file`, CSV, NPY or IDX")
+	outPath := flags.String("out", "", "predictions CSV `file`, standard output by default")
+	scale := flags.Float64("scale", 1, "divide samples by a `factor`")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	n, config, err := readModel(*modelPath)
+	if err != nil {
+		return err
+	}
+	set, err := readSamples(config, *dataPath, *scale)
+	if err != nil {
+		return err
+	}
+	prediction, err := n.Recognize(set)
+	if err != nil {
+		return err
+	}
+
+	if *outPath == "" {
+		return goDeep.WriteCSV(stdout, prediction)
+	}
+	f, err := os.Create(*outPath)
+	if err != nil {
+		return err
+	}
+	if err = goDeep.WriteCSV(f, prediction); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func evaluate(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("evaluate", flag.ContinueOnError)
+	modelPath := flags.String("model", "model.gob", "trained model `file`")
+	dataPath := flags.String("data", "", "samples `file`, CSV, NPY or IDX")
+	labelsPath := flags.String("labels", "", "labels `file`, CSV, NPY or IDX")
+	scale := flags.Float64("scale", 1, "divide samples by a `factor`")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	n, config, err := readModel(*modelPath)
+	if err != nil {
+		return err
+	}
+	set, labels, err := readLabeled(config, *dataPath, *labelsPath, *scale)
+	if err != nil {
+		return err
+	}
+	e, err := goDeep.Evaluate(n, set, labels)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(stdout, "cost: %g\naccuracy: %g\n", e.Cost, e.Accuracy)
+	return err
+}
+
+func quantize(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("quantize", flag.ContinueOnError)
+	modelPath := flags.String("model", "model.gob", "trained model `file`")
+	dataPath := flags.String("data", "", "calibration samples `file`, CSV, NPY or IDX")
+	labelsPath := flags.String("labels", "", "labels `file`, CSV, NPY or IDX")
+	scale := flags.Float64("scale", 1, "divide samples by a `factor`")
+	perChannel := flags.Bool("per-channel", false, "scale synapses of every neuron separately")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	n, config, err := readModel(*modelPath)
+	if err != nil {
+		return err
+	}
+	set, labels, err := readLabeled(config, *dataPath, *labelsPath, *scale)
+	if err != nil {
+		return err
+	}
+	q, err := goDeep.Quantize(n, set, goDeep.QuantizeOptions{PerChannel: *perChannel})
+	if err != nil {
+		return err
+	}
+	r, err := q.Compare(n, set, labels)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(stdout, "accuracy: %g\nquantized accuracy: %g\naccuracy drop: %g\nmax error: %g\nsize: %d bytes of %d\n",
+		r.FloatAccuracy, r.QuantizedAccuracy, r.AccuracyDrop, r.MaxError, r.QuantizedBytes, r.FloatBytes)
+	return err
+}
+
+func summary(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("summary", flag.ContinueOnError)
+	modelPath := flags.String("model", "", "trained model `file`")
+	configPath := flags.String("config", "", "model config `file`, JSON or YAML")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	n, err := readNetwork(*modelPath, *configPath)
+	if err != nil {
+		return err
+	}
+
+	s, err := goDeep.Summary(n)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprint(stdout, s)
+	return err
+}
+
+func serveModel(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	modelPath := flags.String("model", "model.gob", "trained model `file`")
+	addr := flags.String("addr", ":8080", "`address` to listen")
+	maxBatch := flags.Int("max-batch", 0, "predict concurrent requests by batches of a `size`, zero disables batching")
+	batchDelay := flags.Duration("batch-delay", 5*time.Millisecond, "longest `delay` of a request waiting for a batch")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	n, _, err := readModel(*modelPath)
+	if err != nil {
+		return err
+	}
+	var options []serve.HandlerOption
+	if *maxBatch > 0 {
+		options = append(options, serve.WithBatching(serve.BatchOptions{MaxBatch: *maxBatch, MaxDelay: *batchDelay, QueueSize: *maxBatch}))
+	}
+	server, err := serve.NewServer(*addr, n, options...)
+	if err != nil {
+		return err
+	}
+
+	interrupted := make(chan os.Signal, 1)
+	signal.Notify(interrupted, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(interrupted)
+	done := make(chan error, 1)
+	go func() {
+		<-interrupted
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		done <- server.Shutdown(ctx)
+	}()
+
+	fmt.Fprintf(stdout, "serving %s on %s\n", *modelPath, *addr)
+	if err = server.ListenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+	return <-done
+}
+
+func generate(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("generate", flag.ContinueOnError)
+	modelPath := flags.String("model", "model.gob", "trained model `file`")
+	pkg := flags.String("package", "model", "`name` of a generated package")
+	outPath := flags.String("out", "", "Go source `file`, standard output by default")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	n, _, err := readModel(*modelPath)
+	if err != nil {
+		return err
+	}
+	if *outPath == "" {
+		return goDeep.GenerateGo(stdout, n, *pkg)
+	}
+	f, err := os.Create(*outPath)
+	if err != nil {
+		return err
+	}
+	if err = goDeep.GenerateGo(f, n, *pkg); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func graph(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("graph", flag.ContinueOnError)
+	modelPath := flags.String("model", "", "trained model `file`")
+	configPath := flags.String("config", "", "model config `file`, JSON or YAML")
+	format := flags.String("format", "dot", "graph `format`: dot or svg")
+	neurons := flags.Bool("neurons", false, "draw neurons and synapses colored by weights of a small network")
+	outPath := flags.String("out", "", "graph `file`, standard output by default")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	write, ok := map[string]func(io.Writer, goDeep.Network, goDeep.GraphOptions) error{
+		"dot": goDeep.WriteDOT,
+		"svg": goDeep.WriteSVG,
+	}[*format]
+	if !ok {
+		return fmt.Errorf("unknown graph format %q", *format)
+	}
+	n, err := readNetwork(*modelPath, *configPath)
+	if err != nil {
+		return err
+	}
+
+	options := goDeep.GraphOptions{Neurons: *neurons}
+	if *outPath == "" {
+		return write(stdout, n, options)
+	}
+	f, err := os.Create(*outPath)
+	if err != nil {
+		return err
+	}
+	if err = write(f, n, options); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// readNetwork of a saved model or of a config otherwise.
+func readNetwork(modelPath, configPath string) (goDeep.Network, error) {
+	if modelPath != "" {
+		n, _, err := readModel(modelPath)
+		return n, err
+	}
+	config, err := readConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+	return goDeep.NewFromConfig(config)
+}
+
+func readConfig(path string) (config goDeep.ModelConfig, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return config, err
+	}
+	defer f.Close()
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return goDeep.ReadModelConfigYAML(f)
+	}
+	return goDeep.ReadModelConfig(f)
+}
+
+func readModel(path string) (goDeep.Network, goDeep.ModelConfig, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, goDeep.ModelConfig{}, err
+	}
+	defer f.Close()
+
+	n, err := goDeep.LoadModel(f)
+	if err != nil {
+		return nil, goDeep.ModelConfig{}, err
+	}
+	config, err := n.Config()
+	return n, config, err
+}
+
+// readTensor of a CSV file, a NumPy .npy one or an IDX one otherwise as a matrix, a sample
+// per row.
+func readTensor(path string) (*goDeep.Tensor, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var t *goDeep.Tensor
+	switch ext := filepath.Ext(path); {
+	case strings.EqualFold(ext, ".csv"):
+		t, err = goDeep.ReadCSV(f)
+	case strings.EqualFold(ext, ".npy"):
+		t, err = goDeep.ReadNPY(f)
+	default:
+		t, err = goDeep.ReadIDX(f)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if t.Dims() == 0 {
+		return nil, fmt.Errorf("%s: no samples", path)
+	}
+	return t.Reshape(t.Shape()[0], -1)
+}
+
+// readSamples scaled and with a bias slot if an input layer has a bias.
+func readSamples(config goDeep.ModelConfig, path string, scale float64) (*goDeep.Tensor, error) {
+	set, err := readTensor(path)
+	if err != nil {
+		return nil, err
+	}
+	if scale != 1 {
+		set = set.Apply(func(v float64) float64 { return v / scale })
+	}
+	if config.Input == nil || config.Input.Bias == 0 || set.Shape()[1] != config.Input.Size-1 {
+		return set, nil
+	}
+
+	rows := set.Rows()
+	for i := range rows {
+		rows[i] = append(rows[i], 0)
+	}
+	return goDeep.FromRows(rows)
+}
+
+func readLabeled(config goDeep.ModelConfig, dataPath, labelsPath string, scale float64) (set, labels *goDeep.Tensor, err error) {
+	if set, err = readSamples(config, dataPath, scale); err != nil {
+		return
+	}
+	if labels, err = readTensor(labelsPath); err != nil {
+		return
+	}
+	if labels.Shape()[1] == 1 && config.Output.Size > 1 {
+		labels, err = goDeep.OneHot(labels, config.Output.Size)
+	}
+	return
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	goDeep "github.com/I159/go_deep"
+)
+
+func TestRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "godeep")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"model.json": `{
+			"input": {"size": 3, "learningRate": 0.5, "bias": 1},
+			"hidden": [{"size": 4, "learningRate": 0.5, "bias": 1, "activation": "sigmoid"}],
+			"output": {"size": 2, "activation": "sigmoid", "cost": "quadratic"}
+		}`,
+		"training.json": `{
+			"input": {"size": 3, "learningRate": 0.5, "bias": 1, "initializer": "xavier"},
+			"hidden": [{"size": 4, "learningRate": 0.5, "bias": 1, "activation": "sigmoid", "initializer": "xavier"}],
+			"output": {"size": 2, "activation": "sigmoid", "cost": "quadratic"},
+			"seed": 1,
+			"training": {"epochs": 2, "batchSize": 2, "shuffle": 1, "schedule": {"name": "step", "step": 1, "gamma": 0.5}}
+		}`,
+		"model.yaml": "input: {size: 3, learningRate: 0.5, bias: 1}\nhidden:\n  - {size: 4, learningRate: 0.5, bias: 1, activation: sigmoid}\noutput: {size: 2, activation: sigmoid, cost: quadratic}\n",
+		"set.csv":    "0,0\n0,1\n1,0\n1,1\n",
+		"labels.csv": "0\n1\n1\n0\n",
+	}
+	for name, content := range files {
+		if err = ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	path := func(name string) string { return filepath.Join(dir, name) }
+	f, err := os.Create(path("set.npy"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	set, _ := goDeep.FromRows([][]float64{{0, 0}, {0, 1}})
+	if err = goDeep.WriteNPY(f, set, goDeep.NPYFormat{Float32: true}); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantOutput string
+		wantErr    bool
+	}{
+		{
+			name:       "train",
+			args:       []string{"train", "-config", path("model.json"), "-data", path("set.csv"), "-labels", path("labels.csv"), "-model", path("model.gob"), "-epochs", "2", "-batch", "2"},
+			wantOutput: "cost: ",
+		},
+		{
+			name:       "trainConfigured",
+			args:       []string{"train", "-config", path("training.json"), "-data", path("set.csv"), "-labels", path("labels.csv"), "-model", path("trained.gob")},
+			wantOutput: "cost: ",
+		},
+		{
+			name:       "trainFloat32",
+			args:       []string{"train", "-config", path("model.json"), "-data", path("set.csv"), "-labels", path("labels.csv"), "-model", path("model32.gob"), "-epochs", "2", "-precision", "float32"},
+			wantOutput: "cost: ",
+		},
+		{name: "predictFloat32", args: []string{"predict", "-model", path("model32.gob"), "-data", path("set.csv")}, wantOutput: ","},
+		{name: "predict", args: []string{"predict", "-model", path("model.gob"), "-data", path("set.csv")}, wantOutput: ","},
+		{name: "predictNPY", args: []string{"predict", "-model", path("model.gob"), "-data", path("set.npy")}, wantOutput: ","},
+		{name: "evaluate", args: []string{"evaluate", "-model", path("model.gob"), "-data", path("set.csv"), "-labels", path("labels.csv")}, wantOutput: "accuracy: "},
+		{name: "summary", args: []string{"summary", "-model", path("model.gob")}, wantOutput: "hidden[0]  dense   [4]     true   sigmoid"},
+		{name: "summaryConfig", args: []string{"summary", "-config", path("model.json")}, wantOutput: "non-trainable: 0"},
+		{name: "summaryYAML", args: []string{"summary", "-config", path("model.yaml")}, wantOutput: "non-trainable: 0"},
+		{name: "quantize", args: []string{"quantize", "-model", path("model.gob"), "-data", path("set.csv"), "-labels", path("labels.csv"), "-per-channel"}, wantOutput: "quantized accuracy: "},
+		{name: "generate", args: []string{"generate", "-model", path("model.gob"), "-package", "xor"}, wantOutput: "func Predict(input []float64) []float64"},
+		{name: "graph", args: []string{"graph", "-model", path("model.gob")}, wantOutput: `"input" -> "hidden[0]"`},
+		{name: "graphSVG", args: []string{"graph", "-config", path("model.json"), "-format", "svg", "-neurons"}, wantOutput: "<circle "},
+		{name: "graphFormat", args: []string{"graph", "-model", path("model.gob"), "-format", "png"}, wantErr: true},
+		{name: "wrongWidth", args: []string{"predict", "-model", path("model.gob"), "-data", path("labels.csv")}, wantErr: true},
+		{name: "unknownCommand", args: []string{"export"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			err := run(tt.args, &stdout)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !strings.Contains(stdout.String(), tt.wantOutput) {
+				t.Errorf("run() output = %q, want it containing %q", stdout.String(), tt.wantOutput)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package goDeep
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"math"
+	"strconv"
+)
+
+// Go sources of activations of generated code by names of a model configuration.
+var generatedActivations = map[string]string{
+	"sigmoid": "func sigmoid(x float64) float64 {\n\treturn 1 / (1 + math.Exp(-x))\n}\n",
+	"relu":    "func relu(x float64) float64 {\n\treturn math.Max(0, x)\n}\n",
+	"tanh":    "func tanh(x float64) float64 {\n\treturn math.Tanh(x)\n}\n",
+	// A source of a softmax is formatted with an element type of layers, exponents are
+	// rounded to it and summed in float64 as of Recognize.
+	"softmax": `func softmax(layer []%[1]s) {
+	max, sum := math.Inf(-1), 0.
+	for _, x := range layer {
+		max = math.Max(max, float64(x))
+	}
+	for i, x := range layer {
+		layer[i] = %[1]s(math.Exp(float64(x) - max))
+		sum += float64(layer[i])
+	}
+	for i := range layer {
+		layer[i] = %[1]s(float64(layer[i]) / sum)
+	}
+}
+`,
+}
+
+/*
+GenerateGo writes a Go source file of a package predicting by a trained network without
+dependencies: weights are arrays and Predict is an unrolled forward propagation of
+a sample of an input layer size, a bias slot included. Weights and arithmetic are of
+a precision of the network, float32 ones are computed in float32 and activated in float64
+as Recognize does. Predictions are identical to Recognize ones, but Predict saturates
+activations where Recognize reports errors.
+
+Only networks of dense inputs are generated.
+*/
+func GenerateGo(w io.Writer, n Network, pkg string) error {
+	c, err := n.Config()
+	if err != nil {
+		return err
+	}
+	if err = denseInputOnly("GenerateGo", c); err != nil {
+		return err
+	}
+	params := parametersOf(n)
+	element := string(Float64)
+	if c.Precision == Float32 {
+		element = string(Float32)
+	}
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by go_deep GenerateGo. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&src, "// Package %s predicts by a trained network.\npackage %s\n\nimport \"math\"\n\n", pkg, pkg)
+	fmt.Fprintf(&src, "// InputSize is a size of samples, a last bias slot is ignored if an input layer has a bias.\nconst InputSize = %d\n\n", c.Input.Size)
+	fmt.Fprintf(&src, "// OutputSize is a size of predictions.\nconst OutputSize = %d\n\n", c.Output.Size)
+
+	type connection struct {
+		synapses, signal, summed string
+		weights                  [][]float64
+		bias                     bool
+		activation               string
+	}
+	connections := []connection{{synapses: "inputSynapses", signal: "input", bias: c.Input.Bias != 0}}
+	for i, h := range c.Hidden {
+		name := fmt.Sprintf("hidden%d", i)
+		connections[i].summed, connections[i].activation = name, h.Activation
+		connections = append(connections, connection{synapses: name + "Synapses", signal: name, bias: h.Bias != 0})
+	}
+	last := len(connections) - 1
+	connections[last].summed, connections[last].activation = "output", c.Output.Activation
+	for i, name := range append([]string{"input"}, hiddenNames(len(c.Hidden))...) {
+		connections[i].weights = params[name+".synapses"].Rows()
+	}
+
+	used := make(map[string]bool)
+	for _, conn := range connections {
+		if _, ok := generatedActivations[conn.activation]; !ok {
+			return &ConfigError{Field: "activation", Value: conn.activation, Reason: "GenerateGo has no source of the activation"}
+		}
+		used[conn.activation] = true
+		writeGoMatrix(&src, conn.synapses, element, conn.weights)
+	}
+
+	fmt.Fprintf(&src, "// Predict returns a prediction of a sample of InputSize values.\n")
+	fmt.Fprintf(&src, "func Predict(input []float64) []float64 {\n")
+	for _, conn := range connections {
+		signals := len(conn.weights)
+		if conn.bias {
+			signals--
+		}
+		neurons := len(conn.weights[0])
+		fmt.Fprintf(&src, "var %s [%d]%s\n", conn.summed, neurons, element)
+		for j := 0; j < neurons; j++ {
+			fmt.Fprintf(&src, "%s[%d] = ", conn.summed, j)
+			for i := 0; i < signals; i++ {
+				if i > 0 {
+					src.WriteString(" + ")
+				}
+				signal := fmt.Sprintf("%s[%d]", conn.signal, i)
+				if conn.signal == "input" && element != string(Float64) {
+					signal = fmt.Sprintf("%s(%s)", element, signal)
+				}
+				// Conversions keep products rounded as of Recognize, not fused with sums.
+				fmt.Fprintf(&src, "%s(%s*%s[%d][%d])", element, signal, conn.synapses, i, j)
+			}
+			if conn.bias {
+				fmt.Fprintf(&src, " + %s[%d][%d]", conn.synapses, signals, j)
+			}
+			src.WriteString("\n")
+		}
+		if conn.activation == "softmax" {
+			fmt.Fprintf(&src, "softmax(%s[:])\n", conn.summed)
+		} else if element == string(Float64) {
+			for j := 0; j < neurons; j++ {
+				fmt.Fprintf(&src, "%s[%d] = %s(%s[%d])\n", conn.summed, j, conn.activation, conn.summed, j)
+			}
+		} else {
+			// Activations of float32 values are computed in float64 and rounded.
+			for j := 0; j < neurons; j++ {
+				fmt.Fprintf(&src, "%s[%d] = %s(%s(float64(%s[%d])))\n", conn.summed, j, element, conn.activation, conn.summed, j)
+			}
+		}
+	}
+	if element == string(Float64) {
+		src.WriteString("return output[:]\n}\n\n")
+	} else {
+		src.WriteString("prediction := make([]float64, len(output))\nfor i, v := range output {\nprediction[i] = float64(v)\n}\nreturn prediction\n}\n\n")
+	}
+	for _, name := range []string{"relu", "sigmoid", "softmax", "tanh"} {
+		if !used[name] {
+			continue
+		}
+		if name == "softmax" {
+			fmt.Fprintf(&src, generatedActivations[name]+"\n", element)
+		} else {
+			src.WriteString(generatedActivations[name] + "\n")
+		}
+	}
+
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(formatted)
+	return err
+}
+
+func hiddenNames(hidden int) []string {
+	names := make([]string, hidden)
+	for i := range names {
+		names[i] = fmt.Sprintf("hidden[%d]", i)
+	}
+	return names
+}
+
+// writeGoMatrix writes an array variable of exact float literals of an element type.
+func writeGoMatrix(src *bytes.Buffer, name, element string, rows [][]float64) {
+	bits := 64
+	if element == string(Float32) {
+		bits = 32
+	}
+	fmt.Fprintf(src, "var %s = [%d][%d]%s{\n", name, len(rows), len(rows[0]), element)
+	for _, row := range rows {
+		src.WriteString("{")
+		for j, v := range row {
+			if j > 0 {
+				src.WriteString(", ")
+			}
+			if v == 0 && math.Signbit(v) {
+				fmt.Fprintf(src, "%s(math.Copysign(0, -1))", element)
+			} else {
+				src.WriteString(strconv.FormatFloat(v, 'g', -1, bits))
+			}
+		}
+		src.WriteString("},\n")
+	}
+	src.WriteString("}\n\n")
+}
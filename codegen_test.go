@@ -0,0 +1,111 @@
+package goDeep
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// predictMain prints predictions of generated Predict, a line per sample.
+const predictMain = `package main
+
+import "fmt"
+
+func main() {
+	for _, sample := range [][]float64{%s} {
+		for i, v := range Predict(sample) {
+			if i > 0 {
+				fmt.Print(",")
+			}
+			fmt.Print(v)
+		}
+		fmt.Println()
+	}
+}
+`
+
+func TestGenerateGo(t *testing.T) {
+	gobin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("a go command is not found")
+	}
+	samples := [][]float64{{0, 1, 0}, {1, 1, 0}, {.5, -1.5, 0}}
+	tests := []struct {
+		name           string
+		inputBias      float64
+		hidden, output string
+		precision      Precision
+	}{
+		{name: "sigmoid", inputBias: 1, hidden: "sigmoid", output: "sigmoid"},
+		{name: "reluSoftmax", inputBias: 1, hidden: "relu", output: "softmax"},
+		{name: "tanhWithoutInputBias", hidden: "tanh", output: "tanh"},
+		{name: "float32Sigmoid", inputBias: 1, hidden: "sigmoid", output: "sigmoid", precision: Float32},
+		{name: "float32ReluSoftmax", inputBias: 1, hidden: "relu", output: "softmax", precision: Float32},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := NewFromConfig(ModelConfig{
+				Input:     &LayerConfig{Size: 3, LearningRate: .1, Bias: tt.inputBias},
+				Hidden:    []LayerConfig{{Size: 5, LearningRate: .1, Bias: 1, Activation: tt.hidden}},
+				Output:    LayerConfig{Size: 3, Activation: tt.output, Cost: "quadratic"},
+				Precision: tt.precision,
+			})
+			if err != nil {
+				t.Fatalf("NewFromConfig() error = %v", err)
+			}
+			var src bytes.Buffer
+			if err = GenerateGo(&src, n, "main"); err != nil {
+				t.Fatalf("GenerateGo() error = %v", err)
+			}
+
+			dir, err := ioutil.TempDir("", "generated")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			literals := make([]string, len(samples))
+			for i, s := range samples {
+				literals[i] = fmt.Sprintf("%#v", s)[len("[]float64"):]
+			}
+			files := map[string]string{
+				"model.go": src.String(),
+				"main.go":  fmt.Sprintf(predictMain, strings.Join(literals, ", ")),
+			}
+			for name, content := range files {
+				if err = ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			cmd := exec.Command(gobin, "run", "model.go", "main.go")
+			cmd.Dir = dir
+			cmd.Env = append(os.Environ(), "GO111MODULE=off")
+			got, err := cmd.CombinedOutput()
+			if err != nil {
+				t.Fatalf("go run of generated code error = %v\n%s\n%s", err, got, src.String())
+			}
+
+			prediction, err := n.Recognize(rowsOf(samples))
+			if err != nil {
+				t.Fatalf("Perceptron.Recognize() error = %v", err)
+			}
+			var want strings.Builder
+			for _, row := range prediction.Rows() {
+				for i, v := range row {
+					if i > 0 {
+						want.WriteString(",")
+					}
+					fmt.Fprint(&want, v)
+				}
+				want.WriteString("\n")
+			}
+			if string(got) != want.String() {
+				t.Errorf("Predict() = %q, want %q", got, want.String())
+			}
+		})
+	}
+}
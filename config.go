@@ -0,0 +1,246 @@
+package goDeep
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+)
+
+/*
+Initializer returns an initial weight of synapses between a layer of fanIn neurons and
+a next one of fanOut neurons. Biases are not counted and keep bias values.
+*/
+type Initializer func(fanIn, fanOut int, r *rand.Rand) float64
+
+/*
+Registries of names of a model configuration. Names are registered by Register functions,
+usually from init functions: registries are not safe for concurrent use.
+*/
+var (
+	activations = map[string]func() activation{
+		"sigmoid": func() activation { return new(Sigmoid) },
+		"relu":    func() activation { return new(Relu) },
+		"tanh":    func() activation { return new(Tanh) },
+		"softmax": func() activation { return new(Softmax) },
+	}
+	costs = map[string]func() cost{
+		"quadratic": func() cost { return new(Quadratic) },
+	}
+	initializers = map[string]Initializer{
+		"uniform": func(fanIn, fanOut int, r *rand.Rand) float64 {
+			return r.Float64() - .5
+		},
+		"xavier": func(fanIn, fanOut int, r *rand.Rand) float64 {
+			limit := math.Sqrt(6 / float64(fanIn+fanOut))
+			return (2*r.Float64() - 1) * limit
+		},
+		"he": func(fanIn, fanOut int, r *rand.Rand) float64 {
+			return r.NormFloat64() * math.Sqrt(2/float64(fanIn))
+		},
+		"zeros": func(fanIn, fanOut int, r *rand.Rand) float64 {
+			return 0
+		},
+	}
+	schedules = map[string]func(c ScheduleConfig, then Scheduler) Scheduler{
+		"step": func(c ScheduleConfig, then Scheduler) Scheduler {
+			return StepDecay{Step: c.Step, Gamma: c.Gamma}
+		},
+		"exponential": func(c ScheduleConfig, then Scheduler) Scheduler {
+			return ExponentialDecay{Gamma: c.Gamma}
+		},
+		"cosine": func(c ScheduleConfig, then Scheduler) Scheduler {
+			return CosineAnnealing{Period: c.Period, PeriodMult: c.PeriodMult, Min: c.Min}
+		},
+		"oneCycle": func(c ScheduleConfig, then Scheduler) Scheduler {
+			return OneCycle{Steps: c.Steps, Warmup: c.Warmup, Start: c.Start, End: c.End}
+		},
+		"warmup": func(c ScheduleConfig, then Scheduler) Scheduler {
+			return LinearWarmup{Steps: c.Steps, Start: c.Start, Then: then}
+		},
+		"plateau": func(c ScheduleConfig, then Scheduler) Scheduler {
+			return NewReduceOnPlateau(c.Factor, c.Patience, c.Threshold, c.Min)
+		},
+	}
+	optimizers = map[string]bool{"sgd": true}
+)
+
+// RegisterActivation names an activation of model configurations. An activation is shared
+// by all the networks declared by a name, so it must keep no state.
+func RegisterActivation(name string, a activation) {
+	activations[name] = func() activation { return a }
+}
+
+// RegisterCost names a cost of model configurations. A cost must keep no state.
+func RegisterCost(name string, c cost) {
+	costs[name] = func() cost { return c }
+}
+
+// RegisterInitializer names an initializer of synapses of model configurations.
+func RegisterInitializer(name string, init Initializer) {
+	initializers[name] = init
+}
+
+// RegisterSchedule names a learning rate schedule of training configurations. Then is
+// a schedule of Then declaration, nil without it.
+func RegisterSchedule(name string, schedule func(c ScheduleConfig, then Scheduler) Scheduler) {
+	schedules[name] = schedule
+}
+
+/*
+ScheduleConfig is a declaration of a learning rate schedule. Name selects a schedule and
+fields of the same names as of a schedule type configure it, Then declares a schedule
+following a warmup. Unit is "epoch", the default, or "batch".
+
+	{"name": "warmup", "unit": "batch", "steps": 100, "then": {"name": "cosine", "period": 1000}}
+*/
+type ScheduleConfig struct {
+	Name       string          `json:"name"`
+	Unit       string          `json:"unit,omitempty"`
+	Step       int             `json:"step,omitempty"`
+	Steps      int             `json:"steps,omitempty"`
+	Period     int             `json:"period,omitempty"`
+	Patience   int             `json:"patience,omitempty"`
+	Gamma      float64         `json:"gamma,omitempty"`
+	PeriodMult float64         `json:"periodMult,omitempty"`
+	Min        float64         `json:"min,omitempty"`
+	Warmup     float64         `json:"warmup,omitempty"`
+	Start      float64         `json:"start,omitempty"`
+	End        float64         `json:"end,omitempty"`
+	Factor     float64         `json:"factor,omitempty"`
+	Threshold  float64         `json:"threshold,omitempty"`
+	Then       *ScheduleConfig `json:"then,omitempty"`
+}
+
+// NewSchedule is a schedule initializer from a declaration.
+func NewSchedule(c ScheduleConfig) (Scheduler, error) {
+	return newSchedule("Schedule", c)
+}
+
+func newSchedule(field string, c ScheduleConfig) (Scheduler, error) {
+	schedule, ok := schedules[c.Name]
+	if !ok {
+		return nil, &ConfigError{Field: field + ".Name", Value: c.Name, Reason: "unknown schedule"}
+	}
+	var then Scheduler
+	if c.Then != nil {
+		var err error
+		if then, err = newSchedule(field+".Then", *c.Then); err != nil {
+			return nil, err
+		}
+	}
+	return schedule(c, then), nil
+}
+
+func (c ScheduleConfig) unit(field string) (ScheduleUnit, error) {
+	switch c.Unit {
+	case "", "epoch":
+		return PerEpoch, nil
+	case "batch":
+		return PerBatch, nil
+	}
+	return PerEpoch, &ConfigError{Field: field + ".Unit", Value: c.Unit, Reason: `a unit is "epoch" or "batch"`}
+}
+
+/*
+TrainingConfig is a declaration of learning. Epochs and BatchSize are arguments of Learn,
+other fields are options: Shuffle is a seed of WithShuffle, zero keeps an order of samples,
+and NumericGuard enables WithNumericGuard without a dump. Optimizer is "sgd", a stochastic
+gradient descent, the only optimizer so far.
+*/
+type TrainingConfig struct {
+	Epochs       int             `json:"epochs,omitempty"`
+	BatchSize    int             `json:"batchSize,omitempty"`
+	Optimizer    string          `json:"optimizer,omitempty"`
+	Shuffle      int64           `json:"shuffle,omitempty"`
+	Schedule     *ScheduleConfig `json:"schedule,omitempty"`
+	Clipping     *Clipping       `json:"clipping,omitempty"`
+	NumericGuard bool            `json:"numericGuard,omitempty"`
+	// RateMultipliers of layers or parameters, see WithRateMultipliers.
+	RateMultipliers map[string]float64 `json:"rateMultipliers,omitempty"`
+}
+
+// Options of Learn declared by a training configuration.
+func (c *TrainingConfig) Options() (options []LearnOption, err error) {
+	if c.Optimizer != "" && !optimizers[c.Optimizer] {
+		return nil, &ConfigError{Field: "Training.Optimizer", Value: c.Optimizer, Reason: "unknown optimizer"}
+	}
+	if c.Shuffle != 0 {
+		options = append(options, WithShuffle(c.Shuffle))
+	}
+	if c.Schedule != nil {
+		schedule, err := newSchedule("Training.Schedule", *c.Schedule)
+		if err != nil {
+			return nil, err
+		}
+		unit, err := c.Schedule.unit("Training.Schedule")
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, WithScheduler(schedule, unit))
+	}
+	if c.Clipping != nil {
+		options = append(options, WithClipping(*c.Clipping))
+	}
+	if c.NumericGuard {
+		options = append(options, WithNumericGuard(nil))
+	}
+	if c.RateMultipliers != nil {
+		options = append(options, WithRateMultipliers(c.RateMultipliers))
+	}
+	return options, nil
+}
+
+// ReadModelConfig decodes a JSON declaration of a network. Unknown fields are errors, so
+// misspelled names are not ignored.
+func ReadModelConfig(r io.Reader) (c ModelConfig, err error) {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(&c)
+	return
+}
+
+// synapsesConfig is an initializer of synapses of a layer declared by a field.
+type synapsesConfig struct {
+	field, initializer string
+	bias               bool
+}
+
+/*
+initialize synapses declared with initializers drawing from a source of a seed of
+a configuration, synapses without an initializer keep default weights.
+*/
+func initialize(n Network, c ModelConfig, r *rand.Rand) error {
+	declared := make(map[string]synapsesConfig)
+	switch {
+	case c.Input != nil:
+		declared["input.synapses"] = synapsesConfig{"Input.Initializer", c.Input.Initializer, c.Input.Bias != 0}
+	case c.Embedding != nil:
+		declared["input.synapses"] = synapsesConfig{"Embedding.Initializer", c.Embedding.Initializer, c.Embedding.Bias != 0}
+	}
+	for i, l := range c.Hidden {
+		declared[fmt.Sprintf("hidden[%d].synapses", i)] = synapsesConfig{fmt.Sprintf("Hidden[%d].Initializer", i), l.Initializer, l.Bias != 0}
+	}
+
+	for _, p := range n.parameters() {
+		s, ok := declared[p.name]
+		if !ok || s.initializer == "" {
+			continue
+		}
+		init, ok := initializers[s.initializer]
+		if !ok {
+			return &ConfigError{Field: s.field, Value: s.initializer, Reason: "unknown initializer"}
+		}
+		fanIn, fanOut := p.value.Shape()[0], p.value.Shape()[1]
+		if s.bias {
+			fanIn--
+		}
+		for i := 0; i < fanIn; i++ {
+			for j := 0; j < fanOut; j++ {
+				p.value.Set(init(fanIn, fanOut, r), i, j)
+			}
+		}
+	}
+	return nil
+}
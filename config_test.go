@@ -0,0 +1,239 @@
+package goDeep
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadModelConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		want    ModelConfig
+		wantErr bool
+	}{
+		{
+			name: "declaration",
+			json: `{
+				"input": {"size": 3, "learningRate": 0.1, "bias": 1, "initializer": "xavier"},
+				"hidden": [{"size": 4, "learningRate": 0.1, "bias": 1, "activation": "sigmoid", "initializer": "he"}],
+				"output": {"size": 1, "activation": "sigmoid", "cost": "quadratic"},
+				"seed": 7,
+				"training": {
+					"epochs": 10,
+					"batchSize": 4,
+					"optimizer": "sgd",
+					"schedule": {"name": "warmup", "unit": "batch", "steps": 5, "then": {"name": "exponential", "gamma": 0.9}},
+					"clipping": {"globalNorm": 5}
+				}
+			}`,
+			want: ModelConfig{
+				Input:  &LayerConfig{Size: 3, LearningRate: .1, Bias: 1, Initializer: "xavier"},
+				Hidden: []LayerConfig{{Size: 4, LearningRate: .1, Bias: 1, Activation: "sigmoid", Initializer: "he"}},
+				Output: LayerConfig{Size: 1, Activation: "sigmoid", Cost: "quadratic"},
+				Seed:   7,
+				Training: &TrainingConfig{
+					Epochs:    10,
+					BatchSize: 4,
+					Optimizer: "sgd",
+					Schedule: &ScheduleConfig{
+						Name:  "warmup",
+						Unit:  "batch",
+						Steps: 5,
+						Then:  &ScheduleConfig{Name: "exponential", Gamma: .9},
+					},
+					Clipping: &Clipping{GlobalNorm: 5},
+				},
+			},
+		},
+		{name: "unknownField", json: `{"input": {"size": 3, "rate": 0.1}}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadModelConfig(strings.NewReader(tt.json))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadModelConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadModelConfig() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewSchedule(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    ScheduleConfig
+		want      Scheduler
+		wantField string
+	}{
+		{name: "step", config: ScheduleConfig{Name: "step", Step: 2, Gamma: .5}, want: StepDecay{Step: 2, Gamma: .5}},
+		{
+			name:   "warmup",
+			config: ScheduleConfig{Name: "warmup", Steps: 3, Start: .1, Then: &ScheduleConfig{Name: "cosine", Period: 10, Min: .1}},
+			want:   LinearWarmup{Steps: 3, Start: .1, Then: CosineAnnealing{Period: 10, Min: .1}},
+		},
+		{name: "plateau", config: ScheduleConfig{Name: "plateau", Factor: .5, Patience: 2}, want: NewReduceOnPlateau(.5, 2, 0, 0)},
+		{name: "unknown", config: ScheduleConfig{Name: "linear"}, wantField: "Schedule.Name"},
+		{name: "unknownThen", config: ScheduleConfig{Name: "warmup", Then: &ScheduleConfig{}}, wantField: "Schedule.Then.Name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewSchedule(tt.config)
+			if tt.wantField != "" {
+				var configErr *ConfigError
+				if !errors.As(err, &configErr) || configErr.Field != tt.wantField {
+					t.Fatalf("NewSchedule() error = %v, want a ConfigError of %s", err, tt.wantField)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewSchedule() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewSchedule() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrainingConfig_Options(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      TrainingConfig
+		wantOptions int
+		wantField   string
+	}{
+		{name: "empty", config: TrainingConfig{}},
+		{
+			name: "all",
+			config: TrainingConfig{
+				Optimizer:    "sgd",
+				Shuffle:      1,
+				Schedule:     &ScheduleConfig{Name: "exponential", Gamma: .9},
+				Clipping:     &Clipping{Value: 1},
+				NumericGuard: true,
+			},
+			wantOptions: 4,
+		},
+		{name: "unknownOptimizer", config: TrainingConfig{Optimizer: "adam"}, wantField: "Training.Optimizer"},
+		{name: "unknownUnit", config: TrainingConfig{Schedule: &ScheduleConfig{Name: "step", Unit: "sample"}}, wantField: "Training.Schedule.Unit"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.config.Options()
+			if tt.wantField != "" {
+				var configErr *ConfigError
+				if !errors.As(err, &configErr) || configErr.Field != tt.wantField {
+					t.Fatalf("TrainingConfig.Options() error = %v, want a ConfigError of %s", err, tt.wantField)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("TrainingConfig.Options() error = %v", err)
+			}
+			if len(got) != tt.wantOptions {
+				t.Errorf("TrainingConfig.Options() = %d options, want %d", len(got), tt.wantOptions)
+			}
+		})
+	}
+}
+
+func TestNewFromConfig_initializers(t *testing.T) {
+	RegisterInitializer("ones", func(fanIn, fanOut int, r *rand.Rand) float64 { return 1 })
+	defer delete(initializers, "ones")
+
+	config := ModelConfig{
+		Input:  &LayerConfig{Size: 3, LearningRate: .1, Bias: .5, Initializer: "ones"},
+		Hidden: []LayerConfig{{Size: 3, LearningRate: .1, Bias: 1, Activation: "sigmoid", Initializer: "xavier"}},
+		Output: LayerConfig{Size: 2, Activation: "sigmoid", Cost: "quadratic"},
+		Seed:   3,
+	}
+	n, err := NewFromConfig(config)
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	again, err := NewFromConfig(config)
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+
+	params := n.parameters()
+	wantInput := [][]float64{{1, 1}, {1, 1}, {.5, .5}}
+	if got := params[0].value.Rows(); !reflect.DeepEqual(got, wantInput) {
+		t.Errorf("input synapses = %v, want %v", got, wantInput)
+	}
+	if got, want := params[1].value.Data(), again.parameters()[1].value.Data(); !reflect.DeepEqual(got, want) {
+		t.Errorf("hidden synapses of the same seed = %v and %v", got, want)
+	}
+
+	config.Output.Initializer = "ones"
+	var configErr *ConfigError
+	if _, err = NewFromConfig(config); !errors.As(err, &configErr) || configErr.Field != "Output.Initializer" {
+		t.Errorf("NewFromConfig() error = %v, want a ConfigError of Output.Initializer", err)
+	}
+	config.Output.Initializer = ""
+	config.Hidden[0].Initializer = "orthogonal"
+	if _, err = NewFromConfig(config); !errors.As(err, &configErr) || configErr.Field != "Hidden[0].Initializer" {
+		t.Errorf("NewFromConfig() error = %v, want a ConfigError of Hidden[0].Initializer", err)
+	}
+}
+
+func TestNewFromConfig_seed(t *testing.T) {
+	configs := map[string]ModelConfig{
+		"dense": {
+			Input:  &LayerConfig{Size: 3, LearningRate: .1, Bias: 1},
+			Hidden: []LayerConfig{{Size: 4, LearningRate: .1, Bias: 1, Activation: "sigmoid"}},
+			Output: LayerConfig{Size: 2, Activation: "sigmoid", Cost: "quadratic"},
+			Seed:   11,
+		},
+		"embedding": {
+			Embedding: &EmbeddingConfig{Vocabulary: 5, Dimension: 2, Fields: 2, LearningRate: .1, Bias: 1},
+			Hidden:    []LayerConfig{{Size: 3, LearningRate: .1, Activation: "relu", Initializer: "he"}},
+			Output:    LayerConfig{Size: 1, Activation: "sigmoid", Cost: "quadratic"},
+			Seed:      11,
+		},
+	}
+	for name, config := range configs {
+		t.Run(name, func(t *testing.T) {
+			var params [3]map[string][]float64
+			for i := range params {
+				if i == 2 {
+					config.Seed++
+				}
+				n, err := NewFromConfig(config)
+				if err != nil {
+					t.Fatalf("NewFromConfig() error = %v", err)
+				}
+				params[i] = make(map[string][]float64)
+				for name, value := range parametersOf(n) {
+					params[i][name] = value.Data()
+				}
+			}
+			if !reflect.DeepEqual(params[0], params[1]) {
+				t.Errorf("parameters of the same seed = %v and %v, want bit-identical", params[0], params[1])
+			}
+			if reflect.DeepEqual(params[0], params[2]) {
+				t.Errorf("parameters of another seed = %v, want different", params[2])
+			}
+		})
+	}
+}
+
+func TestRegisterActivation(t *testing.T) {
+	RegisterActivation("logistic", new(Sigmoid))
+	defer delete(activations, "logistic")
+
+	config := ModelConfig{
+		Input:  &LayerConfig{Size: 3, LearningRate: .1, Bias: 1},
+		Hidden: []LayerConfig{{Size: 3, LearningRate: .1, Bias: 1, Activation: "logistic"}},
+		Output: LayerConfig{Size: 1, Activation: "logistic", Cost: "quadratic"},
+	}
+	if _, err := NewFromConfig(config); err != nil {
+		t.Errorf("NewFromConfig() error = %v", err)
+	}
+}
@@ -0,0 +1,138 @@
+package goDeep
+
+import (
+	"encoding/binary"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// ReadCSV reads a matrix of numbers, a sample per line.
+func ReadCSV(r io.Reader) (*Tensor, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	rows := make([][]float64, len(records))
+	for i, record := range records {
+		rows[i] = make([]float64, len(record))
+		for j, field := range record {
+			if rows[i][j], err = strconv.ParseFloat(field, 64); err != nil {
+				return nil, fmt.Errorf("line %d, column %d: %v", i+1, j+1, err)
+			}
+		}
+	}
+	return FromRows(rows)
+}
+
+// WriteCSV writes a matrix, a row per line.
+func WriteCSV(w io.Writer, t *Tensor) error {
+	writer := csv.NewWriter(w)
+	for _, row := range t.Rows() {
+		record := make([]string, len(row))
+		for i, v := range row {
+			record[i] = strconv.FormatFloat(v, 'g', -1, 64)
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// idxTypes are sizes of IDX element types by type codes.
+var idxTypes = map[byte]int{0x08: 1, 0x09: 1, 0x0B: 2, 0x0C: 4, 0x0D: 4, 0x0E: 8}
+
+// idxChunk is a number of bytes of IDX data read at once.
+const idxChunk = 1 << 16
+
+/*
+ReadIDX reads a tensor of the IDX format used by MNIST-like data sets: a magic number of
+an element type and a number of dimensions, big-endian sizes of dimensions and elements
+in a row-major order. Data are read by chunks, so sizes of dimensions declaring more data
+than a reader has fail without allocating a whole tensor.
+*/
+func ReadIDX(r io.Reader) (*Tensor, error) {
+	var magic [4]byte
+	if _, err := io.ReadFull(r, magic[:]); err != nil {
+		return nil, err
+	}
+	size, ok := idxTypes[magic[2]]
+	if magic[0] != 0 || magic[1] != 0 || !ok {
+		return nil, fmt.Errorf("IDX: wrong magic number %x", magic)
+	}
+
+	shape := make([]int, magic[3])
+	for i := range shape {
+		var dim uint32
+		if err := binary.Read(r, binary.BigEndian, &dim); err != nil {
+			return nil, err
+		}
+		shape[i] = int(dim)
+	}
+
+	elements := 1
+	for _, d := range shape {
+		if d != 0 && elements > math.MaxInt/size/d {
+			return nil, fmt.Errorf("IDX: data of a shape %v are too large", shape)
+		}
+		elements *= d
+	}
+
+	capacity := elements
+	if capacity > idxChunk/size {
+		capacity = idxChunk / size
+	}
+	data := make([]float64, 0, capacity)
+	raw := make([]byte, capacity*size)
+	for len(data) < elements {
+		chunk := raw
+		if rest := (elements - len(data)) * size; rest < len(chunk) {
+			chunk = chunk[:rest]
+		}
+		if _, err := io.ReadFull(r, chunk); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return nil, fmt.Errorf("IDX: data end before %d elements", elements)
+			}
+			return nil, err
+		}
+		for i := 0; i < len(chunk); i += size {
+			data = append(data, idxValue(magic[2], chunk[i:i+size]))
+		}
+	}
+	return newTensor(data, shape), nil
+}
+
+// idxValue of bytes of an element of a type code.
+func idxValue(code byte, b []byte) float64 {
+	switch code {
+	case 0x08:
+		return float64(b[0])
+	case 0x09:
+		return float64(int8(b[0]))
+	case 0x0B:
+		return float64(int16(binary.BigEndian.Uint16(b)))
+	case 0x0C:
+		return float64(int32(binary.BigEndian.Uint32(b)))
+	case 0x0D:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b)))
+	}
+	return math.Float64frombits(binary.BigEndian.Uint64(b))
+}
+
+// OneHot encodes class indices as rows of a size with one at an index of a class.
+func OneHot(classes *Tensor, size int) (*Tensor, error) {
+	values := classes.Data()
+	out := Zeros(len(values), size)
+	for i, v := range values {
+		class := int(v)
+		if float64(class) != v || class < 0 || class >= size {
+			return nil, fmt.Errorf("OneHot: %v is not a class of %d", v, size)
+		}
+		out.Set(1, i, class)
+	}
+	return out, nil
+}
@@ -0,0 +1,111 @@
+package goDeep
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    [][]float64
+		wantErr bool
+	}{
+		{name: "matrix", input: "1,2.5\n-3,4e-1\n", want: [][]float64{{1, 2.5}, {-3, .4}}},
+		{name: "notNumber", input: "1,a\n", wantErr: true},
+		{name: "ragged", input: "1,2\n3\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadCSV(strings.NewReader(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadCSV() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(got.Rows(), tt.want) {
+				t.Errorf("ReadCSV() = %v, want %v", got.Rows(), tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, rowsOf([][]float64{{1, .25}, {-3, 1e-7}})); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+	if want := "1,0.25\n-3,1e-07\n"; buf.String() != want {
+		t.Errorf("WriteCSV() = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestReadIDX(t *testing.T) {
+	// Doubles of more bytes than a chunk.
+	doubles := make([]float64, 10000)
+	for i := range doubles {
+		doubles[i] = float64(i) / 2
+	}
+	chunks := bytes.NewBuffer([]byte{0, 0, 0x0E, 1, 0, 0, 0x27, 0x10})
+	binary.Write(chunks, binary.BigEndian, doubles)
+
+	tests := []struct {
+		name      string
+		input     []byte
+		wantShape []int
+		want      []float64
+		wantErr   bool
+	}{
+		{
+			name:      "ubyteImages",
+			input:     []byte{0, 0, 0x08, 3, 0, 0, 0, 2, 0, 0, 0, 1, 0, 0, 0, 2, 0, 255, 7, 8},
+			wantShape: []int{2, 1, 2},
+			want:      []float64{0, 255, 7, 8},
+		},
+		{
+			name:      "shortLabels",
+			input:     []byte{0, 0, 0x0B, 1, 0, 0, 0, 2, 0xFF, 0xFF, 0, 9},
+			wantShape: []int{2},
+			want:      []float64{-1, 9},
+		},
+		{name: "chunks", input: chunks.Bytes(), wantShape: []int{10000}, want: doubles},
+		{name: "empty", input: []byte{0, 0, 0x08, 2, 0, 0, 0, 0, 0, 0, 0, 5}, wantShape: []int{0, 5}, want: []float64{}},
+		{name: "wrongMagic", input: []byte{1, 0, 0x08, 1, 0, 0, 0, 0}, wantErr: true},
+		{name: "truncated", input: []byte{0, 0, 0x08, 1, 0, 0, 0, 3, 1}, wantErr: true},
+		{name: "hugeShape", input: []byte{0, 0, 0x0E, 2, 0x7F, 0xFF, 0xFF, 0xFF, 0, 0, 0, 4, 1, 2}, wantErr: true},
+		{
+			name:    "overflowingShape",
+			input:   []byte{0, 0, 0x0E, 3, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadIDX(bytes.NewReader(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadIDX() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(got.Shape(), tt.wantShape) || !reflect.DeepEqual(got.Data(), tt.want) {
+				t.Errorf("ReadIDX() = %v %v, want %v %v", got.Shape(), got.Data(), tt.wantShape, tt.want)
+			}
+		})
+	}
+}
+
+func TestOneHot(t *testing.T) {
+	got, err := OneHot(Vector([]float64{2, 0}), 3)
+	if err != nil {
+		t.Fatalf("OneHot() error = %v", err)
+	}
+	if want := [][]float64{{0, 0, 1}, {1, 0, 0}}; !reflect.DeepEqual(got.Rows(), want) {
+		t.Errorf("OneHot() = %v, want %v", got.Rows(), want)
+	}
+	if _, err = OneHot(Vector([]float64{3}), 3); err == nil {
+		t.Errorf("OneHot() of an unknown class error = nil, want error")
+	}
+}
@@ -0,0 +1,265 @@
+package goDeep
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+/*
+inputEmbedding is an input layer for categorical and token inputs.
+
+Every input item is an integer index of a category. The layer looks up a trainable
+vector for each index, concatenates the vectors and propagates them to a next layer
+through dense synapses as the inputDense does. Only the rows looked up during a batch
+are corrected, so the size of the vocabulary doesn't affect the cost of a learning step.
+*/
+type inputEmbedding struct {
+	*inputDense
+	vocabulary, dimension, fields int
+	embeddings                    *Tensor
+	embCorrections                map[int][]float64
+	indices                       []int
+	frozenEmbeddings              bool
+}
+
+func (l *inputEmbedding) lookup(input *Tensor) (dense *Tensor, err error) {
+	if err = checkInputSize(input.Size(), l.fields); err != nil {
+		return
+	}
+
+	var row *Tensor
+	var signal []float64
+	l.indices = l.indices[:0]
+	for _, v := range input.Data() {
+		idx := int(v)
+		if float64(idx) != v || idx < 0 || idx >= l.vocabulary {
+			// An index is a position in a vocabulary of an expected size.
+			return nil, &ShapeMismatchError{Op: fmt.Sprintf("Embedding index %v", v), Expected: []int{l.vocabulary}, Actual: []int{idx}}
+		}
+		l.indices = append(l.indices, idx)
+		if row, err = l.embeddings.Index(idx); err != nil {
+			return
+		}
+		signal = append(signal, row.Data()...)
+	}
+
+	// Bias has no input, but the dense synapses expect a slot for it.
+	if l.bias {
+		signal = append(signal, 0)
+	}
+	return Vector(signal), nil
+}
+
+func (l *inputEmbedding) forward(input *Tensor) (output *Tensor, err error) {
+	var dense *Tensor
+	if dense, err = l.lookup(input); err != nil {
+		if lockErr, ok := err.(locatedError); ok {
+			err = lockErr.freeze()
+		}
+		return
+	}
+	return l.inputDense.forward(dense)
+}
+
+func (l *inputEmbedding) backward(eRRors *Tensor) (err error) {
+	if err = l.inputDense.backward(eRRors); err != nil || l.frozenEmbeddings {
+		return
+	}
+
+	if l.embCorrections == nil {
+		l.embCorrections = make(map[int][]float64)
+	}
+
+	// Gradients of the looked up vectors are obtained by the dense graph.
+	grad := l.signal.grad.Data()
+	for f, idx := range l.indices {
+		if l.embCorrections[idx] == nil {
+			l.embCorrections[idx] = make([]float64, l.dimension)
+		}
+		for d := 0; d < l.dimension; d++ {
+			l.embCorrections[idx][d] += grad[f*l.dimension+d]
+		}
+	}
+	return
+}
+
+func (l *inputEmbedding) applyCorrections(batchSize float64) (err error) {
+	if err = l.inputDense.applyCorrections(batchSize); err != nil {
+		return
+	}
+
+	// Untouched rows have no corrections and stay as they are.
+	for idx, corr := range l.embCorrections {
+		for d, c := range corr {
+			l.embeddings.Set(l.embeddings.At(idx, d)-l.learningRate*c/batchSize, idx, d)
+		}
+	}
+	l.embCorrections = nil
+
+	return
+}
+
+func (l *inputEmbedding) discardCorrections() {
+	l.inputDense.discardCorrections()
+	l.embCorrections = nil
+}
+
+// sampleSize of input data is a number of categorical fields.
+func (l *inputEmbedding) sampleSize() int {
+	return l.fields
+}
+
+func (l *inputEmbedding) parameters() []parameter {
+	embeddings := parameter{
+		name:   "embeddings",
+		value:  l.embeddings,
+		frozen: &l.frozenEmbeddings,
+		corrections: func() *Tensor {
+			corrections := Zeros(l.vocabulary, l.dimension)
+			for idx, corr := range l.embCorrections {
+				for d, c := range corr {
+					corrections.Set(c, idx, d)
+				}
+			}
+			return corrections
+		},
+		mapCorrections: func(fn func(float64) float64) {
+			for _, corr := range l.embCorrections {
+				for d, c := range corr {
+					corr[d] = fn(c)
+				}
+			}
+		},
+		setCorrections: func(corrections *Tensor) {
+			if l.embCorrections == nil {
+				l.embCorrections = make(map[int][]float64)
+			}
+			for idx := 0; idx < l.vocabulary; idx++ {
+				for d := 0; d < l.dimension; d++ {
+					c := corrections.At(idx, d)
+					if l.embCorrections[idx] == nil {
+						if c == 0 {
+							continue
+						}
+						l.embCorrections[idx] = make([]float64, l.dimension)
+					}
+					l.embCorrections[idx][d] = c
+				}
+			}
+		},
+	}
+	return append(l.inputDense.parameters(), embeddings)
+}
+
+func (l *inputEmbedding) randomInit(r *rand.Rand) {
+	data := make([]float64, l.vocabulary*l.dimension)
+	for i := range data {
+		data[i] = r.Float64() - 0.5
+	}
+	l.embeddings = newTensor(data, []int{l.vocabulary, l.dimension})
+}
+
+func (l *inputEmbedding) loadEmbeddings(weights *Tensor) error {
+	if shape := []int{l.vocabulary, l.dimension}; !sameShape(weights.Shape(), shape) {
+		return &ShapeMismatchError{Op: "Embedding", Expected: shape, Actual: weights.Shape()}
+	}
+	l.embeddings = weights.Clone()
+	return nil
+}
+
+func newInputEmbedding(
+	vocabulary, dimension, fields, next int, learningRate, bias float64, nextBias bool, weights *Tensor, frozen bool, r *rand.Rand,
+) (inputLayer, error) {
+	curr := fields * dimension
+	if bias != 0 {
+		curr++
+	}
+
+	layer := &inputEmbedding{
+		inputDense:       newInputDense(curr, next, learningRate, bias, nextBias, r).(*inputDense),
+		vocabulary:       vocabulary,
+		dimension:        dimension,
+		fields:           fields,
+		frozenEmbeddings: frozen,
+	}
+
+	if weights == nil {
+		layer.randomInit(r)
+		return layer, nil
+	}
+	if err := layer.loadEmbeddings(weights); err != nil {
+		return nil, err
+	}
+	return layer, nil
+}
+
+// EmbeddingShape is an intuitive embedding input layer representation. Designed to
+// pass declaration arguments in intuitive form.
+//
+// Fields is a number of categorical items in a single input, Vocabulary is a number
+// of categories and Dimension is a size of a vector representing a category.
+// Weights are optional pretrained vectors, Vocabulary x Dimension. Frozen embeddings
+// are not corrected during learning.
+type EmbeddingShape struct {
+	Vocabulary, Dimension, Fields int
+	LearningRate, Bias            float64
+	Weights                       *Tensor
+	Frozen                        bool
+}
+
+// validate sizes, learning rate and bias of an embedding layer. Weights shape is
+// checked by the layer.
+func (s EmbeddingShape) validate() error {
+	for _, size := range []struct {
+		field string
+		value int
+	}{
+		{"EmbeddingShape.Vocabulary", s.Vocabulary},
+		{"EmbeddingShape.Dimension", s.Dimension},
+		{"EmbeddingShape.Fields", s.Fields},
+	} {
+		if size.value <= 0 {
+			return &ConfigError{Field: size.field, Value: size.value, Reason: "must be positive"}
+		}
+	}
+	return validateRates("EmbeddingShape", s.LearningRate, s.Bias)
+}
+
+// NewEmbeddingPerceptron is a MLP initializer with an embedding input layer.
+// Input of the network is a vector of category indices.
+func NewEmbeddingPerceptron(embeddingShape EmbeddingShape, hiddenShapes []HiddenShape, outputShape OutputShape) (Network, error) {
+	return newEmbeddingPerceptron(embeddingShape, hiddenShapes, outputShape, newRandom(0))
+}
+
+// newEmbeddingPerceptron of initial embeddings and synapses drawn from a source.
+func newEmbeddingPerceptron(embeddingShape EmbeddingShape, hiddenShapes []HiddenShape, outputShape OutputShape, r *rand.Rand) (*Perceptron, error) {
+	if err := embeddingShape.validate(); err != nil {
+		return nil, err
+	}
+	if err := validateHiddenShapes(hiddenShapes); err != nil {
+		return nil, err
+	}
+	if err := outputShape.validate(); err != nil {
+		return nil, err
+	}
+
+	input, err := newInputEmbedding(
+		embeddingShape.Vocabulary,
+		embeddingShape.Dimension,
+		embeddingShape.Fields,
+		hiddenShapes[0].Size,
+		embeddingShape.LearningRate,
+		embeddingShape.Bias,
+		hiddenShapes[0].Bias != 0,
+		embeddingShape.Weights,
+		embeddingShape.Frozen,
+		r,
+	)
+	if err != nil {
+		return nil, err
+	}
+	n := newPerceptron(input, embeddingShape.Fields*embeddingShape.Dimension, hiddenShapes, outputShape, r)
+	embeddingShape.Weights = nil
+	n.shapes.embedding = &embeddingShape
+	return n, nil
+}
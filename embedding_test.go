@@ -0,0 +1,214 @@
+package goDeep
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_inputEmbedding_forward(t *testing.T) {
+	type fields struct {
+		synapses   [][]float64
+		embeddings [][]float64
+		dimension  int
+		fields     int
+		nextSize   int
+	}
+	type args struct {
+		input []float64
+	}
+	tests := []struct {
+		name       string
+		fields     fields
+		args       args
+		wantOutput [][]float64
+		wantErr    bool
+	}{
+		{
+			name: "lookupAndForward",
+			fields: fields{
+				synapses:   [][]float64{{1, 10}, {2, 20}, {3, 30}, {4, 40}},
+				embeddings: [][]float64{{1, 1}, {2, 3}, {4, 5}},
+				dimension:  2,
+				fields:     2,
+				nextSize:   2,
+			},
+			args:       args{[]float64{2, 1}},
+			wantOutput: [][]float64{{4, 10, 6, 12}, {40, 100, 60, 120}},
+		},
+		{
+			name: "indexOutOfVocabulary",
+			fields: fields{
+				synapses:   [][]float64{{1, 10}, {2, 20}},
+				embeddings: [][]float64{{1, 1}},
+				dimension:  2,
+				fields:     1,
+				nextSize:   2,
+			},
+			args:    args{[]float64{1}},
+			wantErr: true,
+		},
+		{
+			name: "fractionalIndex",
+			fields: fields{
+				synapses:   [][]float64{{1, 10}, {2, 20}},
+				embeddings: [][]float64{{1, 1}, {2, 2}},
+				dimension:  2,
+				fields:     1,
+				nextSize:   2,
+			},
+			args:    args{[]float64{.5}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &inputEmbedding{
+				inputDense: &inputDense{
+					synapses:      rowsOf(tt.fields.synapses),
+					currLayerSize: tt.fields.fields * tt.fields.dimension,
+					nextLayerSize: tt.fields.nextSize,
+				},
+				vocabulary: len(tt.fields.embeddings),
+				dimension:  tt.fields.dimension,
+				fields:     tt.fields.fields,
+				embeddings: rowsOf(tt.fields.embeddings),
+			}
+			gotOutput, err := l.forward(Vector(tt.args.input))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("inputEmbedding.forward() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(gotOutput.Rows(), tt.wantOutput) {
+				t.Errorf("inputEmbedding.forward() = %v, want %v", gotOutput.Rows(), tt.wantOutput)
+			}
+		})
+	}
+}
+
+func Test_inputEmbedding_applyCorrections(t *testing.T) {
+	type fields struct {
+		synapses   [][]float64
+		embeddings [][]float64
+		frozen     bool
+	}
+	type args struct {
+		input  []float64
+		eRRors []float64
+	}
+	tests := []struct {
+		name           string
+		fields         fields
+		args           args
+		wantEmbeddings [][]float64
+		wantSynapses   [][]float64
+	}{
+		{
+			name: "touchedRowsOnly",
+			fields: fields{
+				synapses:   [][]float64{{1, 2}, {3, 4}},
+				embeddings: [][]float64{{1, 1}, {2, 3}, {4, 5}},
+			},
+			args: args{
+				input:  []float64{1},
+				eRRors: []float64{1, 1},
+			},
+			wantEmbeddings: [][]float64{{1, 1}, {-1, -4}, {4, 5}},
+			wantSynapses:   [][]float64{{-1, 0}, {0, 1}},
+		},
+		{
+			name: "frozen",
+			fields: fields{
+				synapses:   [][]float64{{1, 2}, {3, 4}},
+				embeddings: [][]float64{{1, 1}, {2, 3}, {4, 5}},
+				frozen:     true,
+			},
+			args: args{
+				input:  []float64{1},
+				eRRors: []float64{1, 1},
+			},
+			wantEmbeddings: [][]float64{{1, 1}, {2, 3}, {4, 5}},
+			wantSynapses:   [][]float64{{-1, 0}, {0, 1}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &inputEmbedding{
+				inputDense: &inputDense{
+					synapses:      rowsOf(tt.fields.synapses),
+					currLayerSize: 2,
+					nextLayerSize: 2,
+					learningRate:  1,
+				},
+				vocabulary:       len(tt.fields.embeddings),
+				dimension:        2,
+				fields:           1,
+				embeddings:       rowsOf(tt.fields.embeddings),
+				frozenEmbeddings: tt.fields.frozen,
+			}
+			if _, err := l.forward(Vector(tt.args.input)); err != nil {
+				t.Fatalf("inputEmbedding.forward() error = %v", err)
+			}
+			if err := l.backward(Vector(tt.args.eRRors)); err != nil {
+				t.Fatalf("inputEmbedding.backward() error = %v", err)
+			}
+			if err := l.applyCorrections(1); err != nil {
+				t.Fatalf("inputEmbedding.applyCorrections() error = %v", err)
+			}
+			if !reflect.DeepEqual(l.embeddings.Rows(), tt.wantEmbeddings) {
+				t.Errorf("inputEmbedding.embeddings = %v, want %v", l.embeddings.Rows(), tt.wantEmbeddings)
+			}
+			if !reflect.DeepEqual(l.synapses.Rows(), tt.wantSynapses) {
+				t.Errorf("inputEmbedding.synapses = %v, want %v", l.synapses.Rows(), tt.wantSynapses)
+			}
+		})
+	}
+}
+
+func Test_newInputEmbedding(t *testing.T) {
+	tests := []struct {
+		name    string
+		weights [][]float64
+		wantErr bool
+	}{
+		{name: "random"},
+		{name: "pretrained", weights: [][]float64{{1, 2}, {3, 4}, {5, 6}}},
+		{name: "pretrainedWrongVocabulary", weights: [][]float64{{1, 2}}, wantErr: true},
+		{name: "pretrainedWrongDimension", weights: [][]float64{{1}, {3}, {5}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var weights *Tensor
+			if tt.weights != nil {
+				weights = rowsOf(tt.weights)
+			}
+			got, err := newInputEmbedding(3, 2, 2, 4, .1, 1, true, weights, false, newRandom(1))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("newInputEmbedding() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			l := got.(*inputEmbedding)
+			if rowsNumber(l.embeddings) != 3 || rowsNumber(l.synapses) != 5 {
+				t.Errorf("newInputEmbedding() embeddings = %d, synapses = %d, want 3, 5", rowsNumber(l.embeddings), rowsNumber(l.synapses))
+			}
+			if tt.weights != nil && !reflect.DeepEqual(l.embeddings.Rows(), tt.weights) {
+				t.Errorf("newInputEmbedding() embeddings = %v, want %v", l.embeddings.Rows(), tt.weights)
+			}
+		})
+	}
+
+	// Random embeddings are drawn from a given source, so a seed reproduces them.
+	var embeddings [2][][]float64
+	for i := range embeddings {
+		got, err := newInputEmbedding(3, 2, 2, 4, .1, 1, true, nil, false, newRandom(7))
+		if err != nil {
+			t.Fatalf("newInputEmbedding() error = %v", err)
+		}
+		embeddings[i] = got.(*inputEmbedding).embeddings.Rows()
+	}
+	if !reflect.DeepEqual(embeddings[0], embeddings[1]) {
+		t.Errorf("newInputEmbedding() of a seed embeddings = %v and %v, want equal", embeddings[0], embeddings[1])
+	}
+}
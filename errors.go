@@ -1,10 +1,20 @@
 package goDeep
 
 import (
+	"errors"
 	"fmt"
 	"runtime"
 )
 
+// Sentinels matched by typed errors of the package with errors.Is.
+var (
+	ErrShapeMismatch = errors.New("shape mismatch")
+	ErrNumeric       = errors.New("non-finite value")
+	ErrConfig        = errors.New("invalid configuration")
+)
+
+// locatedError reports broken invariants of the package, e.g. a backward pass without
+// a forward one or an index out of a tensor range, with a location of a failure.
 type locatedError struct {
 	msg string
 }
@@ -20,3 +30,86 @@ func (e locatedError) freeze() error {
 func (e locatedError) Error() string {
 	return e.msg
 }
+
+/*
+ShapeMismatchError reports an operation on data of inconsistent shapes.
+
+Layer is a number of a layer in a network counted from one at the input layer, it is zero
+if an error is not raised by a layer.
+*/
+type ShapeMismatchError struct {
+	Op               string
+	Layer            int
+	Expected, Actual []int
+}
+
+func (e *ShapeMismatchError) Error() string {
+	if e.Layer != 0 {
+		return fmt.Sprintf("layer %d: %s: shape mismatch, expected %v, actual %v", e.Layer, e.Op, e.Expected, e.Actual)
+	}
+	return fmt.Sprintf("%s: shape mismatch, expected %v, actual %v", e.Op, e.Expected, e.Actual)
+}
+
+// Is reports a shape mismatch sentinel.
+func (e *ShapeMismatchError) Is(target error) bool {
+	return target == ErrShapeMismatch
+}
+
+/*
+NumericError reports a non-finite or an out of a function domain value.
+
+Layer is a number of a layer in a network counted from one at the input layer, it is zero
+if an error is not raised by a layer. Stage is "activations" or a name of a parameter
+for weights and "gradients of" it for corrections. A neuron of activations is a neuron of
+a layer, a neuron of weights and gradients is a row of a parameter. Batches are counted
+from zero through all the epochs. Inputs are samples which produced the value: a sample
+for activations and a batch for weights and gradients.
+*/
+type NumericError struct {
+	Layer  int
+	Stage  string
+	Neuron int
+	Batch  int
+	Value  float64
+	Inputs *Tensor
+}
+
+func (e *NumericError) Error() string {
+	return fmt.Sprintf("layer %d: %v in %s of neuron %d, batch %d", e.Layer, e.Value, e.Stage, e.Neuron, e.Batch)
+}
+
+// Is reports a numeric sentinel.
+func (e *NumericError) Is(target error) bool {
+	return target == ErrNumeric
+}
+
+// ConfigError reports an invalid field of a network declaration.
+type ConfigError struct {
+	Field  string
+	Value  interface{}
+	Reason string
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("%s = %v: %s", e.Field, e.Value, e.Reason)
+}
+
+// Is reports a configuration sentinel.
+func (e *ConfigError) Is(target error) bool {
+	return target == ErrConfig
+}
+
+// atLayer sets a number of a layer to typed errors not bound to a layer yet.
+func atLayer(err error, layer int) error {
+	switch e := err.(type) {
+	case *ShapeMismatchError:
+		if e.Layer == 0 {
+			e.Layer = layer
+		}
+	case *NumericError:
+		if e.Layer == 0 {
+			e.Layer = layer
+		}
+	}
+	return err
+}
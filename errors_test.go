@@ -0,0 +1,126 @@
+package goDeep
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestPerceptron_typedErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		set       [][]float64
+		labels    [][]float64
+		sentinel  error
+		wantLayer int
+	}{
+		{name: "inputSize", set: [][]float64{{1, 0}}, labels: [][]float64{{0}}, sentinel: ErrShapeMismatch, wantLayer: 1},
+		{name: "labelsSize", set: [][]float64{{1, 0, 0}}, labels: [][]float64{{0, 1}}, sentinel: ErrShapeMismatch, wantLayer: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields := mockPerceptronFields()
+			n := &Perceptron{input: fields.input, hidden: fields.hidden, output: fields.output}
+
+			_, err := n.Learn(rowsOf(tt.set), rowsOf(tt.labels), 1, 1)
+			if !errors.Is(err, tt.sentinel) {
+				t.Fatalf("Perceptron.Learn() error = %v, want %v", err, tt.sentinel)
+			}
+			var shapeErr *ShapeMismatchError
+			if !errors.As(err, &shapeErr) {
+				t.Fatalf("Perceptron.Learn() error = %T, want *ShapeMismatchError", err)
+			}
+			if shapeErr.Layer != tt.wantLayer {
+				t.Errorf("ShapeMismatchError.Layer = %d, want %d", shapeErr.Layer, tt.wantLayer)
+			}
+		})
+	}
+}
+
+func TestConfigError_Is(t *testing.T) {
+	err := error(&ConfigError{Field: "Size", Value: 0, Reason: "must be positive"})
+	if !errors.Is(err, ErrConfig) || errors.Is(err, ErrShapeMismatch) {
+		t.Errorf("errors.Is(%v) does not match ErrConfig only", err)
+	}
+}
+
+func TestTypedErrors(t *testing.T) {
+	embedded, err := NewFromConfig(ModelConfig{
+		Embedding: &EmbeddingConfig{Vocabulary: 5, Dimension: 2, Fields: 2, LearningRate: .1},
+		Hidden:    []LayerConfig{{Size: 3, LearningRate: .1, Bias: 1, Activation: "sigmoid"}},
+		Output:    LayerConfig{Size: 2, Activation: "sigmoid", Cost: "quadratic"},
+	})
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	tests := []struct {
+		name     string
+		err      func() error
+		sentinel error
+	}{
+		{
+			name: "sigmoidDomain",
+			err: func() error {
+				_, err := prunedNetwork(t).Recognize(rowsOf([][]float64{{1e6, 0, 0}}))
+				return err
+			},
+			sentinel: ErrNumeric,
+		},
+		{
+			name: "softmaxValue",
+			err: func() error {
+				_, err := new(Softmax).activate(1)
+				return err
+			},
+			sentinel: ErrConfig,
+		},
+		{
+			name: "outOfVocabulary",
+			err: func() error {
+				_, err := embedded.Recognize(rowsOf([][]float64{{1, 5}}))
+				return err
+			},
+			sentinel: ErrShapeMismatch,
+		},
+		{
+			name: "quantizeEmbedding",
+			err: func() error {
+				_, err := Quantize(embedded, rowsOf([][]float64{{1, 2}}), QuantizeOptions{})
+				return err
+			},
+			sentinel: ErrConfig,
+		},
+		{
+			name:     "generateEmbedding",
+			err:      func() error { return GenerateGo(new(bytes.Buffer), embedded, "model") },
+			sentinel: ErrConfig,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.err(); !errors.Is(err, tt.sentinel) {
+				t.Errorf("error = %v, want %v", err, tt.sentinel)
+			}
+		})
+	}
+
+	_, err = prunedNetwork(t).Recognize(rowsOf([][]float64{{0, 1e6, 0}}))
+	var numericErr *NumericError
+	if !errors.As(err, &numericErr) {
+		t.Fatalf("Recognize() of a sigmoid out of a domain error = %v, want *NumericError", err)
+	}
+	// Sums of hidden neurons are .5e6+.01 and -.05e6+.02, the first one is out of a domain.
+	if numericErr.Layer != 2 || numericErr.Stage != "activations" || numericErr.Neuron != 0 || numericErr.Value != .5e6+.01 {
+		t.Errorf("NumericError = %+v, want activations of neuron 0 of layer 2", numericErr)
+	}
+
+	_, err = embedded.Recognize(rowsOf([][]float64{{1, 5}}))
+	var shapeErr *ShapeMismatchError
+	if !errors.As(err, &shapeErr) || shapeErr.Layer != 1 {
+		t.Errorf("Recognize() of an index out of a vocabulary error = %v, want *ShapeMismatchError of layer 1", err)
+	}
+	var configErr *ConfigError
+	if _, err = NewSparse(embedded); !errors.As(err, &configErr) {
+		t.Errorf("NewSparse() of an embedding error = %v, want *ConfigError", err)
+	}
+}
@@ -0,0 +1,234 @@
+package goDeep
+
+import "math"
+
+// parameter is a named trainable tensor of a layer with its accumulated corrections.
+// Corrections are changed in place by mapCorrections, e.g. to clip them.
+type parameter struct {
+	name           string
+	value          *Tensor
+	corrections    func() *Tensor
+	mapCorrections func(fn func(float64) float64)
+	// setCorrections of a parameter collecting corrections into a copy, nil if corrections
+	// are a tensor of a layer.
+	setCorrections func(*Tensor)
+	// mask of pruned elements of a parameter, nil if a parameter is not pruned.
+	mask **Tensor
+	// frozen parameters are not corrected.
+	frozen *bool
+}
+
+// denseParameter is a parameter with corrections accumulated in a tensor of a layer.
+func denseParameter(name string, value *Tensor, corrections, mask **Tensor, frozen *bool) parameter {
+	return parameter{
+		name:        name,
+		value:       value,
+		mask:        mask,
+		frozen:      frozen,
+		corrections: func() *Tensor { return *corrections },
+		mapCorrections: func(fn func(float64) float64) {
+			if *corrections != nil {
+				(*corrections).applyInPlace(fn)
+			}
+		},
+	}
+}
+
+// GradientCheck is a result of a gradient check of a parameter tensor. The relative
+// error is |analytic - numeric| / max(|analytic|, |numeric|) of the worst element.
+type GradientCheck struct {
+	Parameter        string
+	MaxRelativeError float64
+}
+
+/*
+CheckGradients compares analytic gradients of a network obtained by back propagation
+against central finite differences.
+
+A loss is a sum of costs of all the samples of a set. Every parameter element is shifted
+by epsilon in both directions, so the check is slow and intended for small networks and
+sets. Pending corrections of the network are discarded. Errors below 1e-6 are typical
+for correct gradients.
+*/
+func CheckGradients(n Network, set, labels *Tensor, epsilon float64) ([]GradientCheck, error) {
+	var sample, label *Tensor
+	var err error
+
+	eachSample := func(fn func(sample, label *Tensor) error) error {
+		for i := 0; i < rowsNumber(set); i++ {
+			if sample, err = set.Index(i); err != nil {
+				return err
+			}
+			if label, err = labels.Index(i); err != nil {
+				return err
+			}
+			if err = fn(sample, label); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	loss := func() (sum float64, err error) {
+		err = eachSample(func(sample, label *Tensor) error {
+			_, cost, err := n.forwardMeasure(sample, label)
+			sum += cost
+			return err
+		})
+		return
+	}
+
+	n.discardCorrections()
+	defer n.discardCorrections()
+	err = eachSample(func(sample, label *Tensor) error {
+		prediction, _, err := n.forwardMeasure(sample, label)
+		if err != nil {
+			return err
+		}
+		return n.backward(prediction, label)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return compareGradients(n.parameters(), loss, epsilon)
+}
+
+// compareGradients checks accumulated corrections of parameters against finite
+// differences of a loss.
+func compareGradients(params []parameter, loss func() (float64, error), epsilon float64) (checks []GradientCheck, err error) {
+	for _, p := range params {
+		var analytic []float64
+		if corr := p.corrections(); corr != nil {
+			analytic = corr.Data()
+		} else {
+			analytic = make([]float64, p.value.Size())
+		}
+
+		var i int
+		var maxErr, orig, plus, minus float64
+		p.value.each(func(pos int) {
+			if err != nil {
+				return
+			}
+			orig = p.value.data.at(pos)
+			p.value.data.set(pos, orig+epsilon)
+			if plus, err = loss(); err != nil {
+				return
+			}
+			p.value.data.set(pos, orig-epsilon)
+			if minus, err = loss(); err != nil {
+				return
+			}
+			p.value.data.set(pos, orig)
+
+			maxErr = math.Max(maxErr, relativeError(analytic[i], (plus-minus)/(2*epsilon)))
+			i++
+		})
+		if err != nil {
+			return nil, err
+		}
+		checks = append(checks, GradientCheck{Parameter: p.name, MaxRelativeError: maxErr})
+	}
+	return
+}
+
+func relativeError(a, b float64) float64 {
+	scale := math.Max(math.Abs(a), math.Abs(b))
+	if scale < 1e-10 {
+		return 0
+	}
+	return math.Abs(a-b) / scale
+}
+
+// projectedLoss is a loss of a layer checked in isolation: input sums of next layer
+// neurons weighted by a fixed projection. The projection is an error signal of the loss.
+func projectedLoss(output, projection *Tensor) (float64, error) {
+	sums, err := output.SumAxis(1)
+	if err != nil {
+		return 0, err
+	}
+	weighted, err := sums.Mul(projection)
+	if err != nil {
+		return 0, err
+	}
+	return weighted.Sum(), nil
+}
+
+// inputParameter represents an input of a layer summed by neurons, its gradient is
+// an error of a neuron for every summed signal.
+func inputParameter(input *Tensor, eRRors func() *Tensor) parameter {
+	return parameter{
+		name:  "input",
+		value: input,
+		corrections: func() *Tensor {
+			column, err := eRRors().Reshape(-1, 1)
+			if err != nil {
+				return nil
+			}
+			spread, err := column.broadcastTo(input.shape)
+			if err != nil {
+				return nil
+			}
+			return spread.Clone()
+		},
+	}
+}
+
+func checkInputLayerGradients(l inputLayer, input, projection *Tensor, epsilon float64) ([]GradientCheck, error) {
+	loss := func() (float64, error) {
+		output, err := l.forward(input)
+		if err != nil {
+			return 0, err
+		}
+		return projectedLoss(output, projection)
+	}
+
+	l.discardCorrections()
+	defer l.discardCorrections()
+	if _, err := l.forward(input); err != nil {
+		return nil, err
+	}
+	if err := l.backward(projection); err != nil {
+		return nil, err
+	}
+	return compareGradients(l.parameters(), loss, epsilon)
+}
+
+func checkHiddenLayerGradients(l hiddenLayer, input, projection *Tensor, epsilon float64) ([]GradientCheck, error) {
+	loss := func() (float64, error) {
+		output, err := l.forward(input)
+		if err != nil {
+			return 0, err
+		}
+		return projectedLoss(output, projection)
+	}
+
+	l.discardCorrections()
+	defer l.discardCorrections()
+	if _, err := l.forward(input); err != nil {
+		return nil, err
+	}
+	prevLayerErrors, err := l.backward(projection)
+	if err != nil {
+		return nil, err
+	}
+	params := append(l.parameters(), inputParameter(input, func() *Tensor { return prevLayerErrors }))
+	return compareGradients(params, loss, epsilon)
+}
+
+func checkOutputLayerGradients(l outputLayer, input, labels *Tensor, epsilon float64) ([]GradientCheck, error) {
+	loss := func() (float64, error) {
+		_, cost, err := l.forwardMeasure(input, labels)
+		return cost, err
+	}
+
+	prediction, _, err := l.forwardMeasure(input, labels)
+	if err != nil {
+		return nil, err
+	}
+	eRRors, err := l.backward(prediction, labels)
+	if err != nil {
+		return nil, err
+	}
+	return compareGradients([]parameter{inputParameter(input, func() *Tensor { return eRRors })}, loss, epsilon)
+}
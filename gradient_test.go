@@ -0,0 +1,161 @@
+package goDeep
+
+import (
+	"testing"
+)
+
+const gradientTolerance = 1e-5
+
+func assertGradients(t *testing.T, checks []GradientCheck, err error, wantParams int) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("gradient check error = %v", err)
+	}
+	if len(checks) != wantParams {
+		t.Errorf("gradient check parameters = %d, want %d", len(checks), wantParams)
+	}
+	for _, c := range checks {
+		if c.MaxRelativeError > gradientTolerance {
+			t.Errorf("%s gradient relative error = %v, want < %v", c.Parameter, c.MaxRelativeError, gradientTolerance)
+		}
+	}
+}
+
+func Test_inputDense_gradients(t *testing.T) {
+	tests := []struct {
+		name           string
+		curr, next     int
+		bias           float64
+		nextBias       bool
+		input          []float64
+		projection     []float64
+		wantParameters int
+	}{
+		{name: "bias", curr: 4, next: 5, bias: 1, nextBias: true, input: []float64{.1, -.4, .7, 0}, projection: []float64{.3, -1, .5, 2}, wantParameters: 1},
+		{name: "noBias", curr: 3, next: 2, input: []float64{.1, -.4, .7}, projection: []float64{.3, -1}, wantParameters: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newInputDense(tt.curr, tt.next, .1, tt.bias, tt.nextBias, newRandom(1))
+			checks, err := checkInputLayerGradients(l, Vector(tt.input), Vector(tt.projection), 1e-6)
+			assertGradients(t, checks, err, tt.wantParameters)
+		})
+	}
+}
+
+func Test_inputEmbedding_gradients(t *testing.T) {
+	l, err := newInputEmbedding(4, 3, 2, 4, .1, 1, true, nil, false, newRandom(1))
+	if err != nil {
+		t.Fatalf("newInputEmbedding() error = %v", err)
+	}
+	checks, err := checkInputLayerGradients(l, Vector([]float64{3, 1}), Vector([]float64{.3, -1, .5}), 1e-6)
+	assertGradients(t, checks, err, 2)
+}
+
+func Test_hiddenDense_gradients(t *testing.T) {
+	tests := []struct {
+		name       string
+		activation activation
+		input      [][]float64
+		projection []float64
+		wantFail   bool
+	}{
+		{
+			name:       "sigmoid",
+			activation: new(Sigmoid),
+			input:      [][]float64{{.1, .2}, {-.3, .1}, {.5, .5}, {0, -.2}},
+			projection: []float64{1, -.5, .25},
+		},
+		{
+			name:       "wrongDerivative",
+			activation: new(mockActivation),
+			input:      [][]float64{{.1, .2}, {-.3, .1}, {.5, .5}, {0, -.2}},
+			projection: []float64{1, -.5, .25},
+			wantFail:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newHiddenDense(4, 5, 3, 1, .1, tt.activation, false, newRandom(1))
+			checks, err := checkHiddenLayerGradients(l, rowsOf(tt.input), Vector(tt.projection), 1e-6)
+			if !tt.wantFail {
+				assertGradients(t, checks, err, 2)
+				return
+			}
+			if err != nil {
+				t.Fatalf("checkHiddenLayerGradients() error = %v", err)
+			}
+			var failed bool
+			for _, c := range checks {
+				failed = failed || c.MaxRelativeError > gradientTolerance
+			}
+			if !failed {
+				t.Errorf("checkHiddenLayerGradients() = %v, want a failed check", checks)
+			}
+		})
+	}
+}
+
+func Test_outputDense_gradients(t *testing.T) {
+	l := newOutput(5, 3, new(Sigmoid), new(Quadratic))
+	input := rowsOf([][]float64{{.1, .2, .3}, {-.3, .1, 0}, {.5, .5, -1}})
+	checks, err := checkOutputLayerGradients(l, input, Vector([]float64{0, 1, 0}), 1e-6)
+	assertGradients(t, checks, err, 1)
+}
+
+func TestCheckGradients(t *testing.T) {
+	set := rowsOf([][]float64{{0, 1, 0}, {1, 1, 0}})
+	labels := rowsOf([][]float64{{1, 0}, {0, 1}})
+	// Seeded initial weights keep numeric gradients of a check within a tolerance.
+	n, err := newDensePerceptron(
+		InputShape{Size: 3, LearningRate: .1, Bias: 1},
+		[]HiddenShape{{Size: 4, LearningRate: .1, Bias: 1, Activation: new(Sigmoid)}},
+		OutputShape{Size: 2, Activation: new(Sigmoid), Cost: new(Quadratic)},
+		newRandom(1),
+	)
+	if err != nil {
+		t.Fatalf("newDensePerceptron() error = %v", err)
+	}
+	checks, err := CheckGradients(n, set, labels, 1e-6)
+	assertGradients(t, checks, err, 2)
+
+	embedded, err := newEmbeddingPerceptron(
+		EmbeddingShape{Vocabulary: 5, Dimension: 2, Fields: 2, LearningRate: .1, Bias: 1},
+		[]HiddenShape{{Size: 4, LearningRate: .1, Bias: 1, Activation: new(Sigmoid)}},
+		OutputShape{Size: 2, Activation: new(Sigmoid), Cost: new(Quadratic)},
+		newRandom(1),
+	)
+	if err != nil {
+		t.Fatalf("newEmbeddingPerceptron() error = %v", err)
+	}
+	checks, err = CheckGradients(embedded, rowsOf([][]float64{{0, 4}, {2, 2}}), labels, 1e-6)
+	assertGradients(t, checks, err, 3)
+}
+
+func TestCheckGradients_activations(t *testing.T) {
+	set := rowsOf([][]float64{{0, 1, 0}, {1, 1, 0}})
+	labels := rowsOf([][]float64{{1, 0, 0}, {0, .5, .5}})
+	tests := []struct {
+		name           string
+		hidden, output activation
+	}{
+		{name: "tanh", hidden: new(Tanh), output: new(Tanh)},
+		{name: "relu", hidden: new(Relu), output: new(Sigmoid)},
+		{name: "softmax", hidden: new(Sigmoid), output: new(Softmax)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := newDensePerceptron(
+				InputShape{Size: 3, LearningRate: .1, Bias: 1},
+				[]HiddenShape{{Size: 4, LearningRate: .1, Bias: 1, Activation: tt.hidden}},
+				OutputShape{Size: 3, Activation: tt.output, Cost: new(Quadratic)},
+				newRandom(1),
+			)
+			if err != nil {
+				t.Fatalf("newDensePerceptron() error = %v", err)
+			}
+			checks, err := CheckGradients(n, set, labels, 1e-6)
+			assertGradients(t, checks, err, 2)
+		})
+	}
+}
@@ -0,0 +1,252 @@
+package goDeep
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// maxGraphNeurons of a network drawn by neurons.
+const maxGraphNeurons = 64
+
+// GraphOptions of a network drawing. Neurons draws every neuron and synapse of small
+// networks, synapses are colored by weights: blue positive and red negative ones.
+type GraphOptions struct {
+	Neurons bool
+}
+
+// graph of a network: layers and synapses of every layer to a next one if neurons are drawn.
+type graph struct {
+	layers   []LayerSummary
+	synapses [][][]float64
+	// Largest absolute weights of layers.
+	ranges []float64
+}
+
+func graphOf(n Network, options GraphOptions) (*graph, error) {
+	s, err := Summary(n)
+	if err != nil {
+		return nil, err
+	}
+	g := &graph{layers: s.Layers}
+	if !options.Neurons {
+		return g, nil
+	}
+	var neurons int
+	for _, l := range g.layers {
+		neurons += l.OutputShape[0]
+	}
+	if neurons > maxGraphNeurons {
+		return nil, &ConfigError{Field: "GraphOptions.Neurons", Value: neurons, Reason: fmt.Sprintf("only networks of at most %d neurons are drawn by neurons", maxGraphNeurons)}
+	}
+	params := parametersOf(n)
+	for _, l := range g.layers[:len(g.layers)-1] {
+		synapses := params[l.Name+".synapses"].Rows()
+		var max float64
+		for _, row := range synapses {
+			for _, w := range row {
+				max = math.Max(max, math.Abs(w))
+			}
+		}
+		g.synapses = append(g.synapses, synapses)
+		g.ranges = append(g.ranges, max)
+	}
+	return g, nil
+}
+
+// neurons of a layer, a bias neuron is the last one.
+func (g *graph) neurons(layer int) int {
+	return g.layers[layer].OutputShape[0]
+}
+
+// label lines of a layer.
+func (g *graph) label(layer int) []string {
+	l := g.layers[layer]
+	lines := []string{l.Name, fmt.Sprintf("%s, %d", l.Type, l.OutputShape[0])}
+	if l.Bias {
+		lines[1] += " with a bias"
+	}
+	for _, line := range []string{l.Activation, l.Cost} {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// edge of a synapse from a neuron of a layer to a neuron of a next one: a color and a width.
+func (g *graph) edge(layer, from, to int) (color string, width float64) {
+	w := g.synapses[layer][from][to]
+	var intensity float64
+	if g.ranges[layer] > 0 {
+		intensity = math.Abs(w) / g.ranges[layer]
+	}
+	// Light grey of weak synapses to blue or red of the strongest ones.
+	light, strong := [3]float64{221, 221, 221}, [3]float64{33, 102, 172}
+	if w < 0 {
+		strong = [3]float64{178, 24, 43}
+	}
+	var rgb [3]int
+	for i := range rgb {
+		rgb[i] = int(math.Round(light[i] + (strong[i]-light[i])*intensity))
+	}
+	return fmt.Sprintf("#%02x%02x%02x", rgb[0], rgb[1], rgb[2]), .5 + 2*intensity
+}
+
+/*
+WriteDOT writes a Graphviz DOT graph of a network. Layers are nodes annotated with types,
+sizes, biases, activations and a cost, edges are labeled with numbers of synapses. Neurons
+of options are clustered by layers, bias neurons are dashed.
+*/
+func WriteDOT(w io.Writer, n Network, options GraphOptions) error {
+	g, err := graphOf(n, options)
+	if err != nil {
+		return err
+	}
+	var dot bytes.Buffer
+	fmt.Fprintf(&dot, "digraph network {\n\trankdir=LR;\n")
+	if options.Neurons {
+		g.writeNeuronsDOT(&dot)
+	} else {
+		g.writeLayersDOT(&dot)
+	}
+	fmt.Fprintf(&dot, "}\n")
+	_, err = dot.WriteTo(w)
+	return err
+}
+
+func (g *graph) writeLayersDOT(dot *bytes.Buffer) {
+	fmt.Fprintf(dot, "\tnode [shape=box];\n")
+	for i, l := range g.layers {
+		fmt.Fprintf(dot, "\t%q [label=%q];\n", l.Name, strings.Join(g.label(i), "\n"))
+	}
+	for i, l := range g.layers[:len(g.layers)-1] {
+		fmt.Fprintf(dot, "\t%q -> %q [label=\"%d\"];\n", l.Name, g.layers[i+1].Name, l.Parameters())
+	}
+}
+
+func (g *graph) writeNeuronsDOT(dot *bytes.Buffer) {
+	fmt.Fprintf(dot, "\tsplines=line;\n\tnode [shape=circle, label=\"\", width=.3];\n")
+	neuron := func(layer, i int) string {
+		return fmt.Sprintf("%q", fmt.Sprintf("%s/%d", g.layers[layer].Name, i))
+	}
+	for i, l := range g.layers {
+		fmt.Fprintf(dot, "\tsubgraph %q {\n\t\tlabel=%q;\n", "cluster_"+l.Name, strings.Join(g.label(i), "\n"))
+		for j := 0; j < g.neurons(i); j++ {
+			style := ""
+			if l.Bias && j == g.neurons(i)-1 {
+				style = " [style=dashed]"
+			}
+			fmt.Fprintf(dot, "\t\t%s%s;\n", neuron(i, j), style)
+		}
+		fmt.Fprintf(dot, "\t}\n")
+	}
+	for i, synapses := range g.synapses {
+		for from, row := range synapses {
+			for to, weight := range row {
+				color, width := g.edge(i, from, to)
+				fmt.Fprintf(dot, "\t%s -> %s [color=%q, penwidth=%.2f, tooltip=\"%g\"];\n", neuron(i, from), neuron(i+1, to), color, width, weight)
+			}
+		}
+	}
+}
+
+// SVG layout of a drawing in pixels.
+const (
+	svgMargin      = 20
+	svgLayerGap    = 180
+	svgBoxWidth    = 120
+	svgLineHeight  = 16
+	svgNeuronGap   = 32
+	svgNeuronSize  = 10
+	svgLabelHeight = 4 * svgLineHeight
+)
+
+/*
+WriteSVG draws a network as an SVG image by the same options as WriteDOT without Graphviz:
+layers are boxes of a row or columns of neurons. Only small networks are readable, neurons
+are drawn for networks of at most 64 neurons.
+*/
+func WriteSVG(w io.Writer, n Network, options GraphOptions) error {
+	g, err := graphOf(n, options)
+	if err != nil {
+		return err
+	}
+	width := 2*svgMargin + (len(g.layers)-1)*svgLayerGap + svgBoxWidth
+	height := 2*svgMargin + svgLabelHeight
+	if options.Neurons {
+		for i := range g.layers {
+			if h := 2*svgMargin + svgLabelHeight + g.neurons(i)*svgNeuronGap; h > height {
+				height = h
+			}
+		}
+	}
+
+	var svg bytes.Buffer
+	fmt.Fprintf(&svg, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="sans-serif" font-size="12">`+"\n", width, height)
+	fmt.Fprintf(&svg, `<rect width="%d" height="%d" fill="white"/>`+"\n", width, height)
+	if options.Neurons {
+		g.drawNeurons(&svg, height)
+	} else {
+		g.drawLayers(&svg)
+	}
+	fmt.Fprintf(&svg, "</svg>\n")
+	_, err = svg.WriteTo(w)
+	return err
+}
+
+// text of lines centered at x from y.
+func svgText(svg *bytes.Buffer, lines []string, x, y int) {
+	for i, line := range lines {
+		var escaped bytes.Buffer
+		xml.EscapeText(&escaped, []byte(line))
+		fmt.Fprintf(svg, `<text x="%d" y="%d" text-anchor="middle">%s</text>`+"\n", x, y+(i+1)*svgLineHeight, escaped.String())
+	}
+}
+
+func (g *graph) drawLayers(svg *bytes.Buffer) {
+	fmt.Fprintf(svg, `<defs><marker id="arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="8" markerHeight="8" orient="auto"><path d="M0,0 L10,5 L0,10 z"/></marker></defs>`+"\n")
+	for i := range g.layers {
+		x := svgMargin + i*svgLayerGap
+		lines := g.label(i)
+		fmt.Fprintf(svg, `<rect x="%d" y="%d" width="%d" height="%d" fill="none" stroke="black"/>`+"\n", x, svgMargin, svgBoxWidth, svgLabelHeight)
+		svgText(svg, lines, x+svgBoxWidth/2, svgMargin+(svgLabelHeight-len(lines)*svgLineHeight)/2-svgLineHeight/4)
+		if i < len(g.layers)-1 {
+			y := svgMargin + svgLabelHeight/2
+			fmt.Fprintf(svg, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="black" marker-end="url(#arrow)"/>`+"\n", x+svgBoxWidth, y, x+svgLayerGap, y)
+			svgText(svg, []string{fmt.Sprint(g.layers[i].Parameters())}, x+svgBoxWidth+(svgLayerGap-svgBoxWidth)/2, y-svgLineHeight-2)
+		}
+	}
+}
+
+func (g *graph) drawNeurons(svg *bytes.Buffer, height int) {
+	center := func(layer, neuron int) (x, y int) {
+		top := svgMargin + svgLabelHeight + (height-2*svgMargin-svgLabelHeight-g.neurons(layer)*svgNeuronGap)/2
+		return svgMargin + svgBoxWidth/2 + layer*svgLayerGap, top + neuron*svgNeuronGap + svgNeuronGap/2
+	}
+	for i, synapses := range g.synapses {
+		for from, row := range synapses {
+			for to := range row {
+				color, width := g.edge(i, from, to)
+				x1, y1 := center(i, from)
+				x2, y2 := center(i+1, to)
+				fmt.Fprintf(svg, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="%s" stroke-width="%.2f"/>`+"\n", x1, y1, x2, y2, color, width)
+			}
+		}
+	}
+	for i, l := range g.layers {
+		x, _ := center(i, 0)
+		svgText(svg, g.label(i), x, svgMargin-svgLineHeight/4)
+		for j := 0; j < g.neurons(i); j++ {
+			dash := ""
+			if l.Bias && j == g.neurons(i)-1 {
+				dash = ` stroke-dasharray="3,2"`
+			}
+			x, y := center(i, j)
+			fmt.Fprintf(svg, `<circle cx="%d" cy="%d" r="%d" fill="white" stroke="black"%s/>`+"\n", x, y, svgNeuronSize, dash)
+		}
+	}
+}
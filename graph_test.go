@@ -0,0 +1,118 @@
+package goDeep
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestWriteDOT(t *testing.T) {
+	tests := []struct {
+		name    string
+		options GraphOptions
+		want    []string
+		edges   int
+	}{
+		{
+			name: "layers",
+			want: []string{
+				`"hidden[0]" [label="hidden[0]\ndense, 3 with a bias\nsigmoid"];`,
+				`"input" -> "hidden[0]" [label="6"];`,
+				`"hidden[0]" -> "output" [label="3"];`,
+			},
+			edges: 2,
+		},
+		{
+			name:    "neurons",
+			options: GraphOptions{Neurons: true},
+			want: []string{
+				`"input/2" [style=dashed];`,
+				`"input/0" -> "hidden[0]/1" [color="#b2182b", penwidth=2.50, tooltip="-0.8"];`,
+				`"hidden[0]/1" -> "output/0" [color="#2166ac", penwidth=2.50, tooltip="0.9"];`,
+			},
+			edges: 9,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dot bytes.Buffer
+			if err := WriteDOT(&dot, prunedNetwork(t), tt.options); err != nil {
+				t.Fatalf("WriteDOT() error = %v", err)
+			}
+			got := dot.String()
+			if !strings.HasPrefix(got, "digraph network {") || !strings.HasSuffix(got, "}\n") {
+				t.Errorf("WriteDOT() = %q, want a digraph", got)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("WriteDOT() = %s, want it containing %s", got, want)
+				}
+			}
+			if edges := strings.Count(got, " -> "); edges != tt.edges {
+				t.Errorf("WriteDOT() edges = %d, want %d", edges, tt.edges)
+			}
+		})
+	}
+}
+
+func TestWriteSVG(t *testing.T) {
+	tests := []struct {
+		name    string
+		options GraphOptions
+		want    map[string]int
+	}{
+		{name: "layers", want: map[string]int{"rect": 4, "line": 2, "circle": 0}},
+		{name: "neurons", options: GraphOptions{Neurons: true}, want: map[string]int{"rect": 1, "line": 9, "circle": 7}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var svg bytes.Buffer
+			if err := WriteSVG(&svg, prunedNetwork(t), tt.options); err != nil {
+				t.Fatalf("WriteSVG() error = %v", err)
+			}
+			got := make(map[string]int)
+			decoder := xml.NewDecoder(&svg)
+			for {
+				token, err := decoder.Token()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("WriteSVG() is not XML: %v", err)
+				}
+				if start, ok := token.(xml.StartElement); ok {
+					got[start.Name.Local]++
+				}
+			}
+			for element, want := range tt.want {
+				if got[element] != want {
+					t.Errorf("WriteSVG() %s elements = %d, want %d", element, got[element], want)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteDOT_large(t *testing.T) {
+	n, err := NewFromConfig(ModelConfig{
+		Input:  &LayerConfig{Size: 40, LearningRate: .1},
+		Hidden: []LayerConfig{{Size: 30, LearningRate: .1, Activation: "relu"}},
+		Output: LayerConfig{Size: 2, Activation: "sigmoid", Cost: "quadratic"},
+	})
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	if err = WriteDOT(io.Discard, n, GraphOptions{}); err != nil {
+		t.Errorf("WriteDOT() of layers error = %v", err)
+	}
+	var configErr *ConfigError
+	if err = WriteDOT(io.Discard, n, GraphOptions{Neurons: true}); !errors.As(err, &configErr) {
+		t.Errorf("WriteDOT() of 72 neurons error = %v, want *ConfigError", err)
+	}
+	if err = WriteSVG(io.Discard, n, GraphOptions{Neurons: true}); !errors.As(err, &configErr) {
+		t.Errorf("WriteSVG() of 72 neurons error = %v, want *ConfigError", err)
+	}
+}
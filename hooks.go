@@ -0,0 +1,165 @@
+package goDeep
+
+import "fmt"
+
+/*
+ForwardEvent is activations of a layer of a sample: signals of an input layer, embedded
+ones of an embedding layer, activated neurons of a hidden layer, biases excluded, and
+a prediction of an output layer. Batch is a learned batch counted as steps of a schedule,
+or -1 by Recognize, Sample is an index of a sample in a set.
+*/
+type ForwardEvent struct {
+	Layer         string
+	Batch, Sample int
+	Activations   *Tensor
+}
+
+/*
+BackwardEvent is gradients of parameters of a layer summed over a batch before clipping and
+learning rates, by names of parameters: "input.synapses", "input.embeddings" or
+"hidden[0].synapses". Gradients of embeddings are rows of a vocabulary, rows not looked up
+by a batch are zero. Frozen parameters have no gradients.
+*/
+type BackwardEvent struct {
+	Layer     string
+	Batch     int
+	Gradients map[string]*Tensor
+}
+
+/*
+ForwardHook observes activations of a layer, modified activations are propagated forward.
+Hooks of an output layer observe predictions after a cost is measured. An error stops
+propagation.
+*/
+type ForwardHook func(ForwardEvent) error
+
+// BackwardHook observes gradients of a layer, modified gradients are applied. An error
+// stops learning, corrections of a batch are discarded.
+type BackwardHook func(BackwardEvent) error
+
+type hook struct {
+	id       int
+	layer    string
+	forward  ForwardHook
+	backward BackwardHook
+}
+
+// layerHooks registered on layers of a network. Methods of nil hooks do nothing.
+type layerHooks struct {
+	hooks  []hook
+	lastID int
+	// A batch and a sample of a current propagation.
+	batch, sample int
+}
+
+// add a hook and return a function removing it.
+func (h *layerHooks) add(registered hook) func() {
+	h.lastID++
+	registered.id = h.lastID
+	h.hooks = append(h.hooks, registered)
+	return func() {
+		for i, r := range h.hooks {
+			if r.id == registered.id {
+				h.hooks = append(h.hooks[:i:i], h.hooks[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// at a sample of a batch, a batch is -1 out of learning.
+func (h *layerHooks) at(batch, sample int) {
+	if h != nil {
+		h.batch, h.sample = batch, sample
+	}
+}
+
+func (h *layerHooks) forward(layer string, activations *Tensor) error {
+	if h == nil {
+		return nil
+	}
+	for _, r := range h.hooks {
+		if r.forward != nil && r.layer == layer {
+			if err := r.forward(ForwardEvent{Layer: layer, Batch: h.batch, Sample: h.sample, Activations: activations}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// backward hooks of gradients of parameters, gradients are collected only if there are hooks.
+func (h *layerHooks) backward(params []parameter) error {
+	if h == nil {
+		return nil
+	}
+	var gradients map[string]*Tensor
+	for _, r := range h.hooks {
+		if r.backward == nil {
+			continue
+		}
+		if gradients == nil {
+			gradients = make(map[string]*Tensor)
+			for _, p := range params {
+				if *p.frozen {
+					continue
+				}
+				if g := p.corrections(); g != nil {
+					gradients[p.name] = g
+				}
+			}
+		}
+		layer := make(map[string]*Tensor)
+		for name, g := range gradients {
+			if inGroup(name, r.layer) {
+				layer[name] = g
+			}
+		}
+		if err := r.backward(BackwardEvent{Layer: r.layer, Batch: h.batch, Gradients: layer}); err != nil {
+			return err
+		}
+	}
+	// Gradients collected into copies are set back modified.
+	for _, p := range params {
+		if g, ok := gradients[p.name]; ok && p.setCorrections != nil {
+			p.setCorrections(g)
+		}
+	}
+	return nil
+}
+
+/*
+AddForwardHook registers a hook of activations of a layer: "input", "hidden[0]" or "output".
+Hooks are called in an order of registration by learning and recognition, the returned
+function removes a hook.
+*/
+func AddForwardHook(n Network, layer string, forward ForwardHook) (remove func(), err error) {
+	h, err := hooksOf(n, layer)
+	if err != nil {
+		return nil, err
+	}
+	return h.add(hook{layer: layer, forward: forward}), nil
+}
+
+// AddBackwardHook registers a hook of gradients of parameters of a layer: "input" or
+// "hidden[0]". Hooks are called once a batch, the returned function removes a hook.
+func AddBackwardHook(n Network, layer string, backward BackwardHook) (remove func(), err error) {
+	if layer == "output" {
+		return nil, &ConfigError{Field: "layer", Value: layer, Reason: "an output layer has no synapses"}
+	}
+	h, err := hooksOf(n, layer)
+	if err != nil {
+		return nil, err
+	}
+	return h.add(hook{layer: layer, backward: backward}), nil
+}
+
+// hooksOf a network of a layer.
+func hooksOf(n Network, layer string) (*layerHooks, error) {
+	for _, name := range n.layerNames() {
+		if name == layer && n.layerHooks() != nil {
+			return n.layerHooks(), nil
+		}
+	}
+	return nil, &ConfigError{Field: "layer", Value: layer, Reason: fmt.Sprintf("is not a layer of %v", n.layerNames())}
+}
@@ -0,0 +1,183 @@
+package goDeep
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestAddForwardHook(t *testing.T) {
+	n := prunedNetwork(t)
+	var events []ForwardEvent
+	for _, layer := range []string{"output", "hidden[0]", "input"} {
+		if _, err := AddForwardHook(n, layer, func(e ForwardEvent) error {
+			events = append(events, e)
+			return nil
+		}); err != nil {
+			t.Fatalf("AddForwardHook(%q) error = %v", layer, err)
+		}
+	}
+	set := rowsOf([][]float64{{1, 0, 0}, {0, 1, 0}})
+	predictions, err := n.Recognize(set)
+	if err != nil {
+		t.Fatalf("Perceptron.Recognize() error = %v", err)
+	}
+
+	if len(events) != 6 {
+		t.Fatalf("forward hooks are called %d times, want 6", len(events))
+	}
+	for i, e := range events {
+		if want := []string{"input", "hidden[0]", "output"}[i%3]; e.Layer != want || e.Batch != -1 || e.Sample != i/3 {
+			t.Errorf("event %d = %s of batch %d sample %d, want %s of batch -1 sample %d", i, e.Layer, e.Batch, e.Sample, want, i/3)
+		}
+	}
+	if got, want := events[3].Activations.Data(), []float64{0, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("input activations = %v, want signals %v", got, want)
+	}
+	// Activated sums of the first sample are sigmoid(.1 + .01) and sigmoid(-.8 + .02).
+	if got := events[1].Activations.Data(); len(got) != 2 || math.Abs(got[0]-1/(1+math.Exp(-.11))) > 1e-12 {
+		t.Errorf("hidden activations = %v, want two activated neurons", got)
+	}
+	if got := events[5].Activations.Data(); !reflect.DeepEqual(got, predictions.Rows()[1]) {
+		t.Errorf("output activations = %v, want a prediction %v", got, predictions.Rows()[1])
+	}
+}
+
+func TestAddForwardHook_modify(t *testing.T) {
+	n := prunedNetwork(t)
+	calls := 0
+	remove, err := AddForwardHook(n, "hidden[0]", func(e ForwardEvent) error {
+		calls++
+		e.Activations.applyInPlace(func(float64) float64 { return 0 })
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("AddForwardHook() error = %v", err)
+	}
+	set := rowsOf([][]float64{{1, 0, 0}})
+	// Dead hidden neurons leave a bias synapse of .04 only.
+	if got, _ := n.Recognize(set); math.Abs(got.At(0, 0)-1/(1+math.Exp(-.04))) > 1e-12 {
+		t.Errorf("Recognize() of zero activations = %v, want sigmoid(.04)", got.At(0, 0))
+	}
+
+	remove()
+	if got, _ := n.Recognize(set); got.At(0, 0) == 1/(1+math.Exp(-.04)) || calls != 1 {
+		t.Errorf("Recognize() of a removed hook = %v after %d calls, want original activations", got.At(0, 0), calls)
+	}
+
+	hookErr := errors.New("saturated")
+	if _, err = AddForwardHook(n, "input", func(ForwardEvent) error { return hookErr }); err != nil {
+		t.Fatalf("AddForwardHook() error = %v", err)
+	}
+	if _, err = n.Recognize(set); !errors.Is(err, hookErr) {
+		t.Errorf("Recognize() error = %v, want a hook error", err)
+	}
+}
+
+func TestAddBackwardHook(t *testing.T) {
+	n := prunedNetwork(t)
+	set := rowsOf([][]float64{{0, 0, 0}, {0, 1, 0}, {1, 0, 0}, {1, 1, 0}})
+	labels := rowsOf([][]float64{{0}, {1}, {1}, {0}})
+	var batches []int
+	if _, err := AddBackwardHook(n, "hidden[0]", func(e BackwardEvent) error {
+		batches = append(batches, e.Batch)
+		g := e.Gradients["hidden[0].synapses"]
+		if len(e.Gradients) != 1 || !reflect.DeepEqual(g.Shape(), []int{3, 1}) {
+			t.Fatalf("hidden gradients = %v, want synapses of [3 1]", e.Gradients)
+		}
+		g.applyInPlace(func(float64) float64 { return 0 })
+		return nil
+	}); err != nil {
+		t.Fatalf("AddBackwardHook() error = %v", err)
+	}
+	var forwarded []int
+	if _, err := AddForwardHook(n, "output", func(e ForwardEvent) error {
+		forwarded = append(forwarded, e.Batch)
+		return nil
+	}); err != nil {
+		t.Fatalf("AddForwardHook() error = %v", err)
+	}
+	before := parametersOf(n)["hidden[0].synapses"].Rows()
+	if _, err := n.Learn(set, labels, 2, 2); err != nil {
+		t.Fatalf("Perceptron.Learn() error = %v", err)
+	}
+
+	if want := []int{0, 1, 2, 3}; !reflect.DeepEqual(batches, want) {
+		t.Errorf("backward hook batches = %v, want %v", batches, want)
+	}
+	if want := []int{0, 0, 1, 1, 2, 2, 3, 3}; !reflect.DeepEqual(forwarded, want) {
+		t.Errorf("forward hook batches = %v, want %v", forwarded, want)
+	}
+	if got := parametersOf(n)["hidden[0].synapses"].Rows(); !reflect.DeepEqual(got, before) {
+		t.Errorf("hidden synapses = %v after zero gradients, want %v", got, before)
+	}
+}
+
+func TestAddBackwardHook_embedding(t *testing.T) {
+	tests := []struct {
+		name   string
+		frozen bool
+		want   []string
+	}{
+		{name: "trainable", want: []string{"input.embeddings", "input.synapses"}},
+		{name: "frozen", frozen: true, want: []string{"input.synapses"}},
+	}
+	set := rowsOf([][]float64{{0, 1}, {2, 1}})
+	labels := rowsOf([][]float64{{1}, {0}})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := NewEmbeddingPerceptron(
+				EmbeddingShape{Vocabulary: 4, Dimension: 2, Fields: 2, LearningRate: .5, Frozen: tt.frozen},
+				[]HiddenShape{{Size: 3, LearningRate: .5, Bias: 1, Activation: new(Sigmoid)}},
+				OutputShape{Size: 1, Activation: new(Sigmoid), Cost: new(Quadratic)},
+			)
+			if err != nil {
+				t.Fatalf("NewEmbeddingPerceptron() error = %v", err)
+			}
+			var got []string
+			if _, err = AddBackwardHook(n, "input", func(e BackwardEvent) error {
+				got = got[:0]
+				for name, g := range e.Gradients {
+					got = append(got, name)
+					g.applyInPlace(func(float64) float64 { return 0 })
+				}
+				sort.Strings(got)
+				if g := e.Gradients["input.embeddings"]; g != nil && !reflect.DeepEqual(g.Shape(), []int{4, 2}) {
+					t.Errorf("embeddings gradients shape = %v, want [4 2]", g.Shape())
+				}
+				return nil
+			}); err != nil {
+				t.Fatalf("AddBackwardHook() error = %v", err)
+			}
+			before := parametersOf(n)
+			for name, value := range before {
+				before[name] = value.Clone()
+			}
+			if _, err = n.Learn(set, labels, 1, 2); err != nil {
+				t.Fatalf("Network.Learn() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("backward gradients of %v, want %v", got, tt.want)
+			}
+			for _, name := range []string{"input.embeddings", "input.synapses"} {
+				if after := parametersOf(n)[name]; !reflect.DeepEqual(after.Rows(), before[name].Rows()) {
+					t.Errorf("%s = %v after zero gradients, want %v", name, after.Rows(), before[name].Rows())
+				}
+			}
+		})
+	}
+}
+
+func TestAddHook_errors(t *testing.T) {
+	n := prunedNetwork(t)
+	var configErr *ConfigError
+	if _, err := AddForwardHook(n, "hidden[1]", func(ForwardEvent) error { return nil }); !errors.As(err, &configErr) {
+		t.Errorf("AddForwardHook() of an unknown layer error = %v, want *ConfigError", err)
+	}
+	if _, err := AddBackwardHook(n, "output", func(BackwardEvent) error { return nil }); !errors.As(err, &configErr) {
+		t.Errorf("AddBackwardHook() of an output layer error = %v, want *ConfigError", err)
+	}
+}
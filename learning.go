@@ -0,0 +1,181 @@
+package goDeep
+
+import (
+	"math"
+	"math/rand"
+)
+
+// History of a learning. Costs, learning rate factors and numbers of triggered gradient
+// clippings are recorded for every batch, validation costs are recorded for every epoch
+// when a validation set is given.
+type History struct {
+	Cost           []float64
+	ValidationCost []float64
+	LearningRates  []float64
+	Clipped        []int
+}
+
+func (h History) clone() History {
+	return History{
+		Cost:           append([]float64(nil), h.Cost...),
+		ValidationCost: append([]float64(nil), h.ValidationCost...),
+		LearningRates:  append([]float64(nil), h.LearningRates...),
+		Clipped:        append([]int(nil), h.Clipped...),
+	}
+}
+
+// LearnOption configures a Learn call.
+type LearnOption func(*learnConfig)
+
+type learnConfig struct {
+	scheduler                  Scheduler
+	unit                       ScheduleUnit
+	validationSet, validLabels *Tensor
+	clipping                   *Clipping
+	guard                      *numericGuard
+	shuffle                    bool
+	seed                       int64
+	checkpoints                *checkpointConfig
+	pruning                    *pruningConfig
+	multipliers                map[string]float64
+	resume                     *Checkpoint
+}
+
+func newLearnConfig(options []LearnOption) *learnConfig {
+	config := new(learnConfig)
+	for _, option := range options {
+		option(config)
+	}
+	return config
+}
+
+// WithScheduler adjusts learning rates of all the layers by a schedule every epoch or batch.
+func WithScheduler(s Scheduler, unit ScheduleUnit) LearnOption {
+	return func(c *learnConfig) {
+		c.scheduler, c.unit = s, unit
+	}
+}
+
+// WithValidation measures a mean cost of a validation set after every epoch.
+func WithValidation(set, labels *Tensor) LearnOption {
+	return func(c *learnConfig) {
+		c.validationSet, c.validLabels = set, labels
+	}
+}
+
+// WithClipping clips gradients of every batch before corrections are applied.
+func WithClipping(c Clipping) LearnOption {
+	return func(config *learnConfig) {
+		config.clipping = &c
+	}
+}
+
+// WithShuffle learns samples of every epoch in a random order. An order of an epoch
+// depends on a seed and an epoch only, so learning is reproducible and resumable.
+func WithShuffle(seed int64) LearnOption {
+	return func(c *learnConfig) {
+		c.shuffle, c.seed = true, seed
+	}
+}
+
+// order of samples of an epoch.
+func (c *learnConfig) order(epoch, samples int) []int {
+	if c.shuffle {
+		return rand.New(rand.NewSource(c.seed + int64(epoch))).Perm(samples)
+	}
+	order := make([]int, samples)
+	for i := range order {
+		order[i] = i
+	}
+	return order
+}
+
+// rate of a schedule for a step, full rate without a schedule.
+func (c *learnConfig) rate(step int) float64 {
+	if c.scheduler == nil {
+		return 1
+	}
+	return c.scheduler.Rate(step)
+}
+
+// observe a validation cost of an epoch by a schedule driven by it.
+func (c *learnConfig) observe(validationCost float64) {
+	if o, ok := c.scheduler.(validationObserver); ok {
+		o.observe(validationCost)
+	}
+}
+
+// Evaluation of a network on a labeled set.
+type Evaluation struct {
+	Cost, Accuracy float64
+}
+
+/*
+Evaluate measures a mean cost and an accuracy of a network on a set. A prediction is
+correct if its largest output is the largest label, a single output is compared
+rounded.
+*/
+func Evaluate(n Network, set, labels *Tensor) (e Evaluation, err error) {
+	samples := rowsNumber(set)
+	if samples == 0 {
+		return
+	}
+	var correct int
+	for i := 0; i < samples; i++ {
+		sample, err := set.Index(i)
+		if err != nil {
+			return e, err
+		}
+		label, err := labels.Index(i)
+		if err != nil {
+			return e, err
+		}
+		n.layerHooks().at(-1, i)
+		prediction, cost, err := n.forwardMeasure(sample, label)
+		if err != nil {
+			return e, err
+		}
+		e.Cost += cost
+		if correctPrediction(prediction, label) {
+			correct++
+		}
+	}
+	e.Cost /= float64(samples)
+	e.Accuracy = float64(correct) / float64(samples)
+	return
+}
+
+// correctPrediction has the largest output at the largest label, a single output is
+// compared rounded.
+func correctPrediction(prediction, label *Tensor) bool {
+	if label.Size() == 1 {
+		return math.Round(prediction.Data()[0]) == label.Data()[0]
+	}
+	return prediction.ArgMax() == label.ArgMax()
+}
+
+// meanCost of a set measured without learning.
+func meanCost(n backwardPropagation, set, labels *Tensor) (float64, error) {
+	var sum float64
+	samples := rowsNumber(set)
+	for i := 0; i < samples; i++ {
+		sample, err := set.Index(i)
+		if err != nil {
+			return 0, err
+		}
+		label, err := labels.Index(i)
+		if err != nil {
+			return 0, err
+		}
+		n.layerHooks().at(-1, i)
+		_, cost, err := n.forwardMeasure(sample, label)
+		if err != nil {
+			return 0, err
+		}
+		sum += cost
+	}
+	if samples == 0 {
+		return 0, nil
+	}
+	return sum / float64(samples), nil
+}
@@ -0,0 +1,293 @@
+package goDeep
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+/*
+LayerConfig is a declaration of a dense layer. Activation, Cost and Initializer are names,
+an input layer has no activation, only an output layer has a cost and an initializer of
+synapses to a next layer is of input and hidden layers.
+*/
+type LayerConfig struct {
+	Size         int     `json:"size"`
+	LearningRate float64 `json:"learningRate,omitempty"`
+	Bias         float64 `json:"bias,omitempty"`
+	Activation   string  `json:"activation,omitempty"`
+	Cost         string  `json:"cost,omitempty"`
+	Initializer  string  `json:"initializer,omitempty"`
+	// Frozen synapses are not learned, see SetTrainable.
+	Frozen bool `json:"frozen,omitempty"`
+}
+
+// EmbeddingConfig is a declaration of an embedding input layer.
+type EmbeddingConfig struct {
+	Vocabulary   int     `json:"vocabulary"`
+	Dimension    int     `json:"dimension"`
+	Fields       int     `json:"fields"`
+	LearningRate float64 `json:"learningRate"`
+	Bias         float64 `json:"bias,omitempty"`
+	Frozen       bool    `json:"frozen,omitempty"`
+	Initializer  string  `json:"initializer,omitempty"`
+	// FrozenSynapses of an embedding to hidden neurons are not learned.
+	FrozenSynapses bool `json:"frozenSynapses,omitempty"`
+}
+
+/*
+ModelConfig is a declaration of a network, e.g. decoded from JSON:
+
+	{
+		"input": {"size": 3, "learningRate": 0.1, "bias": 1},
+		"hidden": [{"size": 4, "learningRate": 0.1, "bias": 1, "activation": "sigmoid"}],
+		"output": {"size": 1, "activation": "sigmoid", "cost": "quadratic"},
+		"seed": 7,
+		"precision": "float32",
+		"training": {"epochs": 100, "batchSize": 10, "schedule": {"name": "exponential", "gamma": 0.99}}
+	}
+
+A network has either a dense or an embedding input. Seed makes initial weights reproducible,
+parameters are of Precision, float64 by default.
+Training is not a part of a network, so Perceptron.Config and saved models have neither
+training nor initializers and a seed.
+
+Configurations are JSON or YAML, read by ReadModelConfig and ReadModelConfigYAML: the package
+depends on a standard library only.
+*/
+type ModelConfig struct {
+	Input     *LayerConfig     `json:"input,omitempty"`
+	Embedding *EmbeddingConfig `json:"embedding,omitempty"`
+	Hidden    []LayerConfig    `json:"hidden"`
+	Output    LayerConfig      `json:"output"`
+	Seed      int64            `json:"seed,omitempty"`
+	Precision Precision        `json:"precision,omitempty"`
+	Training  *TrainingConfig  `json:"training,omitempty"`
+}
+
+// NewFromConfig is a network initializer from a declaration.
+func NewFromConfig(c ModelConfig) (Network, error) {
+	if (c.Input == nil) == (c.Embedding == nil) {
+		return nil, &ConfigError{Field: "ModelConfig.Input", Value: c.Input, Reason: "either a dense or an embedding input is required"}
+	}
+	if err := c.Precision.validate("ModelConfig.Precision"); err != nil {
+		return nil, err
+	}
+
+	hiddenShapes := make([]HiddenShape, len(c.Hidden))
+	for i, l := range c.Hidden {
+		act, err := activationByName(fmt.Sprintf("Hidden[%d].Activation", i), l.Activation)
+		if err != nil {
+			return nil, err
+		}
+		hiddenShapes[i] = HiddenShape{Size: l.Size, LearningRate: l.LearningRate, Bias: l.Bias, Activation: act}
+	}
+
+	act, err := activationByName("Output.Activation", c.Output.Activation)
+	if err != nil {
+		return nil, err
+	}
+	if c.Output.Initializer != "" {
+		return nil, &ConfigError{Field: "Output.Initializer", Value: c.Output.Initializer, Reason: "an output layer has no synapses"}
+	}
+	if c.Output.Frozen {
+		return nil, &ConfigError{Field: "Output.Frozen", Value: c.Output.Frozen, Reason: "an output layer has no synapses"}
+	}
+	newCost, ok := costs[c.Output.Cost]
+	if !ok {
+		return nil, &ConfigError{Field: "Output.Cost", Value: c.Output.Cost, Reason: "unknown cost"}
+	}
+	outputShape := OutputShape{Size: c.Output.Size, Activation: act, Cost: newCost()}
+
+	// Default initial weights are drawn from a source of a seed as well as initializers.
+	r := newRandom(c.Seed)
+	var n *Perceptron
+	if c.Embedding != nil {
+		e := c.Embedding
+		n, err = newEmbeddingPerceptron(
+			EmbeddingShape{
+				Vocabulary:   e.Vocabulary,
+				Dimension:    e.Dimension,
+				Fields:       e.Fields,
+				LearningRate: e.LearningRate,
+				Bias:         e.Bias,
+				Frozen:       e.Frozen,
+			},
+			hiddenShapes,
+			outputShape,
+			r,
+		)
+	} else {
+		n, err = newDensePerceptron(
+			InputShape{Size: c.Input.Size, LearningRate: c.Input.LearningRate, Bias: c.Input.Bias},
+			hiddenShapes,
+			outputShape,
+			r,
+		)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = initialize(n, c, r); err != nil {
+		return nil, err
+	}
+	if c.Precision == Float32 {
+		convertParameters(n.parameters(), Float32)
+	}
+	if err = SetTrainable(n, false, frozenGroups(c)...); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// frozenGroups of parameters declared frozen.
+func frozenGroups(c ModelConfig) (groups []string) {
+	if c.Input != nil && c.Input.Frozen || c.Embedding != nil && c.Embedding.FrozenSynapses {
+		groups = append(groups, "input.synapses")
+	}
+	for i, l := range c.Hidden {
+		if l.Frozen {
+			groups = append(groups, fmt.Sprintf("hidden[%d].synapses", i))
+		}
+	}
+	return
+}
+
+// denseInputOnly reports a ConfigError of an operation of networks of dense inputs.
+func denseInputOnly(op string, c ModelConfig) error {
+	if c.Input == nil {
+		return &ConfigError{Field: "ModelConfig.Input", Value: c.Input, Reason: op + " supports only networks of dense inputs"}
+	}
+	return nil
+}
+
+func activationByName(field, name string) (activation, error) {
+	newActivation, ok := activations[name]
+	if !ok {
+		return nil, &ConfigError{Field: field, Value: name, Reason: "unknown activation"}
+	}
+	return newActivation(), nil
+}
+
+func activationName(field string, a activation) (string, error) {
+	for name, newActivation := range activations {
+		if reflect.TypeOf(newActivation()) == reflect.TypeOf(a) {
+			return name, nil
+		}
+	}
+	return "", &ConfigError{Field: field, Value: reflect.TypeOf(a), Reason: "is not registered by a name"}
+}
+
+func costName(field string, c cost) (string, error) {
+	for name, newCost := range costs {
+		if reflect.TypeOf(newCost()) == reflect.TypeOf(c) {
+			return name, nil
+		}
+	}
+	return "", &ConfigError{Field: field, Value: reflect.TypeOf(c), Reason: "is not registered by a name"}
+}
+
+// Config is a declaration of a network. Activations and costs must be known by names.
+func (n *Perceptron) Config() (c ModelConfig, err error) {
+	s := n.shapes
+	switch {
+	case s.input != nil:
+		c.Input = &LayerConfig{Size: s.input.Size, LearningRate: s.input.LearningRate, Bias: s.input.Bias}
+	case s.embedding != nil:
+		c.Embedding = &EmbeddingConfig{
+			Vocabulary:   s.embedding.Vocabulary,
+			Dimension:    s.embedding.Dimension,
+			Fields:       s.embedding.Fields,
+			LearningRate: s.embedding.LearningRate,
+			Bias:         s.embedding.Bias,
+			Frozen:       s.embedding.Frozen,
+		}
+	default:
+		return c, &ConfigError{Field: "Perceptron", Value: nil, Reason: "a network is not declared by shapes"}
+	}
+
+	for i, h := range s.hidden {
+		l := LayerConfig{Size: h.Size, LearningRate: h.LearningRate, Bias: h.Bias}
+		if l.Activation, err = activationName(fmt.Sprintf("Hidden[%d].Activation", i), h.Activation); err != nil {
+			return
+		}
+		c.Hidden = append(c.Hidden, l)
+	}
+
+	params := n.parameters()
+	if len(params) > 0 && params[0].value.Precision() == Float32 {
+		c.Precision = Float32
+	}
+	if c.Input != nil {
+		c.Input.Frozen = frozen(params, "input.synapses")
+	} else {
+		c.Embedding.Frozen = frozen(params, "input.embeddings")
+		c.Embedding.FrozenSynapses = frozen(params, "input.synapses")
+	}
+	for i := range c.Hidden {
+		c.Hidden[i].Frozen = frozen(params, fmt.Sprintf("hidden[%d].synapses", i))
+	}
+
+	c.Output.Size = s.output.Size
+	if c.Output.Activation, err = activationName("Output.Activation", s.output.Activation); err != nil {
+		return
+	}
+	c.Output.Cost, err = costName("Output.Cost", s.output.Cost)
+	return
+}
+
+// savedModel is a declaration and weights of a network.
+type savedModel struct {
+	Config     ModelConfig
+	Parameters map[string]*Tensor
+}
+
+// SaveModel writes a declaration and weights of a network.
+func SaveModel(w io.Writer, n Network) error {
+	config, err := n.Config()
+	if err != nil {
+		return err
+	}
+	return gob.NewEncoder(w).Encode(savedModel{Config: config, Parameters: parametersOf(n)})
+}
+
+// LoadModel reads a network written by SaveModel.
+func LoadModel(r io.Reader) (Network, error) {
+	var saved savedModel
+	if err := gob.NewDecoder(r).Decode(&saved); err != nil {
+		return nil, err
+	}
+	n, err := NewFromConfig(saved.Config)
+	if err != nil {
+		return nil, err
+	}
+	if err = loadParameters(n.parameters(), saved.Parameters); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// parametersOf a network by names.
+func parametersOf(n Network) map[string]*Tensor {
+	params := make(map[string]*Tensor)
+	for _, p := range n.parameters() {
+		params[p.name] = p.value
+	}
+	return params
+}
+
+// loadParameters copies saved weights to parameters of the same names, converting them
+// to precisions of parameters.
+func loadParameters(params []parameter, saved map[string]*Tensor) error {
+	for _, p := range params {
+		value, ok := saved[p.name]
+		if !ok {
+			return &ConfigError{Field: "Parameters", Value: p.name, Reason: "is missing"}
+		}
+		if err := p.value.copyFrom(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
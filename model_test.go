@@ -0,0 +1,90 @@
+package goDeep
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestNewFromConfig(t *testing.T) {
+	hidden := []LayerConfig{{Size: 3, LearningRate: .1, Bias: 1, Activation: "sigmoid"}}
+	output := LayerConfig{Size: 2, Activation: "sigmoid", Cost: "quadratic"}
+	tests := []struct {
+		name      string
+		config    ModelConfig
+		wantField string
+	}{
+		{name: "dense", config: ModelConfig{Input: &LayerConfig{Size: 3, LearningRate: .1, Bias: 1}, Hidden: hidden, Output: output}},
+		{name: "embedding", config: ModelConfig{Embedding: &EmbeddingConfig{Vocabulary: 5, Dimension: 2, Fields: 2, LearningRate: .1}, Hidden: hidden, Output: output}},
+		{name: "noInput", config: ModelConfig{Hidden: hidden, Output: output}, wantField: "ModelConfig.Input"},
+		{
+			name:      "unknownActivation",
+			config:    ModelConfig{Input: &LayerConfig{Size: 3, LearningRate: .1}, Hidden: []LayerConfig{{Size: 3, LearningRate: .1, Activation: "step"}}, Output: output},
+			wantField: "Hidden[0].Activation",
+		},
+		{
+			name:      "unknownCost",
+			config:    ModelConfig{Input: &LayerConfig{Size: 3, LearningRate: .1}, Hidden: hidden, Output: LayerConfig{Size: 2, Activation: "sigmoid"}},
+			wantField: "Output.Cost",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := NewFromConfig(tt.config)
+			if tt.wantField != "" {
+				var configErr *ConfigError
+				if !errors.As(err, &configErr) || configErr.Field != tt.wantField {
+					t.Fatalf("NewFromConfig() error = %v, want a ConfigError of %s", err, tt.wantField)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewFromConfig() error = %v", err)
+			}
+			got, err := n.Config()
+			if err != nil {
+				t.Fatalf("Perceptron.Config() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.config) {
+				t.Errorf("Perceptron.Config() = %+v, want %+v", got, tt.config)
+			}
+		})
+	}
+}
+
+func TestSaveModel(t *testing.T) {
+	n, err := NewPerceptron(
+		InputShape{Size: 3, LearningRate: .1, Bias: 1},
+		[]HiddenShape{{Size: 4, LearningRate: .1, Bias: 1, Activation: new(Sigmoid)}},
+		OutputShape{Size: 2, Activation: new(Sigmoid), Cost: new(Quadratic)},
+	)
+	if err != nil {
+		t.Fatalf("NewPerceptron() error = %v", err)
+	}
+	var buf bytes.Buffer
+	if err = SaveModel(&buf, n); err != nil {
+		t.Fatalf("SaveModel() error = %v", err)
+	}
+	loaded, err := LoadModel(&buf)
+	if err != nil {
+		t.Fatalf("LoadModel() error = %v", err)
+	}
+
+	set := rowsOf([][]float64{{0, 1, 0}, {1, 1, 0}})
+	want, err := n.Recognize(set)
+	if err != nil {
+		t.Fatalf("Perceptron.Recognize() error = %v", err)
+	}
+	got, err := loaded.Recognize(set)
+	if err != nil {
+		t.Fatalf("Perceptron.Recognize() of a loaded model error = %v", err)
+	}
+	if !reflect.DeepEqual(got.Rows(), want.Rows()) {
+		t.Errorf("loaded Perceptron.Recognize() = %v, want %v", got.Rows(), want.Rows())
+	}
+
+	if err = SaveModel(&buf, &Perceptron{}); err == nil {
+		t.Errorf("SaveModel() of an undeclared network error = nil, want error")
+	}
+}
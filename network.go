@@ -1,19 +1,28 @@
 package goDeep
 
+import "context"
+
 type backwardPropagation interface {
-	forward(set []float64) (output []float64, err error)
-	forwardMeasure(set, labels []float64) (prediction []float64, cost float64, err error)
-	backward(prediction, labels []float64) error
+	forward(set *Tensor) (output *Tensor, err error)
+	forwardMeasure(set, labels *Tensor) (prediction *Tensor, cost float64, err error)
+	backward(prediction, labels *Tensor) error
 	applyCorrections(float64) error
+	discardCorrections()
+	parameters() []parameter
+	layerHooks() *layerHooks
 }
 
 /*
 Network is a public interface for actual library usage.
 
 Network interface defines abstract neural network with back propagation.
+Data sets, labels and predictions are two dimensional tensors, a sample per row.
 */
 type Network interface {
 	backwardPropagation
-	Learn(set, labels [][]float64, epochs int, batchSize int) ([]float64, error)
-	Recognize([][]float64) ([][]float64, error)
+	Learn(set, labels *Tensor, epochs int, batchSize int, options ...LearnOption) (History, error)
+	LearnContext(ctx context.Context, set, labels *Tensor, epochs int, batchSize int, options ...LearnOption) (History, error)
+	Recognize(*Tensor) (*Tensor, error)
+	Config() (ModelConfig, error)
+	layerNames() []string
 }
@@ -1,136 +1,150 @@
 package goDeep
 
+import "math/rand"
+
 type inputLayer interface {
 	synapseInitializer
-	forward([]float64) ([][]float64, error)
-	backward([]float64) error
+	forward(*Tensor) (*Tensor, error)
+	backward(*Tensor) error
 	applyCorrections(float64) error
+	discardCorrections()
+	parameters() []parameter
+	rate() float64
+	setRate(float64)
+	sampleSize() int
+	setHook(func(signals *Tensor) error)
 }
 
 type hiddenLayer interface {
 	activation
 	synapseInitializer
-	forward([][]float64) ([][]float64, error)
-	backward([]float64) ([]float64, error)
+	forward(*Tensor) (*Tensor, error)
+	backward(*Tensor) (*Tensor, error)
 	applyCorrections(float64) error
+	discardCorrections()
+	parameters() []parameter
+	rate() float64
+	setRate(float64)
+	setHook(func(activated *Tensor) error)
 }
 
 type outputLayer interface {
 	activation
 	cost
-	forwardMeasure([][]float64, []float64) ([]float64, float64, error)
-	forward(rowInput [][]float64) ([]float64, error)
-	backward(prediction, labels []float64) ([]float64, error)
+	forwardMeasure(*Tensor, *Tensor) (*Tensor, float64, error)
+	forward(rowInput *Tensor) (*Tensor, error)
+	backward(prediction, labels *Tensor) (*Tensor, error)
+	labelSize() int
 }
 
 type inputDense struct {
 	synapseInitializer
-	corrections, synapses        [][]float64
+	corrections, synapses        *Tensor
+	mask                         *Tensor // Synapses of zero mask are pruned
+	frozen                       bool    // Frozen synapses are not corrected
+	hook                         func(signals *Tensor) error
 	nextLayerSize, currLayerSize int
 	learningRate                 float64
-	input                        []float64
+	input                        *Tensor
 	bias                         bool
 	nextBias                     bool
+	// Graph of the last forward pass
+	weights, signal, weighted *variable
 }
 
-func (l *inputDense) forward(input []float64) (output [][]float64, err error) {
-	if err = areSizesConsistent(len(input), l.currLayerSize, len(l.synapses), false); err != nil {
-		lockErr := err.(locatedError)
-		err = lockErr.freeze()
+func (l *inputDense) forward(input *Tensor) (output *Tensor, err error) {
+	if err = areSizesConsistent(input.Size(), l.currLayerSize, rowsNumber(l.synapses), false); err != nil {
 		return
 	}
 
 	l.input = input
 
 	// Exclude bias synapse
-	nextLayerSize := l.nextLayerSize
-	if l.nextBias {
-		nextLayerSize--
-	}
 	currLayerSize := l.currLayerSize
 	if l.bias {
 		currLayerSize--
 	}
-	output = make([][]float64, nextLayerSize)
-	for i := 0; i < nextLayerSize; i++ {
-		for j := 0; j < currLayerSize; j++ {
-			output[i] = append(output[i], l.synapses[j][i]*input[j])
-		}
-		if l.bias {
-			output[i] = append(output[i], l.synapses[currLayerSize][i])
+	signal := input.Data()[:currLayerSize]
+	if l.hook != nil {
+		signals := Vector(signal)
+		if err = l.hook(signals); err != nil {
+			return
 		}
+		signal = signals.Data()
 	}
-	return
-}
-
-func (l *inputDense) backward(eRRors []float64) (err error) {
-	// Exclude bias synapse
-	nextLayerSize := l.nextLayerSize
-	if l.nextBias {
-		nextLayerSize--
-	}
-	currLayerSize := l.currLayerSize
 	if l.bias {
-		currLayerSize--
+		// Bias has no input, its signal is constant.
+		signal = append(signal, 1)
 	}
 
-	if err = checkInputSize(len(eRRors), nextLayerSize); err != nil {
-		if err = checkInputSize(len(l.input), currLayerSize); err != nil {
-			lockErr := err.(locatedError)
-			err = lockErr.freeze()
-			return
-		}
+	l.signal = newVariable(Vector(signal))
+	l.weights = newVariable(l.synapses)
+	if l.weighted, err = weigh(l.weights, l.signal); err != nil {
+		return
 	}
+	return l.weighted.value, nil
+}
 
-	if l.corrections == nil {
-		l.corrections = make([][]float64, l.currLayerSize)
+func (l *inputDense) backward(eRRors *Tensor) (err error) {
+	if err = checkForwarded(l.weighted); err != nil {
+		lockErr := err.(locatedError)
+		err = lockErr.freeze()
+		return
 	}
-
-	for i := 0; i < nextLayerSize; i++ {
-		for j := 0; j < currLayerSize; j++ {
-			if l.corrections[j] == nil {
-				l.corrections[j] = make([]float64, nextLayerSize)
-			}
-			// Input layer doesn't use activation so to obtain correction we need to
-			// use input as it is.
-			l.corrections[j][i] += eRRors[i] * l.input[j]
-		}
-		if l.bias {
-			if l.corrections[currLayerSize] == nil {
-				l.corrections[currLayerSize] = make([]float64, nextLayerSize)
-			}
-			l.corrections[currLayerSize][i] += eRRors[i]
-		}
+	if err = checkInputSize(eRRors.Size(), rowsNumber(l.weighted.value)); err != nil {
+		return
+	}
+	if err = backwardWeighted(l.weighted, eRRors); err != nil || l.frozen {
+		return
 	}
+	l.corrections, err = accumulate(l.corrections, l.weights.grad, l.synapses.Precision())
 	return
 }
 
 func (l *inputDense) applyCorrections(batchSize float64) (err error) {
-	if err = areCorrsConsistent(len(l.corrections), l.currLayerSize, len(l.synapses)); err != nil {
-		lockErr := err.(locatedError)
-		err = lockErr.freeze()
+	if l.frozen {
+		l.corrections = nil
 		return
 	}
-
 	nextLayerSize := l.nextLayerSize
 	if l.nextBias {
 		nextLayerSize--
 	}
 
-	for i := 0; i < l.currLayerSize; i++ {
-		if err = areCorrsConsistent(len(l.corrections[i]), nextLayerSize, len(l.synapses[i])); err != nil {
-			return
-		}
-		for j := 0; j < nextLayerSize; j++ {
-			l.synapses[i][j] -= l.learningRate * l.corrections[i][j] / batchSize
-		}
+	if err = applyDenseCorrections(l.synapses, l.corrections, l.mask, l.currLayerSize, nextLayerSize, l.learningRate, batchSize); err != nil {
+		return
 	}
 	l.corrections = nil
 
 	return
 }
 
-func newInputDense(curr, next int, learningRate, bias float64, nextBias bool) inputLayer {
+func (l *inputDense) discardCorrections() {
+	l.corrections = nil
+}
+
+func (l *inputDense) parameters() []parameter {
+	return []parameter{denseParameter("synapses", l.synapses, &l.corrections, &l.mask, &l.frozen)}
+}
+
+func (l *inputDense) rate() float64 {
+	return l.learningRate
+}
+
+func (l *inputDense) setRate(learningRate float64) {
+	l.learningRate = learningRate
+}
+
+func (l *inputDense) setHook(hook func(signals *Tensor) error) {
+	l.hook = hook
+}
+
+// sampleSize of input data, a sample has a slot of a bias.
+func (l *inputDense) sampleSize() int {
+	return l.currLayerSize
+}
+
+func newInputDense(curr, next int, learningRate, bias float64, nextBias bool, r *rand.Rand) inputLayer {
 	layer := &inputDense{
 		synapseInitializer: &denseSynapses{
 			prev:     1,
@@ -138,6 +152,7 @@ func newInputDense(curr, next int, learningRate, bias float64, nextBias bool) in
 			next:     next,
 			bias:     bias,
 			nextBias: nextBias,
+			random:   r,
 		},
 		currLayerSize: curr,
 		nextLayerSize: next,
@@ -154,152 +169,119 @@ type hiddenDense struct {
 	synapseInitializer
 	prevLayerSize, currLayerSize, nextLayerSize int
 	learningRate                                float64
-	corrections, synapses                       [][]float64
-	activated, input                            []float64
+	corrections, synapses                       *Tensor
+	mask                                        *Tensor // Synapses of zero mask are pruned
+	frozen                                      bool    // Frozen synapses are not corrected
+	hook                                        func(activated *Tensor) error
+	activated, input                            *Tensor
 	nextBias, bias                              bool // Indicate do biases on a current layer and a next one exist
+	// Graph of the last forward pass
+	weights, summed, weighted *variable
 }
 
-func (l *hiddenDense) forward(input [][]float64) (output [][]float64, err error) {
+func (l *hiddenDense) forward(input *Tensor) (output *Tensor, err error) {
 	// Input lesser than a layer size because bias has no input.
-	if err = areSizesConsistent(len(input), l.currLayerSize, len(l.synapses), true); err != nil {
-		lockErr := err.(locatedError)
-		err = lockErr.freeze()
+	if err = areSizesConsistent(rowsNumber(input), l.currLayerSize, rowsNumber(l.synapses), true); err != nil {
 		return
 	}
 
-	nextLayerSize := l.nextLayerSize
-	if l.nextBias {
-		nextLayerSize--
-	}
-	currLayerSize := l.currLayerSize
-	if l.bias {
-		currLayerSize--
-	}
-
-	var inputSum, actValue float64
-	output = make([][]float64, l.nextLayerSize)
+	var activated, signal *variable
 
 	l.activated = nil
 	l.input = nil
-	for i := 0; i < currLayerSize; i++ {
-		inputSum = 0
-		for _, j := range input[i] {
-			inputSum += j
-		}
-
-		l.input = append(l.input, inputSum)
-		actValue, err = l.activate(inputSum)
-		if err != nil {
-			return
-		}
-
-		l.activated = append(l.activated, actValue)
+	if l.summed, err = apply(sumAxisOp{1}, newVariable(input)); err != nil {
+		return
 	}
-
-	for i := 0; i < nextLayerSize; i++ {
-		for j := 0; j < currLayerSize; j++ {
-			output[i] = append(output[i], l.synapses[j][i]*l.activated[j])
-		}
-		if l.bias {
-			output[i] = append(output[i], l.synapses[currLayerSize][i])
+	if activated, err = apply(activationOp(l.activation), l.summed); err != nil {
+		return
+	}
+	l.input = l.summed.value
+	l.activated = activated.value
+	if l.hook != nil {
+		if err = l.hook(l.activated); err != nil {
+			return
 		}
 	}
-	return
-}
 
-func (l *hiddenDense) updateCorrections(eRRors []float64) [][]float64 {
-	currLayerSize := l.currLayerSize
+	signal = activated
 	if l.bias {
-		currLayerSize--
-	}
-	nextLayerSize := l.nextLayerSize
-	if l.nextBias {
-		nextLayerSize--
-	}
-
-	// Collect corrections for further forward error propagation
-	if l.corrections == nil {
-		l.corrections = make([][]float64, l.currLayerSize)
+		// Bias has no input, its signal is constant.
+		if signal, err = apply(concatOp{}, activated, newVariable(Vector([]float64{1}))); err != nil {
+			return
+		}
 	}
 
-	for i := 0; i < nextLayerSize; i++ {
-		for j := 0; j < currLayerSize; j++ {
-			if l.corrections[j] == nil {
-				l.corrections[j] = make([]float64, l.nextLayerSize)
-			}
-			l.corrections[j][i] += l.activated[j] * eRRors[i]
-		}
-		// Apply bias error signal
-		if l.corrections[currLayerSize] == nil {
-			l.corrections[currLayerSize] = make([]float64, nextLayerSize)
-		}
-		l.corrections[currLayerSize][i] += eRRors[i]
+	l.weights = newVariable(l.synapses)
+	if l.weighted, err = weigh(l.weights, signal); err != nil {
+		return
 	}
-	return l.corrections
+	return l.weighted.value, nil
 }
 
-func (l *hiddenDense) backward(eRRors []float64) (prevLayerErrors []float64, err error) {
+func (l *hiddenDense) backward(eRRors *Tensor) (prevLayerErrors *Tensor, err error) {
 	// Propagate backward from hidden to a previous hidden or input layer
 	// Single error signal per neuron.
-	l.corrections = l.updateCorrections(eRRors)
-
-	nextLayerSize := l.nextLayerSize
-	if l.nextBias {
-		nextLayerSize--
+	if err = checkForwarded(l.weighted); err != nil {
+		lockErr := err.(locatedError)
+		err = lockErr.freeze()
+		return
 	}
-	currLayerSize := l.currLayerSize
-	if l.bias {
-		currLayerSize--
+	if err = checkInputSize(eRRors.Size(), rowsNumber(l.weighted.value)); err != nil {
+		return
 	}
-
-	// Bias is not connected with previous layer so exclude the last synapse
-	// From both previous layer errors vector and a current one.
-	var eRRSum, actDer float64
-	for i := 0; i < currLayerSize; i++ {
-
-		actDer, err = l.actDerivative(l.input[i])
-		if err != nil {
+	if err = backwardWeighted(l.weighted, eRRors); err != nil {
+		return
+	}
+	if !l.frozen {
+		if l.corrections, err = accumulate(l.corrections, l.weights.grad, l.synapses.Precision()); err != nil {
 			return
 		}
-
-		eRRSum = 0
-		for j := 0; j < nextLayerSize; j++ {
-			eRRSum += l.synapses[i][j] * eRRors[j]
-		}
-		prevLayerErrors = append(prevLayerErrors, actDer*eRRSum)
 	}
-	return
+
+	// Bias is not connected with previous layer, so previous layer errors
+	// are gradients of a current layer input sums.
+	return l.summed.grad, nil
 }
 
-// FIXME: refactor this crap. Should be implemented in a single place.
 func (l *hiddenDense) applyCorrections(batchSize float64) (err error) {
-	if err = areCorrsConsistent(len(l.corrections), l.currLayerSize, len(l.synapses)); err != nil {
-		lockErr := err.(locatedError)
-		err = lockErr.freeze()
+	if l.frozen {
+		l.corrections = nil
 		return
 	}
-
 	nextLayerSize := l.nextLayerSize
 	if l.nextBias {
 		nextLayerSize--
 	}
 
-	for i := 0; i < l.currLayerSize; i++ {
-		if err = areCorrsConsistent(len(l.corrections[i]), nextLayerSize, len(l.synapses[i])); err != nil {
-			lockErr := err.(locatedError)
-			err = lockErr.freeze()
-			return
-		}
-		for j := 0; j < nextLayerSize; j++ {
-			l.synapses[i][j] -= l.learningRate * l.corrections[i][j] / batchSize
-		}
+	if err = applyDenseCorrections(l.synapses, l.corrections, l.mask, l.currLayerSize, nextLayerSize, l.learningRate, batchSize); err != nil {
+		return
 	}
 	l.corrections = nil
 
 	return
 }
 
-func newHiddenDense(prev, curr, next int, bias, learningRate float64, activation activation, nextBias bool) hiddenLayer {
+func (l *hiddenDense) discardCorrections() {
+	l.corrections = nil
+}
+
+func (l *hiddenDense) parameters() []parameter {
+	return []parameter{denseParameter("synapses", l.synapses, &l.corrections, &l.mask, &l.frozen)}
+}
+
+func (l *hiddenDense) rate() float64 {
+	return l.learningRate
+}
+
+func (l *hiddenDense) setRate(learningRate float64) {
+	l.learningRate = learningRate
+}
+
+func (l *hiddenDense) setHook(hook func(activated *Tensor) error) {
+	l.hook = hook
+}
+
+func newHiddenDense(prev, curr, next int, bias, learningRate float64, activation activation, nextBias bool, r *rand.Rand) hiddenLayer {
 	layer := &hiddenDense{
 		activation: activation,
 		synapseInitializer: &hiddenDenseSynapses{
@@ -309,6 +291,7 @@ func newHiddenDense(prev, curr, next int, bias, learningRate float64, activation
 				next:     next,
 				bias:     bias,
 				nextBias: nextBias,
+				random:   r,
 			},
 		},
 		prevLayerSize: prev,
@@ -326,59 +309,62 @@ type outputDense struct {
 	activation
 	// Cost function exists only in output layer and in hidden layers used indirectly
 	// as a sum of weighted errors. Thus cost function is global for a network.
-	input []float64
+	input *Tensor
 	cost
 	prevLayerSize, currLayerSize int
+	// Graph of the last forward pass
+	summed, prediction *variable
 }
 
-func (l *outputDense) forward(rowInput [][]float64) (output []float64, err error) {
-	if err = checkInputSize(len(rowInput), l.currLayerSize); err != nil {
-		lockErr := err.(locatedError)
-		err = lockErr.freeze()
+func (l *outputDense) forward(rowInput *Tensor) (output *Tensor, err error) {
+	if err = checkInputSize(rowsNumber(rowInput), l.currLayerSize); err != nil {
 		return
 	}
 
-	var iSum, actVal float64
-
 	l.input = nil
-	for _, raw := range rowInput {
-		iSum = 0
-		for _, item := range raw {
-			iSum += item
-		}
-
-		l.input = append(l.input, iSum)
-		actVal, err = l.activate(iSum)
-		if err != nil {
-			return
-		}
-		output = append(output, actVal)
+	if l.summed, err = apply(sumAxisOp{1}, newVariable(rowInput)); err != nil {
+		return
 	}
-	return
+	l.input = l.summed.value
+	if l.prediction, err = apply(activationOp(l.activation), l.summed); err != nil {
+		return
+	}
+	return l.prediction.value, nil
 }
 
-func (l *outputDense) forwardMeasure(rowInput [][]float64, labels []float64) (prediction []float64, cost float64, err error) {
+func (l *outputDense) forwardMeasure(rowInput, labels *Tensor) (prediction *Tensor, cost float64, err error) {
 	prediction, err = l.forward(rowInput)
 	if err != nil {
 		return
 	}
-	cost = l.countCost(prediction, labels)
+	cost = l.countCost(prediction.Data(), labels.Data())
 	return
 }
 
-func (l *outputDense) backward(prediction []float64, labels []float64) (eRRors []float64, err error) {
-	var eRR, actDer float64
+func (l *outputDense) backward(prediction, labels *Tensor) (eRRors *Tensor, err error) {
+	if err = checkForwarded(l.prediction); err != nil {
+		lockErr := err.(locatedError)
+		err = lockErr.freeze()
+		return
+	}
 
-	for i, pred := range prediction {
-		// Delta rule
-		actDer, err = l.actDerivative(l.input[i])
-		if err != nil {
-			return
-		}
-		eRR = l.costDerivative(pred, labels[i]) * actDer
-		eRRors = append(eRRors, eRR)
+	// Delta rule: a gradient of the cost seeds the activation graph.
+	var measured *variable
+	pred := newVariable(prediction)
+	if measured, err = apply(costOp{l.cost}, pred, newVariable(labels)); err != nil {
+		return
 	}
-	return
+	if err = measured.backprop(); err != nil {
+		return
+	}
+	if err = l.prediction.backward(pred.grad); err != nil {
+		return
+	}
+	return l.summed.grad, nil
+}
+
+func (l *outputDense) labelSize() int {
+	return l.currLayerSize
 }
 
 func newOutput(prev, curr int, activation activation, cost cost) outputLayer {
@@ -389,3 +375,30 @@ func newOutput(prev, curr int, activation activation, cost cost) outputLayer {
 		currLayerSize: curr,
 	}
 }
+
+// backwardWeighted propagates errors of next layer neurons through a dense connection.
+// An error of a neuron is a gradient of every signal the neuron sums.
+func backwardWeighted(weighted *variable, eRRors *Tensor) error {
+	column, err := eRRors.Reshape(-1, 1)
+	if err != nil {
+		return err
+	}
+	spread, err := column.broadcastTo(weighted.value.shape)
+	if err != nil {
+		return err
+	}
+	return weighted.backward(spread)
+}
+
+// applyDenseCorrections to synapses, pruned synapses of a mask stay zero.
+func applyDenseCorrections(synapses, corrections, mask *Tensor, currLayerSize, nextLayerSize int, learningRate, batchSize float64) (err error) {
+	if err = areCorrsConsistent(corrections, synapses, currLayerSize, nextLayerSize); err != nil {
+		return
+	}
+
+	synapses.descend(corrections, learningRate, batchSize)
+	if mask != nil {
+		return synapses.mulInPlace(mask)
+	}
+	return
+}
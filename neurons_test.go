@@ -46,18 +46,18 @@ func Test_inputDense_forward(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			l := &inputDense{
-				synapses:      tt.fields.synapses,
+				synapses:      rowsOf(tt.fields.synapses),
 				nextLayerSize: tt.fields.nextLayerSize,
 				currLayerSize: tt.fields.currLayerSize,
 				nextBias:      tt.fields.nextBias,
 			}
-			gotOutput, err := l.forward(tt.args.input)
+			gotOutput, err := l.forward(Vector(tt.args.input))
 			if (err != nil) != tt.wantErr {
 				t.Errorf("inputDense.forward() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if !reflect.DeepEqual(gotOutput, tt.wantOutput) {
-				t.Errorf("inputDense.forward() = %v, want %v", gotOutput, tt.wantOutput)
+			if !reflect.DeepEqual(gotOutput.Rows(), tt.wantOutput) {
+				t.Errorf("inputDense.forward() = %v, want %v", gotOutput.Rows(), tt.wantOutput)
 			}
 		})
 	}
@@ -130,17 +130,17 @@ func Test_hiddenDense_forward(t *testing.T) {
 				prevLayerSize: tt.fields.prevLayerSize,
 				currLayerSize: tt.fields.currLayerSize,
 				nextLayerSize: tt.fields.nextLayerSize,
-				synapses:      tt.fields.synapses,
+				synapses:      rowsOf(tt.fields.synapses),
 				nextBias:      tt.fields.nextBias,
 				bias:          tt.fields.bias,
 			}
-			gotOutput, err := l.forward(tt.args.input)
+			gotOutput, err := l.forward(rowsOf(tt.args.input))
 			if (err != nil) != tt.wantErr {
 				t.Errorf("hiddenDense.forward() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if !reflect.DeepEqual(gotOutput, tt.wantOutput) {
-				t.Errorf("hiddenDense.forward() = %v, want %v", gotOutput, tt.wantOutput)
+			if !reflect.DeepEqual(gotOutput.Rows(), tt.wantOutput) {
+				t.Errorf("hiddenDense.forward() = %v, want %v", gotOutput.Rows(), tt.wantOutput)
 			}
 		})
 	}
@@ -192,13 +192,13 @@ func Test_outputDense_forward(t *testing.T) {
 				prevLayerSize: tt.fields.prevLayerSize,
 				currLayerSize: tt.fields.currLayerSize,
 			}
-			gotOutput, err := l.forward(tt.args.rowInput)
+			gotOutput, err := l.forward(rowsOf(tt.args.rowInput))
 			if (err != nil) != tt.wantErr {
 				t.Errorf("outputDense.forward() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if !reflect.DeepEqual(gotOutput, tt.wantOutput) {
-				t.Errorf("outputDense.forward() = %v, want %v", gotOutput, tt.wantOutput)
+			if !reflect.DeepEqual(gotOutput.Data(), tt.wantOutput) {
+				t.Errorf("outputDense.forward() = %v, want %v", gotOutput.Data(), tt.wantOutput)
 			}
 		})
 	}
@@ -229,17 +229,17 @@ func Test_outputDense_forwardMeasure(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			l := &outputDense{
 				activation:    tt.fields.activation,
-				input:         tt.fields.input,
+				input:         Vector(tt.fields.input),
 				cost:          tt.fields.cost,
 				prevLayerSize: tt.fields.prevLayerSize,
 			}
-			gotPrediction, gotCost, err := l.forwardMeasure(tt.args.rowInput, tt.args.labels)
+			gotPrediction, gotCost, err := l.forwardMeasure(rowsOf(tt.args.rowInput), Vector(tt.args.labels))
 			if (err != nil) != tt.wantErr {
 				t.Errorf("outputDense.forwardMeasure() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if !reflect.DeepEqual(gotPrediction, tt.wantPrediction) {
-				t.Errorf("outputDense.forwardMeasure() gotPrediction = %v, want %v", gotPrediction, tt.wantPrediction)
+			if !reflect.DeepEqual(gotPrediction.Data(), tt.wantPrediction) {
+				t.Errorf("outputDense.forwardMeasure() gotPrediction = %v, want %v", gotPrediction.Data(), tt.wantPrediction)
 			}
 			if gotCost != tt.wantCost {
 				t.Errorf("outputDense.forwardMeasure() gotCost = %v, want %v", gotCost, tt.wantCost)
@@ -252,10 +252,11 @@ func Test_inputDense_backward(t *testing.T) {
 	type fields struct {
 		nextLayerSize  int
 		currLayerSize  int
-		input          []float64
+		synapses       [][]float64
 		bias, nextBias bool
 	}
 	type args struct {
+		input  []float64
 		eRRors []float64
 	}
 	tests := []struct {
@@ -270,31 +271,56 @@ func Test_inputDense_backward(t *testing.T) {
 			fields: fields{
 				nextLayerSize: 5,
 				currLayerSize: 4,
-				input:         []float64{1, 2, 3},
-				nextBias:      true,
-				bias:          true,
+				synapses: [][]float64{
+					{1, 1, 1, 1}, {1, 1, 1, 1}, {1, 1, 1, 1}, {1, 1, 1, 1},
+				},
+				nextBias: true,
+				bias:     true,
+			},
+			args: args{
+				input:  []float64{1, 2, 3, 0},
+				eRRors: []float64{1, 2, 3, 4},
 			},
-			args: args{[]float64{1, 2, 3, 4}},
 			want: [][]float64{
 				{1, 2, 3, 4}, {2, 4, 6, 8}, {3, 6, 9, 12}, {1, 2, 3, 4},
 			},
 		},
+		{
+			name: "inputBackwardWrongErrors",
+			fields: fields{
+				nextLayerSize: 5,
+				currLayerSize: 4,
+				synapses: [][]float64{
+					{1, 1, 1, 1}, {1, 1, 1, 1}, {1, 1, 1, 1}, {1, 1, 1, 1},
+				},
+				nextBias: true,
+				bias:     true,
+			},
+			args: args{
+				input:  []float64{1, 2, 3, 0},
+				eRRors: []float64{1, 2},
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			l := &inputDense{
 				nextLayerSize: tt.fields.nextLayerSize,
 				currLayerSize: tt.fields.currLayerSize,
-				input:         tt.fields.input,
+				synapses:      rowsOf(tt.fields.synapses),
 				bias:          tt.fields.bias,
 				nextBias:      tt.fields.nextBias,
 			}
 
-			if err := l.backward(tt.args.eRRors); (err != nil) != tt.wantErr {
-				t.Errorf("hiddenDense.applyCorrections() error = %v, wantErr %v", err, tt.wantErr)
+			if _, err := l.forward(Vector(tt.args.input)); err != nil {
+				t.Fatalf("inputDense.forward() error = %v", err)
 			}
-			if !reflect.DeepEqual(l.corrections, tt.want) {
-				t.Errorf("hiddenDense.corrections = %v, want %v", l.corrections, tt.want)
+			if err := l.backward(Vector(tt.args.eRRors)); (err != nil) != tt.wantErr {
+				t.Errorf("inputDense.backward() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.want != nil && !reflect.DeepEqual(l.corrections.Rows(), tt.want) {
+				t.Errorf("inputDense.corrections = %v, want %v", l.corrections.Rows(), tt.want)
 			}
 		})
 	}
@@ -307,11 +333,10 @@ func Test_hiddenDense_backward(t *testing.T) {
 		currLayerSize  int
 		nextLayerSize  int
 		synapses       [][]float64
-		activated      []float64
-		input          []float64
 		nextBias, bias bool
 	}
 	type args struct {
+		input  [][]float64
 		eRRors []float64
 	}
 	tests := []struct {
@@ -328,8 +353,6 @@ func Test_hiddenDense_backward(t *testing.T) {
 				prevLayerSize: 4,
 				currLayerSize: 5,
 				nextLayerSize: 3,
-				input:         []float64{1, 2, 3, 4},
-				activated:     []float64{1, 2, 3, 4},
 				synapses: [][]float64{
 					{1, 2, 3},
 					{10, 20, 30},
@@ -339,7 +362,10 @@ func Test_hiddenDense_backward(t *testing.T) {
 				},
 				bias: true,
 			},
-			args:                args{[]float64{1, 2, 3}},
+			args: args{
+				input:  [][]float64{{1}, {2}, {3}, {4}},
+				eRRors: []float64{1, 2, 3},
+			},
 			wantPrevLayerErrors: []float64{14, 280, 4200, 56000},
 		},
 	}
@@ -349,19 +375,20 @@ func Test_hiddenDense_backward(t *testing.T) {
 				activation:    tt.fields.activation,
 				currLayerSize: tt.fields.currLayerSize,
 				nextLayerSize: tt.fields.nextLayerSize,
-				synapses:      tt.fields.synapses,
-				activated:     tt.fields.activated,
-				input:         tt.fields.input,
+				synapses:      rowsOf(tt.fields.synapses),
 				nextBias:      tt.fields.nextBias,
 				bias:          tt.fields.bias,
 			}
-			gotPrevLayerErrors, err := l.backward(tt.args.eRRors)
+			if _, err := l.forward(rowsOf(tt.args.input)); err != nil {
+				t.Fatalf("hiddenDense.forward() error = %v", err)
+			}
+			gotPrevLayerErrors, err := l.backward(Vector(tt.args.eRRors))
 			if (err != nil) != tt.wantErr {
 				t.Errorf("hiddenDense.backward() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if !reflect.DeepEqual(gotPrevLayerErrors, tt.wantPrevLayerErrors) {
-				t.Errorf("hiddenDense.backward() = %v, want %v", gotPrevLayerErrors, tt.wantPrevLayerErrors)
+			if !reflect.DeepEqual(gotPrevLayerErrors.Data(), tt.wantPrevLayerErrors) {
+				t.Errorf("hiddenDense.backward() = %v, want %v", gotPrevLayerErrors.Data(), tt.wantPrevLayerErrors)
 			}
 		})
 	}
@@ -372,9 +399,10 @@ func Test_outputDense_backward(t *testing.T) {
 		activation    activation
 		cost          cost
 		prevLayerSize int
-		input         []float64
+		currLayerSize int
 	}
 	type args struct {
+		rowInput   [][]float64
 		prediction []float64
 		labels     []float64
 	}
@@ -391,9 +419,10 @@ func Test_outputDense_backward(t *testing.T) {
 				activation:    new(mockActivation),
 				cost:          new(mockCost),
 				prevLayerSize: 5,
-				input:         []float64{1, 2, 3},
+				currLayerSize: 3,
 			},
 			args: args{
+				rowInput:   [][]float64{{1}, {2}, {3}},
 				prediction: []float64{2, 3, 4},
 				labels:     []float64{1, 1, 1},
 			},
@@ -406,28 +435,32 @@ func Test_outputDense_backward(t *testing.T) {
 				activation:    tt.fields.activation,
 				cost:          tt.fields.cost,
 				prevLayerSize: tt.fields.prevLayerSize,
-				input:         tt.fields.input,
+				currLayerSize: tt.fields.currLayerSize,
+			}
+			if _, err := l.forward(rowsOf(tt.args.rowInput)); err != nil {
+				t.Fatalf("outputDense.forward() error = %v", err)
 			}
-			gotERRors, err := l.backward(tt.args.prediction, tt.args.labels)
+			gotERRors, err := l.backward(Vector(tt.args.prediction), Vector(tt.args.labels))
 			if (err != nil) != tt.wantErr {
 				t.Errorf("outputDense.backward() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if !reflect.DeepEqual(gotERRors, tt.wantERRors) {
-				t.Errorf("outputDense.backward() = %v, want %v", gotERRors, tt.wantERRors)
+			if !reflect.DeepEqual(gotERRors.Data(), tt.wantERRors) {
+				t.Errorf("outputDense.backward() = %v, want %v", gotERRors.Data(), tt.wantERRors)
 			}
 		})
 	}
 }
 
-func Test_hiddenDense_updateCorrections(t *testing.T) {
+func Test_hiddenDense_corrections(t *testing.T) {
 	type fields struct {
 		currLayerSize int
 		nextLayerSize int
-		activated     []float64
+		synapses      [][]float64
 		bias          bool
 	}
 	type args struct {
+		input  [][]float64
 		eRRors []float64
 	}
 	tests := []struct {
@@ -437,14 +470,19 @@ func Test_hiddenDense_updateCorrections(t *testing.T) {
 		want   [][]float64
 	}{
 		{
-			name: "updateCorrectionsHiddenToOutput",
+			name: "correctionsHiddenToOutput",
 			fields: fields{
 				currLayerSize: 5,
 				nextLayerSize: 3,
-				activated:     []float64{2, 3, 4, 5},
-				bias:          true,
+				synapses: [][]float64{
+					{1, 1, 1}, {1, 1, 1}, {1, 1, 1}, {1, 1, 1}, {1, 1, 1},
+				},
+				bias: true,
+			},
+			args: args{
+				input:  [][]float64{{2}, {3}, {4}, {5}},
+				eRRors: []float64{1, 4, 9},
 			},
-			args: args{[]float64{1, 4, 9}},
 			want: [][]float64{
 				{2, 8, 18}, {3, 12, 27}, {4, 16, 36}, {5, 20, 45}, {1, 4, 9},
 			},
@@ -453,13 +491,20 @@ func Test_hiddenDense_updateCorrections(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			l := &hiddenDense{
+				activation:    new(mockActivation),
 				currLayerSize: tt.fields.currLayerSize,
 				nextLayerSize: tt.fields.nextLayerSize,
-				activated:     tt.fields.activated,
+				synapses:      rowsOf(tt.fields.synapses),
 				bias:          tt.fields.bias,
 			}
-			if got := l.updateCorrections(tt.args.eRRors); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("hiddenDense.updateCorrections() = %v, want %v", got, tt.want)
+			if _, err := l.forward(rowsOf(tt.args.input)); err != nil {
+				t.Fatalf("hiddenDense.forward() error = %v", err)
+			}
+			if _, err := l.backward(Vector(tt.args.eRRors)); err != nil {
+				t.Fatalf("hiddenDense.backward() error = %v", err)
+			}
+			if !reflect.DeepEqual(l.corrections.Rows(), tt.want) {
+				t.Errorf("hiddenDense.corrections = %v, want %v", l.corrections.Rows(), tt.want)
 			}
 		})
 	}
@@ -491,12 +536,12 @@ func Test_inputDense_applyCorrections(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			l := &inputDense{
 				synapseInitializer: tt.fields.synapseInitializer,
-				corrections:        tt.fields.corrections,
-				synapses:           tt.fields.synapses,
+				corrections:        rowsOf(tt.fields.corrections),
+				synapses:           rowsOf(tt.fields.synapses),
 				nextLayerSize:      tt.fields.nextLayerSize,
 				currLayerSize:      tt.fields.currLayerSize,
 				learningRate:       tt.fields.learningRate,
-				input:              tt.fields.input,
+				input:              Vector(tt.fields.input),
 				bias:               tt.fields.bias,
 			}
 			if err := l.applyCorrections(tt.args.batchSize); (err != nil) != tt.wantErr {
@@ -551,16 +596,16 @@ func Test_hiddenDense_applyCorrections(t *testing.T) {
 				currLayerSize: tt.fields.currLayerSize,
 				nextLayerSize: tt.fields.nextLayerSize,
 				learningRate:  tt.fields.learningRate,
-				corrections:   tt.fields.corrections,
-				synapses:      tt.fields.synapses,
+				corrections:   rowsOf(tt.fields.corrections),
+				synapses:      rowsOf(tt.fields.synapses),
 				nextBias:      tt.fields.nextBias,
 				bias:          tt.fields.bias,
 			}
 			if err := l.applyCorrections(tt.args.batchSize); (err != nil) != tt.wantErr {
 				t.Errorf("hiddenDense.applyCorrections() error = %v, wantErr %v", err, tt.wantErr)
 			}
-			if !reflect.DeepEqual(l.synapses, tt.want) {
-				t.Errorf("hiddenDense.synapses = %v, want %v", l.synapses, tt.want)
+			if !reflect.DeepEqual(l.synapses.Rows(), tt.want) {
+				t.Errorf("hiddenDense.synapses = %v, want %v", l.synapses.Rows(), tt.want)
 			}
 		})
 	}
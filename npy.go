@@ -0,0 +1,279 @@
+package goDeep
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	npyMagic = "\x93NUMPY"
+	// npyMaxHeader is a largest header read, as numpy.load reads by default.
+	npyMaxHeader = 10000
+	// npyChunk is a number of bytes of data read at once.
+	npyChunk = 1 << 16
+)
+
+var (
+	npyDescr   = regexp.MustCompile(`['"]descr['"]\s*:\s*['"]([^'"]*)['"]`)
+	npyFortran = regexp.MustCompile(`['"]fortran_order['"]\s*:\s*(True|False)`)
+	npyShape   = regexp.MustCompile(`['"]shape['"]\s*:\s*\(([^)]*)\)`)
+)
+
+// NPYFormat of written arrays. The zero format is float64 in a C order.
+type NPYFormat struct {
+	Float32 bool
+	Fortran bool
+}
+
+/*
+ReadNPY reads an array of the NumPy .npy format: float32 or float64 elements of any byte
+order, in a C or a Fortran order. A tensor has a shape of an array. Data are read by chunks,
+so a header declaring more data than a reader has fails without allocating a whole array.
+*/
+func ReadNPY(r io.Reader) (*Tensor, error) {
+	var preamble [8]byte
+	if _, err := io.ReadFull(r, preamble[:]); err != nil {
+		return nil, err
+	}
+	if string(preamble[:6]) != npyMagic {
+		return nil, fmt.Errorf("NPY: wrong magic string %q", preamble[:6])
+	}
+
+	var headerSize int
+	switch preamble[6] {
+	case 1:
+		var size uint16
+		if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+			return nil, err
+		}
+		headerSize = int(size)
+	case 2, 3:
+		var size uint32
+		if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+			return nil, err
+		}
+		headerSize = int(size)
+	default:
+		return nil, fmt.Errorf("NPY: version %d.%d is not supported", preamble[6], preamble[7])
+	}
+	if headerSize > npyMaxHeader {
+		return nil, fmt.Errorf("NPY: a header of %d bytes is longer than %d", headerSize, npyMaxHeader)
+	}
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+
+	order, size, fortran, shape, err := parseNPYHeader(string(header))
+	if err != nil {
+		return nil, err
+	}
+	elements, err := npyElements(shape, size)
+	if err != nil {
+		return nil, err
+	}
+	data, err := readNPYData(io.LimitReader(r, int64(elements)*int64(size)), order, size, elements)
+	if err != nil {
+		return nil, err
+	}
+
+	if !fortran {
+		return newTensor(data, shape), nil
+	}
+	// A Fortran order is a C order of reversed dimensions.
+	reversed := make([]int, len(shape))
+	for i, d := range shape {
+		reversed[len(shape)-1-i] = d
+	}
+	return newTensor(data, reversed).Transpose().Clone(), nil
+}
+
+// npyElements of a shape, an error if data of elements of a size don't fit in memory.
+func npyElements(shape []int, size int) (int, error) {
+	elements := 1
+	for _, d := range shape {
+		if d != 0 && elements > math.MaxInt/size/d {
+			return 0, fmt.Errorf("NPY: data of a shape %v are too large", shape)
+		}
+		elements *= d
+	}
+	return elements, nil
+}
+
+// readNPYData of elements of a size by chunks, short data are an error.
+func readNPYData(r io.Reader, order binary.ByteOrder, size, elements int) ([]float64, error) {
+	capacity := elements
+	if capacity > npyChunk/size {
+		capacity = npyChunk / size
+	}
+	data := make([]float64, 0, capacity)
+	raw := make([]byte, capacity*size)
+	for len(data) < elements {
+		chunk := raw
+		if rest := (elements - len(data)) * size; rest < len(chunk) {
+			chunk = chunk[:rest]
+		}
+		if _, err := io.ReadFull(r, chunk); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return nil, fmt.Errorf("NPY: data end before %d elements", elements)
+			}
+			return nil, err
+		}
+		for i := 0; i < len(chunk); i += size {
+			if size == 4 {
+				data = append(data, float64(math.Float32frombits(order.Uint32(chunk[i:]))))
+			} else {
+				data = append(data, math.Float64frombits(order.Uint64(chunk[i:])))
+			}
+		}
+	}
+	return data, nil
+}
+
+func parseNPYHeader(header string) (order binary.ByteOrder, size int, fortran bool, shape []int, err error) {
+	descr, fortranOrder, dims := npyDescr.FindStringSubmatch(header), npyFortran.FindStringSubmatch(header), npyShape.FindStringSubmatch(header)
+	if descr == nil || fortranOrder == nil || dims == nil {
+		return nil, 0, false, nil, fmt.Errorf("NPY: malformed header %q", header)
+	}
+
+	switch descr[1] {
+	case "<f4", "=f4":
+		order, size = binary.LittleEndian, 4
+	case "<f8", "=f8":
+		order, size = binary.LittleEndian, 8
+	case ">f4":
+		order, size = binary.BigEndian, 4
+	case ">f8":
+		order, size = binary.BigEndian, 8
+	default:
+		return nil, 0, false, nil, fmt.Errorf("NPY: an element type %s is not supported, float32 or float64 is", descr[1])
+	}
+
+	shape = []int{}
+	for _, dim := range strings.Split(dims[1], ",") {
+		if dim = strings.TrimSpace(dim); dim == "" {
+			continue
+		}
+		d, err := strconv.Atoi(dim)
+		if err != nil || d < 0 {
+			return nil, 0, false, nil, fmt.Errorf("NPY: wrong shape (%s)", dims[1])
+		}
+		shape = append(shape, d)
+	}
+	return order, size, fortranOrder[1] == "True", shape, nil
+}
+
+// WriteNPY writes a tensor as an array of the NumPy .npy format of version 1.0.
+func WriteNPY(w io.Writer, t *Tensor, format NPYFormat) error {
+	descr, size := "<f8", 8
+	if format.Float32 {
+		descr, size = "<f4", 4
+	}
+	dims := make([]string, len(t.shape))
+	for i, d := range t.shape {
+		dims[i] = strconv.Itoa(d)
+	}
+	shape := strings.Join(dims, ", ")
+	if len(dims) == 1 {
+		shape += ","
+	}
+	fortran := "False"
+	data := t.Data()
+	if format.Fortran {
+		fortran, data = "True", t.Transpose().Data()
+	}
+
+	// A header is padded by spaces and a newline to align data by 64 bytes.
+	header := fmt.Sprintf("{'descr': '%s', 'fortran_order': %s, 'shape': (%s), }", descr, fortran, shape)
+	padding := 64 - (len(npyMagic)+4+len(header)+1)%64
+	header += strings.Repeat(" ", padding%64) + "\n"
+
+	buf := bytes.NewBufferString(npyMagic)
+	buf.Write([]byte{1, 0})
+	binary.Write(buf, binary.LittleEndian, uint16(len(header)))
+	buf.WriteString(header)
+	raw := make([]byte, len(data)*size)
+	for i, v := range data {
+		if size == 4 {
+			binary.LittleEndian.PutUint32(raw[i*4:], math.Float32bits(float32(v)))
+		} else {
+			binary.LittleEndian.PutUint64(raw[i*8:], math.Float64bits(v))
+		}
+	}
+	buf.Write(raw)
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+/*
+ReadNPZ reads arrays of the NumPy .npz format, a zip archive of .npy files, compressed
+or not. Arrays are keyed by names of files without an extension.
+*/
+func ReadNPZ(r io.ReaderAt, size int64) (map[string]*Tensor, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	arrays := make(map[string]*Tensor)
+	for _, f := range archive.File {
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		t, err := ReadNPY(rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", f.Name, err)
+		}
+		arrays[strings.TrimSuffix(f.Name, ".npy")] = t
+	}
+	return arrays, nil
+}
+
+// WriteNPZ writes arrays of names as a compressed NumPy .npz archive.
+func WriteNPZ(w io.Writer, arrays map[string]*Tensor, format NPYFormat) error {
+	names := make([]string, 0, len(arrays))
+	for name := range arrays {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	archive := zip.NewWriter(w)
+	for _, name := range names {
+		f, err := archive.CreateHeader(&zip.FileHeader{Name: name + ".npy", Method: zip.Deflate})
+		if err != nil {
+			return err
+		}
+		if err = WriteNPY(f, arrays[name], format); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+/*
+WriteWeightsNPZ writes weights of a network as a NumPy .npz archive of arrays named by
+parameters: "input.synapses", "input.embeddings" and "hidden[i].synapses". Synapses are
+matrices of a row per a neuron of a layer, a last row is of biases if a layer has a bias.
+*/
+func WriteWeightsNPZ(w io.Writer, n Network, format NPYFormat) error {
+	return WriteNPZ(w, parametersOf(n), format)
+}
+
+// ReadWeightsNPZ loads weights of a network from a NumPy .npz archive of arrays named
+// as by WriteWeightsNPZ. Every parameter of a network must be of an archive.
+func ReadWeightsNPZ(r io.ReaderAt, size int64, n Network) error {
+	arrays, err := ReadNPZ(r, size)
+	if err != nil {
+		return err
+	}
+	return loadParameters(n.parameters(), arrays)
+}
@@ -0,0 +1,173 @@
+package goDeep
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// npyBytes of an array of a header and raw data.
+func npyBytes(version byte, header string, order binary.ByteOrder, data interface{}) []byte {
+	buf := bytes.NewBufferString(npyMagic)
+	buf.Write([]byte{version, 0})
+	if version == 1 {
+		binary.Write(buf, binary.LittleEndian, uint16(len(header)))
+	} else {
+		binary.Write(buf, binary.LittleEndian, uint32(len(header)))
+	}
+	buf.WriteString(header)
+	binary.Write(buf, order, data)
+	return buf.Bytes()
+}
+
+func TestReadNPY(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     []byte
+		wantShape []int
+		want      []float64
+		wantErr   bool
+	}{
+		{
+			name:      "float64",
+			input:     npyBytes(1, "{'descr': '<f8', 'fortran_order': False, 'shape': (2, 3), }\n", binary.LittleEndian, []float64{1, 2, 3, 4, 5, 6}),
+			wantShape: []int{2, 3},
+			want:      []float64{1, 2, 3, 4, 5, 6},
+		},
+		{
+			name:      "float32Fortran",
+			input:     npyBytes(1, "{'descr': '<f4', 'fortran_order': True, 'shape': (2, 3), }\n", binary.LittleEndian, []float32{1, 4, 2, 5, 3, 6}),
+			wantShape: []int{2, 3},
+			want:      []float64{1, 2, 3, 4, 5, 6},
+		},
+		{
+			name:      "bigEndianVersion2",
+			input:     npyBytes(2, "{'descr': '>f8', 'fortran_order': False, 'shape': (3,), }\n", binary.BigEndian, []float64{.5, -1, 2}),
+			wantShape: []int{3},
+			want:      []float64{.5, -1, 2},
+		},
+		{
+			name:    "integers",
+			input:   npyBytes(1, "{'descr': '<i8', 'fortran_order': False, 'shape': (1,), }\n", binary.LittleEndian, []int64{1}),
+			wantErr: true,
+		},
+		{
+			name:    "truncated",
+			input:   npyBytes(1, "{'descr': '<f8', 'fortran_order': False, 'shape': (2,), }\n", binary.LittleEndian, []float64{1}),
+			wantErr: true,
+		},
+		{
+			name:      "empty",
+			input:     npyBytes(1, "{'descr': '<f8', 'fortran_order': False, 'shape': (0, 3), }\n", binary.LittleEndian, []float64{}),
+			wantShape: []int{0, 3},
+			want:      []float64{},
+		},
+		{
+			// A header of an array of 8 TB and no data.
+			name:    "hugeShape",
+			input:   npyBytes(1, "{'descr': '<f8', 'fortran_order': False, 'shape': (1000000, 1000000), }\n", binary.LittleEndian, []float64{1}),
+			wantErr: true,
+		},
+		{
+			name:    "overflowingShape",
+			input:   npyBytes(1, "{'descr': '<f8', 'fortran_order': False, 'shape': (4294967296, 4294967296), }\n", binary.LittleEndian, []float64{1}),
+			wantErr: true,
+		},
+		{name: "longHeader", input: []byte("\x93NUMPY\x02\x00\xff\xff\xff\xff"), wantErr: true},
+		{name: "wrongMagic", input: []byte("\x93NUMPZ\x01\x00"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadNPY(bytes.NewReader(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadNPY() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(got.Shape(), tt.wantShape) || !reflect.DeepEqual(got.Data(), tt.want) {
+				t.Errorf("ReadNPY() = %v of %v, want %v of %v", got.Data(), got.Shape(), tt.want, tt.wantShape)
+			}
+		})
+	}
+}
+
+func TestWriteNPY(t *testing.T) {
+	tensor, _ := NewTensor([]float64{1, .25, -3, 1e-7, 5, 6}, 3, 2)
+	for _, format := range []NPYFormat{{}, {Float32: true}, {Fortran: true}, {Float32: true, Fortran: true}} {
+		var buf bytes.Buffer
+		if err := WriteNPY(&buf, tensor, format); err != nil {
+			t.Fatalf("WriteNPY(%+v) error = %v", format, err)
+		}
+		size := 8
+		if format.Float32 {
+			size = 4
+		}
+		if offset := buf.Len() - tensor.Size()*size; offset%64 != 0 {
+			t.Errorf("WriteNPY(%+v) data offset = %d, want aligned by 64", format, offset)
+		}
+
+		got, err := ReadNPY(&buf)
+		if err != nil {
+			t.Fatalf("ReadNPY() error = %v", err)
+		}
+		if !reflect.DeepEqual(got.Shape(), tensor.Shape()) {
+			t.Fatalf("ReadNPY() shape = %v, want %v", got.Shape(), tensor.Shape())
+		}
+		for i, v := range tensor.Data() {
+			if math.Abs(got.Data()[i]-v) > 1e-7*math.Abs(v) {
+				t.Errorf("ReadNPY(WriteNPY(%+v)) = %v, want %v", format, got.Data(), tensor.Data())
+				break
+			}
+		}
+	}
+}
+
+func TestWriteWeightsNPZ(t *testing.T) {
+	config := ModelConfig{
+		Input:  &LayerConfig{Size: 3, LearningRate: .1, Bias: 1},
+		Hidden: []LayerConfig{{Size: 4, LearningRate: .1, Bias: 1, Activation: "sigmoid"}},
+		Output: LayerConfig{Size: 2, Activation: "sigmoid", Cost: "quadratic"},
+	}
+	n, err := NewFromConfig(config)
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	var buf bytes.Buffer
+	if err = WriteWeightsNPZ(&buf, n, NPYFormat{}); err != nil {
+		t.Fatalf("WriteWeightsNPZ() error = %v", err)
+	}
+
+	arrays, err := ReadNPZ(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("ReadNPZ() error = %v", err)
+	}
+	if got := arrays["hidden[0].synapses"].Shape(); !reflect.DeepEqual(got, []int{4, 2}) {
+		t.Errorf("hidden[0].synapses shape = %v, want [4 2]", got)
+	}
+
+	loaded, err := NewFromConfig(config)
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	if err = ReadWeightsNPZ(bytes.NewReader(buf.Bytes()), int64(buf.Len()), loaded); err != nil {
+		t.Fatalf("ReadWeightsNPZ() error = %v", err)
+	}
+	set := rowsOf([][]float64{{0, 1, 0}, {1, 1, 0}})
+	want, _ := n.Recognize(set)
+	got, _ := loaded.Recognize(set)
+	if !reflect.DeepEqual(got.Data(), want.Data()) {
+		t.Errorf("Recognize() of loaded weights = %v, want %v", got.Data(), want.Data())
+	}
+
+	delete(arrays, "input.synapses")
+	buf.Reset()
+	if err = WriteNPZ(&buf, arrays, NPYFormat{}); err != nil {
+		t.Fatalf("WriteNPZ() error = %v", err)
+	}
+	if err = ReadWeightsNPZ(bytes.NewReader(buf.Bytes()), int64(buf.Len()), loaded); err == nil {
+		t.Error("ReadWeightsNPZ() of missing synapses error = nil")
+	}
+}
@@ -0,0 +1,117 @@
+package goDeep
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// numericGuard checks activations, gradients and weights of a network to be finite.
+type numericGuard struct {
+	dump io.Writer
+}
+
+/*
+WithNumericGuard stops learning with a NumericError when activations of a sample,
+gradients or weights of a batch first went NaN or infinite. Offending inputs are written
+to a dump writer one sample per line if it is not nil.
+*/
+func WithNumericGuard(dump io.Writer) LearnOption {
+	return func(c *learnConfig) {
+		c.guard = &numericGuard{dump}
+	}
+}
+
+// inspector of layer outputs of a sample for a network propagation.
+func (g *numericGuard) inspector(batch int, sample *Tensor) func(int, *Tensor) error {
+	return func(layer int, output *Tensor) error {
+		return g.activations(layer, output, batch, sample)
+	}
+}
+
+// activations of a layer are checked by neurons of a last dimension, a product of a layer
+// passed to a next layer has a column per neuron.
+func (g *numericGuard) activations(layer int, output *Tensor, batch int, sample *Tensor) error {
+	i, v, found := nonFinite(output)
+	if !found {
+		return nil
+	}
+	shape := output.Shape()
+	return g.fail(&NumericError{
+		Layer:  layer,
+		Stage:  "activations",
+		Neuron: i % shape[len(shape)-1],
+		Batch:  batch,
+		Value:  v,
+		Inputs: sample,
+	})
+}
+
+func (g *numericGuard) gradients(layers [][]parameter, batch int, set *Tensor, indices []int) error {
+	return g.parameters(layers, batch, set, indices, "gradients of ", func(p parameter) *Tensor {
+		return p.corrections()
+	})
+}
+
+func (g *numericGuard) weights(layers [][]parameter, batch int, set *Tensor, indices []int) error {
+	return g.parameters(layers, batch, set, indices, "", func(p parameter) *Tensor {
+		return p.value
+	})
+}
+
+func (g *numericGuard) parameters(layers [][]parameter, batch int, set *Tensor, indices []int, stage string, tensor func(parameter) *Tensor) error {
+	for layer, params := range layers {
+		for _, p := range params {
+			t := tensor(p)
+			if t == nil {
+				continue
+			}
+			i, v, found := nonFinite(t)
+			if !found {
+				continue
+			}
+			inputs, err := set.gather(indices)
+			if err != nil {
+				return err
+			}
+			name := strings.SplitN(p.name, ".", 2)
+			return g.fail(&NumericError{
+				Layer:  layer + 1,
+				Stage:  stage + name[len(name)-1],
+				Neuron: i / t.Shape()[t.Dims()-1],
+				Batch:  batch,
+				Value:  v,
+				Inputs: inputs,
+			})
+		}
+	}
+	return nil
+}
+
+// fail dumps inputs of an error if a dump is set.
+func (g *numericGuard) fail(e *NumericError) error {
+	if g.dump == nil {
+		return e
+	}
+	for _, row := range e.Inputs.Rows() {
+		fields := make([]string, len(row))
+		for i, v := range row {
+			fields[i] = fmt.Sprint(v)
+		}
+		if _, err := fmt.Fprintln(g.dump, strings.Join(fields, " ")); err != nil {
+			return err
+		}
+	}
+	return e
+}
+
+// nonFinite finds a first NaN or infinite element in a row-major order.
+func nonFinite(t *Tensor) (i int, v float64, found bool) {
+	for i, v = range t.Data() {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return i, v, true
+		}
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,67 @@
+package goDeep
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestPerceptron_Learn_numericGuard(t *testing.T) {
+	tests := []struct {
+		name     string
+		set      [][]float64
+		labels   [][]float64
+		wantErr  *NumericError
+		wantDump string
+	}{
+		{
+			name:     "activations",
+			set:      [][]float64{{1, 0, 0}, {0, math.Inf(1), 0}},
+			labels:   [][]float64{{0}, {0}},
+			wantErr:  &NumericError{Layer: 1, Stage: "activations", Neuron: 1, Batch: 1},
+			wantDump: "0 +Inf 0\n",
+		},
+		{
+			name:     "gradients",
+			set:      [][]float64{{1, 0, 0}, {0, 1, 0}},
+			labels:   [][]float64{{0}, {math.NaN()}},
+			wantErr:  &NumericError{Layer: 1, Stage: "gradients of synapses", Neuron: 0, Batch: 1},
+			wantDump: "0 1 0\n",
+		},
+		{
+			name:   "finite",
+			set:    [][]float64{{1, 0, 0}, {0, 1, 0}},
+			labels: [][]float64{{0}, {1}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields := mockPerceptronFields()
+			n := &Perceptron{input: fields.input, hidden: fields.hidden, output: fields.output}
+			dump := new(bytes.Buffer)
+
+			_, err := n.Learn(rowsOf(tt.set), rowsOf(tt.labels), 1, 1, WithNumericGuard(dump))
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("Perceptron.Learn() error = %v, want nil", err)
+				}
+				return
+			}
+			var got *NumericError
+			if !errors.As(err, &got) {
+				t.Fatalf("Perceptron.Learn() error = %v, want *NumericError", err)
+			}
+			if got.Layer != tt.wantErr.Layer || got.Stage != tt.wantErr.Stage || got.Neuron != tt.wantErr.Neuron || got.Batch != tt.wantErr.Batch {
+				t.Errorf("Perceptron.Learn() error = %+v, want %+v", got, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got.Inputs.Data(), tt.set[1]) {
+				t.Errorf("NumericError.Inputs = %v, want %v", got.Inputs.Data(), tt.set[1])
+			}
+			if dump.String() != tt.wantDump {
+				t.Errorf("dump = %q, want %q", dump.String(), tt.wantDump)
+			}
+		})
+	}
+}
@@ -0,0 +1,631 @@
+package goDeep
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+)
+
+// ONNX operators of activations by names of a model configuration.
+var onnxActivations = map[string]string{
+	"sigmoid": "Sigmoid",
+	"relu":    "Relu",
+	"tanh":    "Tanh",
+	"softmax": "Softmax",
+}
+
+const (
+	onnxIRVersion = 7
+	onnxOpset     = 13
+
+	// Element types of tensors.
+	onnxFloat  = 1
+	onnxDouble = 11
+
+	// Types of attributes.
+	onnxAttributeFloat = 1
+	onnxAttributeInt   = 2
+)
+
+// onnxGraph is a subset of an ONNX graph of dense networks.
+type onnxGraph struct {
+	name            string
+	nodes           []onnxNode
+	initializers    []onnxTensor
+	inputs, outputs []onnxValue
+}
+
+type onnxNode struct {
+	name, opType    string
+	inputs, outputs []string
+	attributes      []onnxAttribute
+}
+
+type onnxAttribute struct {
+	name string
+	kind int64
+	f    float64
+	i    int64
+}
+
+type onnxTensor struct {
+	name string
+	dims []int64
+	data []float64
+}
+
+// onnxValue is a float tensor of a graph input or output. A negative dimension is a batch.
+type onnxValue struct {
+	name string
+	dims []int64
+}
+
+func (g onnxGraph) marshalModel() []byte {
+	var model, opset, graph protoBuffer
+	model.varint(1, onnxIRVersion)
+	model.string(2, "go_deep")
+	for _, n := range g.nodes {
+		graph.message(1, n.marshal())
+	}
+	graph.string(2, g.name)
+	for _, t := range g.initializers {
+		graph.message(5, t.marshal())
+	}
+	for _, v := range g.inputs {
+		graph.message(11, v.marshal())
+	}
+	for _, v := range g.outputs {
+		graph.message(12, v.marshal())
+	}
+	model.message(7, &graph)
+	opset.varint(2, onnxOpset)
+	model.message(8, &opset)
+	return model.b
+}
+
+func (n onnxNode) marshal() *protoBuffer {
+	var p protoBuffer
+	for _, in := range n.inputs {
+		p.string(1, in)
+	}
+	for _, out := range n.outputs {
+		p.string(2, out)
+	}
+	p.string(3, n.name)
+	p.string(4, n.opType)
+	for _, a := range n.attributes {
+		var attr protoBuffer
+		attr.string(1, a.name)
+		if a.kind == onnxAttributeFloat {
+			attr.float32(2, float32(a.f))
+		} else {
+			attr.varint(3, a.i)
+		}
+		attr.varint(20, a.kind)
+		p.message(5, &attr)
+	}
+	return &p
+}
+
+// marshal a tensor of 32 bit floats.
+func (t onnxTensor) marshal() *protoBuffer {
+	var p protoBuffer
+	p.packedVarints(1, t.dims)
+	p.varint(2, onnxFloat)
+	p.string(8, t.name)
+	raw := make([]byte, 4*len(t.data))
+	for i, v := range t.data {
+		binary.LittleEndian.PutUint32(raw[i*4:], math.Float32bits(float32(v)))
+	}
+	p.bytes(9, raw)
+	return &p
+}
+
+func (v onnxValue) marshal() *protoBuffer {
+	var p, typ, tensor, shape protoBuffer
+	for _, d := range v.dims {
+		var dim protoBuffer
+		if d < 0 {
+			dim.string(2, "N")
+		} else {
+			dim.varint(1, d)
+		}
+		shape.message(1, &dim)
+	}
+	tensor.varint(1, onnxFloat)
+	tensor.message(2, &shape)
+	typ.message(1, &tensor)
+	p.string(1, v.name)
+	p.message(2, &typ)
+	return &p
+}
+
+func unmarshalONNXModel(b []byte) (g onnxGraph, err error) {
+	found := false
+	err = protoFields(b, func(f protoField) error {
+		if f.number != 7 {
+			return nil
+		}
+		found = true
+		return g.unmarshal(f.bytes)
+	})
+	if err == nil && !found {
+		err = fmt.Errorf("ONNX: a model has no graph")
+	}
+	return
+}
+
+func (g *onnxGraph) unmarshal(b []byte) error {
+	return protoFields(b, func(f protoField) error {
+		switch f.number {
+		case 1:
+			var n onnxNode
+			if err := n.unmarshal(f.bytes); err != nil {
+				return err
+			}
+			g.nodes = append(g.nodes, n)
+		case 2:
+			g.name = string(f.bytes)
+		case 5:
+			var t onnxTensor
+			if err := t.unmarshal(f.bytes); err != nil {
+				return err
+			}
+			g.initializers = append(g.initializers, t)
+		case 11, 12:
+			var v onnxValue
+			err := protoFields(f.bytes, func(f protoField) error {
+				if f.number == 1 {
+					v.name = string(f.bytes)
+				}
+				return nil
+			})
+			if f.number == 11 {
+				g.inputs = append(g.inputs, v)
+			} else {
+				g.outputs = append(g.outputs, v)
+			}
+			return err
+		}
+		return nil
+	})
+}
+
+func (n *onnxNode) unmarshal(b []byte) error {
+	return protoFields(b, func(f protoField) error {
+		switch f.number {
+		case 1:
+			n.inputs = append(n.inputs, string(f.bytes))
+		case 2:
+			n.outputs = append(n.outputs, string(f.bytes))
+		case 3:
+			n.name = string(f.bytes)
+		case 4:
+			n.opType = string(f.bytes)
+		case 5:
+			var a onnxAttribute
+			err := protoFields(f.bytes, func(f protoField) error {
+				switch f.number {
+				case 1:
+					a.name = string(f.bytes)
+				case 2:
+					a.f = float64(math.Float32frombits(uint32(f.varint)))
+				case 3:
+					a.i = int64(f.varint)
+				case 20:
+					a.kind = int64(f.varint)
+				}
+				return nil
+			})
+			n.attributes = append(n.attributes, a)
+			return err
+		case 7:
+			if domain := string(f.bytes); domain != "" && domain != "ai.onnx" {
+				return fmt.Errorf("ONNX: an operator domain %q is not supported", domain)
+			}
+		}
+		return nil
+	})
+}
+
+func (t *onnxTensor) unmarshal(b []byte) error {
+	var dataType int64
+	var raw []byte
+	err := protoFields(b, func(f protoField) (err error) {
+		var vs []int64
+		var data []float64
+		switch f.number {
+		case 1:
+			vs, err = f.varints()
+			t.dims = append(t.dims, vs...)
+		case 2:
+			dataType = int64(f.varint)
+		case 4:
+			data, err = f.float32s()
+			t.data = append(t.data, data...)
+		case 8:
+			t.name = string(f.bytes)
+		case 9:
+			raw = f.bytes
+		case 10:
+			data, err = f.float64s()
+			t.data = append(t.data, data...)
+		}
+		return
+	})
+	if err != nil {
+		return err
+	}
+
+	switch dataType {
+	case onnxFloat:
+		if raw != nil {
+			t.data, err = littleEndianFloats(raw, 4)
+		}
+	case onnxDouble:
+		if raw != nil {
+			t.data, err = littleEndianFloats(raw, 8)
+		}
+	default:
+		return fmt.Errorf("ONNX: %s has an element type %d, float or double is supported", t.name, dataType)
+	}
+	if err != nil {
+		return err
+	}
+	var size int64 = 1
+	for _, d := range t.dims {
+		if d <= 0 {
+			return &ConfigError{Field: "ONNX " + t.name + ".dims", Value: t.dims, Reason: "must be positive"}
+		}
+		// Dimensions of more values than there are don't overflow a size.
+		if d > int64(len(t.data)) || size*d > int64(len(t.data)) {
+			size = -1
+			break
+		}
+		size *= d
+	}
+	if size != int64(len(t.data)) {
+		return fmt.Errorf("ONNX: %s has %d values of dimensions %v", t.name, len(t.data), t.dims)
+	}
+	return nil
+}
+
+// checkArity of a node of at least a number of inputs and outputs.
+func (n onnxNode) checkArity(inputs, outputs int) error {
+	if len(n.inputs) < inputs || len(n.outputs) < outputs {
+		return &ConfigError{
+			Field:  "ONNX " + n.name,
+			Value:  n.opType,
+			Reason: fmt.Sprintf("has %d inputs and %d outputs, at least %d and %d are required", len(n.inputs), len(n.outputs), inputs, outputs),
+		}
+	}
+	return nil
+}
+
+func (n onnxNode) attribute(name string) (onnxAttribute, bool) {
+	for _, a := range n.attributes {
+		if a.name == name {
+			return a, true
+		}
+	}
+	return onnxAttribute{}, false
+}
+
+/*
+ExportONNX writes a network as an ONNX model of opset 13. Every dense connection is
+a Gemm operator of 32 bit float weights and biases followed by an activation operator.
+An input of a model is a batch of samples without bias slots.
+
+Only networks of dense inputs and activations of ONNX operators are exported: sigmoid,
+relu, tanh and softmax.
+*/
+func ExportONNX(w io.Writer, n Network) error {
+	c, err := n.Config()
+	if err != nil {
+		return err
+	}
+	if err = denseInputOnly("ExportONNX", c); err != nil {
+		return err
+	}
+	params := make(map[string]*Tensor)
+	for _, p := range n.parameters() {
+		params[p.name] = p.value
+	}
+
+	type connection struct {
+		from, to   string
+		bias       bool
+		activation string
+	}
+	connections := []connection{{from: "input", bias: c.Input.Bias != 0}}
+	for i, h := range c.Hidden {
+		name := fmt.Sprintf("hidden[%d]", i)
+		connections[i].to, connections[i].activation = name, h.Activation
+		connections = append(connections, connection{from: name, bias: h.Bias != 0})
+	}
+	last := len(connections) - 1
+	connections[last].to, connections[last].activation = "output", c.Output.Activation
+
+	g := onnxGraph{name: "go_deep"}
+	signal := "input"
+	for _, conn := range connections {
+		op, ok := onnxActivations[conn.activation]
+		if !ok {
+			return fmt.Errorf("ONNX: an activation %q of %s has no operator", conn.activation, conn.to)
+		}
+		rows := params[conn.from+".synapses"].Rows()
+		weights := rows
+		if conn.bias {
+			weights = rows[:len(rows)-1]
+		}
+		if signal == "input" {
+			g.inputs = []onnxValue{{name: "input", dims: []int64{-1, int64(len(weights))}}}
+		}
+
+		inputs := []string{signal, conn.from + ".weights"}
+		g.initializers = append(g.initializers, onnxTensor{
+			name: conn.from + ".weights",
+			dims: []int64{int64(len(weights)), int64(len(weights[0]))},
+			data: flatten(weights),
+		})
+		if conn.bias {
+			inputs = append(inputs, conn.from+".bias")
+			g.initializers = append(g.initializers, onnxTensor{
+				name: conn.from + ".bias",
+				dims: []int64{int64(len(rows[len(rows)-1]))},
+				data: rows[len(rows)-1],
+			})
+		}
+
+		summed, activated := conn.to+".summed", conn.to+".activated"
+		if conn.to == "output" {
+			activated = "output"
+		}
+		g.nodes = append(g.nodes, onnxNode{name: conn.from, opType: "Gemm", inputs: inputs, outputs: []string{summed}})
+		activation := onnxNode{name: conn.to, opType: op, inputs: []string{summed}, outputs: []string{activated}}
+		if op == "Softmax" {
+			activation.attributes = []onnxAttribute{{name: "axis", kind: onnxAttributeInt, i: 1}}
+		}
+		g.nodes = append(g.nodes, activation)
+		signal = activated
+	}
+	g.outputs = []onnxValue{{name: "output", dims: []int64{-1, int64(c.Output.Size)}}}
+
+	_, err = w.Write(g.marshalModel())
+	return err
+}
+
+func flatten(rows [][]float64) (data []float64) {
+	for _, row := range rows {
+		data = append(data, row...)
+	}
+	return
+}
+
+// importedLayer is a dense connection of an ONNX graph.
+type importedLayer struct {
+	weights    [][]float64
+	bias       []float64
+	activation string
+}
+
+/*
+ImportONNX reads a network of an ONNX model: dense connections of Gemm operators or MatMul
+ones with Add of biases, each followed by Sigmoid, Relu, Tanh or Softmax activation.
+A network has an input and a single hidden layer, so a model has two connections.
+
+ONNX has no learning, so learning rates of layers are learningRate and an output layer
+has a cost of a name. Samples of a network have bias slots as of any dense network.
+*/
+func ImportONNX(r io.Reader, learningRate float64, cost string) (Network, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	g, err := unmarshalONNXModel(b)
+	if err != nil {
+		return nil, err
+	}
+
+	initializers := make(map[string]onnxTensor)
+	for _, t := range g.initializers {
+		initializers[t.name] = t
+	}
+	consumers := make(map[string]onnxNode)
+	for _, n := range g.nodes {
+		for _, in := range n.inputs {
+			if _, ok := initializers[in]; ok || in == "" {
+				continue
+			}
+			if _, ok := consumers[in]; ok {
+				return nil, fmt.Errorf("ONNX: %s has several consumers, branches are not supported", in)
+			}
+			consumers[in] = n
+		}
+	}
+
+	var signal string
+	for _, in := range g.inputs {
+		if _, ok := initializers[in.name]; !ok {
+			signal = in.name
+			break
+		}
+	}
+	if signal == "" {
+		return nil, fmt.Errorf("ONNX: a graph has no input")
+	}
+
+	var layers []importedLayer
+	for {
+		n, ok := consumers[signal]
+		if !ok {
+			break
+		}
+		var l importedLayer
+		if l, signal, err = importDense(n, consumers, initializers); err != nil {
+			return nil, err
+		}
+		act, ok := consumers[signal]
+		if !ok {
+			return nil, fmt.Errorf("ONNX: a dense connection %s has no activation", n.name)
+		}
+		if l.activation, err = importActivation(act); err != nil {
+			return nil, err
+		}
+		layers = append(layers, l)
+		signal = act.outputs[0]
+	}
+
+	if len(g.outputs) != 1 || g.outputs[0].name != signal {
+		return nil, fmt.Errorf("ONNX: a graph output is not of the last activation %s", signal)
+	}
+	if len(layers) != 2 {
+		return nil, fmt.Errorf("ONNX: a graph has %d dense connections, a network has 2", len(layers))
+	}
+	if len(layers[1].weights) != len(layers[0].weights[0]) {
+		return nil, fmt.Errorf("ONNX: %d outputs of a connection are not %d inputs of a next one", len(layers[0].weights[0]), len(layers[1].weights))
+	}
+	return importedNetwork(layers, learningRate, cost)
+}
+
+func importDense(n onnxNode, consumers map[string]onnxNode, initializers map[string]onnxTensor) (l importedLayer, output string, err error) {
+	switch n.opType {
+	case "Gemm", "MatMul":
+		if err = n.checkArity(2, 1); err != nil {
+			return
+		}
+	}
+	switch n.opType {
+	case "Gemm":
+		alpha, beta := 1., 1.
+		if a, ok := n.attribute("alpha"); ok {
+			alpha = a.f
+		}
+		if a, ok := n.attribute("beta"); ok {
+			beta = a.f
+		}
+		if a, ok := n.attribute("transA"); ok && a.i != 0 {
+			return l, "", fmt.Errorf("ONNX: Gemm %s transposes inputs", n.name)
+		}
+		transB, _ := n.attribute("transB")
+		if l.weights, err = importMatrix(n, 1, initializers, transB.i != 0, alpha); err != nil {
+			return
+		}
+		if len(n.inputs) > 2 {
+			l.bias, err = importVector(n, 2, initializers, len(l.weights[0]), beta)
+		}
+		return l, n.outputs[0], err
+	case "MatMul":
+		if l.weights, err = importMatrix(n, 1, initializers, false, 1); err != nil {
+			return
+		}
+		output = n.outputs[0]
+		if add, ok := consumers[output]; ok && add.opType == "Add" {
+			if err = add.checkArity(2, 1); err != nil {
+				return
+			}
+			bias := 1
+			if add.inputs[1] == output {
+				bias = 0
+			}
+			if l.bias, err = importVector(add, bias, initializers, len(l.weights[0]), 1); err != nil {
+				return
+			}
+			output = add.outputs[0]
+		}
+		return l, output, nil
+	}
+	return l, "", fmt.Errorf("ONNX: an operator %s of %s is not supported", n.opType, n.name)
+}
+
+func importMatrix(n onnxNode, input int, initializers map[string]onnxTensor, transposed bool, scale float64) ([][]float64, error) {
+	t, ok := initializers[n.inputs[input]]
+	if !ok || len(t.dims) != 2 || t.dims[0] == 0 || t.dims[1] == 0 {
+		return nil, fmt.Errorf("ONNX: weights of %s are not a matrix initializer", n.name)
+	}
+	rows, cols := int(t.dims[0]), int(t.dims[1])
+	if transposed {
+		rows, cols = cols, rows
+	}
+	m := make([][]float64, rows)
+	for i := range m {
+		m[i] = make([]float64, cols)
+		for j := range m[i] {
+			if transposed {
+				m[i][j] = scale * t.data[j*rows+i]
+			} else {
+				m[i][j] = scale * t.data[i*cols+j]
+			}
+		}
+	}
+	return m, nil
+}
+
+func importVector(n onnxNode, input int, initializers map[string]onnxTensor, size int, scale float64) ([]float64, error) {
+	t, ok := initializers[n.inputs[input]]
+	if !ok || len(t.data) != size {
+		return nil, fmt.Errorf("ONNX: biases of %s are not an initializer of %d values", n.name, size)
+	}
+	v := make([]float64, size)
+	for i, b := range t.data {
+		v[i] = scale * b
+	}
+	return v, nil
+}
+
+func importActivation(n onnxNode) (string, error) {
+	if err := n.checkArity(1, 1); err != nil {
+		return "", err
+	}
+	for name, op := range onnxActivations {
+		if op != n.opType {
+			continue
+		}
+		if axis, ok := n.attribute("axis"); ok && op == "Softmax" && axis.i != 1 && axis.i != -1 {
+			return "", fmt.Errorf("ONNX: Softmax %s is not of a layer axis", n.name)
+		}
+		return name, nil
+	}
+	return "", fmt.Errorf("ONNX: an activation %s of %s is not supported", n.opType, n.name)
+}
+
+func importedNetwork(layers []importedLayer, learningRate float64, cost string) (Network, error) {
+	slot := func(l importedLayer) (int, float64) {
+		if l.bias == nil {
+			return 0, 0
+		}
+		return 1, 1
+	}
+	inputSlot, inputBias := slot(layers[0])
+	hiddenSlot, hiddenBias := slot(layers[1])
+	n, err := NewFromConfig(ModelConfig{
+		Input: &LayerConfig{Size: len(layers[0].weights) + inputSlot, LearningRate: learningRate, Bias: inputBias},
+		Hidden: []LayerConfig{{
+			Size:         len(layers[1].weights) + hiddenSlot,
+			LearningRate: learningRate,
+			Bias:         hiddenBias,
+			Activation:   layers[0].activation,
+		}},
+		Output: LayerConfig{Size: len(layers[1].weights[0]), Activation: layers[1].activation, Cost: cost},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	saved := make(map[string]*Tensor)
+	for i, name := range []string{"input.synapses", "hidden[0].synapses"} {
+		rows := layers[i].weights
+		if layers[i].bias != nil {
+			rows = append(rows[:len(rows):len(rows)], layers[i].bias)
+		}
+		if saved[name], err = FromRows(rows); err != nil {
+			return nil, err
+		}
+	}
+	if err = loadParameters(n.parameters(), saved); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
@@ -0,0 +1,212 @@
+package goDeep
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func assertPredictions(t *testing.T, got, want *Tensor, tolerance float64) {
+	t.Helper()
+	if !sameShape(got.Shape(), want.Shape()) {
+		t.Fatalf("prediction shape = %v, want %v", got.Shape(), want.Shape())
+	}
+	wantData := want.Data()
+	for i, v := range got.Data() {
+		if math.Abs(v-wantData[i]) > tolerance {
+			t.Fatalf("prediction = %v, want %v", got.Data(), wantData)
+		}
+	}
+}
+
+func TestExportONNX(t *testing.T) {
+	set := rowsOf([][]float64{{0, 1, 0}, {1, 1, 0}, {.5, -1, 0}})
+	tests := []struct {
+		name           string
+		inputBias      float64
+		hidden, output string
+	}{
+		{name: "sigmoid", inputBias: 1, hidden: "sigmoid", output: "sigmoid"},
+		{name: "reluSoftmax", inputBias: 1, hidden: "relu", output: "softmax"},
+		{name: "tanhWithoutInputBias", hidden: "tanh", output: "tanh"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := NewFromConfig(ModelConfig{
+				Input:  &LayerConfig{Size: 3, LearningRate: .1, Bias: tt.inputBias},
+				Hidden: []LayerConfig{{Size: 5, LearningRate: .1, Bias: 1, Activation: tt.hidden}},
+				Output: LayerConfig{Size: 3, Activation: tt.output, Cost: "quadratic"},
+			})
+			if err != nil {
+				t.Fatalf("NewFromConfig() error = %v", err)
+			}
+			var buf bytes.Buffer
+			if err = ExportONNX(&buf, n); err != nil {
+				t.Fatalf("ExportONNX() error = %v", err)
+			}
+			imported, err := ImportONNX(&buf, .1, "quadratic")
+			if err != nil {
+				t.Fatalf("ImportONNX() error = %v", err)
+			}
+
+			config, err := imported.Config()
+			if err != nil {
+				t.Fatalf("Perceptron.Config() error = %v", err)
+			}
+			if config.Hidden[0].Activation != tt.hidden || config.Output.Activation != tt.output {
+				t.Errorf("imported activations = %s, %s, want %s, %s", config.Hidden[0].Activation, config.Output.Activation, tt.hidden, tt.output)
+			}
+			want, err := n.Recognize(set)
+			if err != nil {
+				t.Fatalf("Perceptron.Recognize() error = %v", err)
+			}
+			got, err := imported.Recognize(set)
+			if err != nil {
+				t.Fatalf("imported Perceptron.Recognize() error = %v", err)
+			}
+			// Weights are exported as 32 bit floats.
+			assertPredictions(t, got, want, 1e-5)
+		})
+	}
+}
+
+func TestExportONNX_unsupported(t *testing.T) {
+	embedded, err := NewFromConfig(ModelConfig{
+		Embedding: &EmbeddingConfig{Vocabulary: 5, Dimension: 2, Fields: 2, LearningRate: .1},
+		Hidden:    []LayerConfig{{Size: 3, LearningRate: .1, Bias: 1, Activation: "sigmoid"}},
+		Output:    LayerConfig{Size: 2, Activation: "sigmoid", Cost: "quadratic"},
+	})
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	if err = ExportONNX(new(bytes.Buffer), embedded); err == nil {
+		t.Error("ExportONNX() of an embedding network error = nil")
+	}
+}
+
+// denseGraph is an ONNX graph of two dense connections of inputs of size 2, the first one
+// of Gemm with transposed weights and the second one of MatMul and Add.
+func denseGraph(hiddenOp, outputOp string) onnxGraph {
+	return onnxGraph{
+		name: "test",
+		nodes: []onnxNode{
+			{name: "fc1", opType: "Gemm", inputs: []string{"x", "w1", "b1"}, outputs: []string{"h"}, attributes: []onnxAttribute{
+				{name: "transB", kind: onnxAttributeInt, i: 1},
+				{name: "alpha", kind: onnxAttributeFloat, f: 2},
+			}},
+			{name: "act1", opType: hiddenOp, inputs: []string{"h"}, outputs: []string{"a"}},
+			{name: "fc2", opType: "MatMul", inputs: []string{"a", "w2"}, outputs: []string{"m"}},
+			{name: "add2", opType: "Add", inputs: []string{"b2", "m"}, outputs: []string{"z"}},
+			{name: "act2", opType: outputOp, inputs: []string{"z"}, outputs: []string{"y"}},
+		},
+		initializers: []onnxTensor{
+			// Weights of 3 hidden neurons by rows.
+			{name: "w1", dims: []int64{3, 2}, data: []float64{.5, -.5, .25, .25, -1, 0}},
+			{name: "b1", dims: []int64{3}, data: []float64{0, .5, 1}},
+			{name: "w2", dims: []int64{3, 1}, data: []float64{1, -1, .5}},
+			{name: "b2", dims: []int64{1, 1}, data: []float64{-.25}},
+		},
+		inputs:  []onnxValue{{name: "x", dims: []int64{-1, 2}}, {name: "w1", dims: []int64{3, 2}}},
+		outputs: []onnxValue{{name: "y", dims: []int64{-1, 1}}},
+	}
+}
+
+func TestImportONNX(t *testing.T) {
+	n, err := ImportONNX(bytes.NewReader(denseGraph("Relu", "Sigmoid").marshalModel()), .1, "quadratic")
+	if err != nil {
+		t.Fatalf("ImportONNX() error = %v", err)
+	}
+	x := []float64{1, 2}
+	hidden := []float64{
+		math.Max(0, 2*(.5*x[0]-.5*x[1])+0),
+		math.Max(0, 2*(.25*x[0]+.25*x[1])+.5),
+		math.Max(0, 2*(-1*x[0])+1),
+	}
+	z := hidden[0] - hidden[1] + .5*hidden[2] - .25
+	want := rowsOf([][]float64{{1 / (1 + math.Exp(-z))}})
+
+	got, err := n.Recognize(rowsOf([][]float64{{x[0], x[1], 0}}))
+	if err != nil {
+		t.Fatalf("Perceptron.Recognize() error = %v", err)
+	}
+	assertPredictions(t, got, want, 1e-6)
+}
+
+func TestImportONNX_unsupported(t *testing.T) {
+	threeLayers := denseGraph("Relu", "Sigmoid")
+	threeLayers.nodes[4].outputs = []string{"s"}
+	threeLayers.nodes = append(threeLayers.nodes,
+		onnxNode{name: "fc3", opType: "Gemm", inputs: []string{"s", "w3"}, outputs: []string{"t"}},
+		onnxNode{name: "act3", opType: "Sigmoid", inputs: []string{"t"}, outputs: []string{"y"}},
+	)
+	threeLayers.initializers = append(threeLayers.initializers, onnxTensor{name: "w3", dims: []int64{1, 1}, data: []float64{1}})
+	malformed := func(edit func(g *onnxGraph)) []byte {
+		g := denseGraph("Relu", "Sigmoid")
+		edit(&g)
+		return g.marshalModel()
+	}
+
+	tests := []struct {
+		name       string
+		model      []byte
+		wantErr    string
+		wantConfig bool
+	}{
+		{name: "activation", model: denseGraph("LeakyRelu", "Sigmoid").marshalModel(), wantErr: "LeakyRelu"},
+		{name: "noActivation", model: denseGraph("Relu", "Identity").marshalModel(), wantErr: "Identity"},
+		{name: "threeLayers", model: threeLayers.marshalModel(), wantErr: "3 dense connections"},
+		{name: "truncated", model: denseGraph("Relu", "Sigmoid").marshalModel()[:40], wantErr: "truncated"},
+		{
+			name:       "gemmWithoutOutput",
+			model:      malformed(func(g *onnxGraph) { g.nodes[0].outputs = nil }),
+			wantErr:    "fc1",
+			wantConfig: true,
+		},
+		{
+			name:       "matMulWithoutWeights",
+			model:      malformed(func(g *onnxGraph) { g.nodes[2].inputs = []string{"a"} }),
+			wantErr:    "fc2",
+			wantConfig: true,
+		},
+		{
+			name:       "addWithoutBias",
+			model:      malformed(func(g *onnxGraph) { g.nodes[3].inputs = []string{"m"} }),
+			wantErr:    "add2",
+			wantConfig: true,
+		},
+		{
+			name:       "activationWithoutOutput",
+			model:      malformed(func(g *onnxGraph) { g.nodes[1].outputs = nil }),
+			wantErr:    "act1",
+			wantConfig: true,
+		},
+		{
+			name:       "negativeDims",
+			model:      malformed(func(g *onnxGraph) { g.initializers[2].dims = []int64{-3, -1} }),
+			wantErr:    "w2.dims",
+			wantConfig: true,
+		},
+		{
+			name: "overflowingDims",
+			// A product of dimensions wraps around to a number of values.
+			model: malformed(func(g *onnxGraph) {
+				g.initializers[2].dims, g.initializers[2].data = []int64{math.MaxInt64, math.MaxInt64}, []float64{1}
+			}),
+			wantErr: "w2 has 1 values",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ImportONNX(bytes.NewReader(tt.model), .1, "quadratic")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ImportONNX() error = %v, want it containing %q", err, tt.wantErr)
+			}
+			var configErr *ConfigError
+			if tt.wantConfig && !errors.As(err, &configErr) {
+				t.Errorf("ImportONNX() error = %v, want *ConfigError", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,285 @@
+package goDeep
+
+import (
+	"math"
+)
+
+// Element-wise operations, operands are broadcast.
+
+type addOp struct{}
+
+func (addOp) forward(inputs ...*Tensor) (*Tensor, error) {
+	return inputs[0].Add(inputs[1])
+}
+
+func (addOp) gradient(grad, output *Tensor, inputs ...*Tensor) ([]*Tensor, error) {
+	return reduceGrads(inputs, grad, grad)
+}
+
+type subOp struct{}
+
+func (subOp) forward(inputs ...*Tensor) (*Tensor, error) {
+	return inputs[0].Sub(inputs[1])
+}
+
+func (subOp) gradient(grad, output *Tensor, inputs ...*Tensor) ([]*Tensor, error) {
+	return reduceGrads(inputs, grad, grad.Apply(func(g float64) float64 { return -g }))
+}
+
+type mulOp struct{}
+
+func (mulOp) forward(inputs ...*Tensor) (*Tensor, error) {
+	return inputs[0].Mul(inputs[1])
+}
+
+func (mulOp) gradient(grad, output *Tensor, inputs ...*Tensor) ([]*Tensor, error) {
+	left, err := grad.Mul(inputs[1])
+	if err != nil {
+		return nil, err
+	}
+	right, err := grad.Mul(inputs[0])
+	if err != nil {
+		return nil, err
+	}
+	return reduceGrads(inputs, left, right)
+}
+
+type divOp struct{}
+
+func (divOp) forward(inputs ...*Tensor) (*Tensor, error) {
+	return inputs[0].Div(inputs[1])
+}
+
+func (divOp) gradient(grad, output *Tensor, inputs ...*Tensor) ([]*Tensor, error) {
+	left, err := grad.Div(inputs[1])
+	if err != nil {
+		return nil, err
+	}
+	right, err := left.Mul(output)
+	if err != nil {
+		return nil, err
+	}
+	return reduceGrads(inputs, left, right.Apply(func(g float64) float64 { return -g }))
+}
+
+// reduceGrads sums gradients of broadcast operands back to the operands shapes.
+func reduceGrads(inputs []*Tensor, grads ...*Tensor) (_ []*Tensor, err error) {
+	for i, g := range grads {
+		if grads[i], err = g.reduceTo(inputs[i].shape); err != nil {
+			return nil, err
+		}
+	}
+	return grads, nil
+}
+
+// mapOp applies a scalar function with a known derivative to every element.
+type mapOp struct {
+	fn, derivative func(float64) (float64, error)
+}
+
+func (o mapOp) forward(inputs ...*Tensor) (*Tensor, error) {
+	return inputs[0].applyErr(o.fn)
+}
+
+func (o mapOp) gradient(grad, output *Tensor, inputs ...*Tensor) ([]*Tensor, error) {
+	der, err := inputs[0].applyErr(o.derivative)
+	if err != nil {
+		return nil, err
+	}
+	in, err := grad.Mul(der)
+	return []*Tensor{in}, err
+}
+
+func activationOp(a activation) operation {
+	if l, ok := a.(layerActivation); ok {
+		return l.layerOp()
+	}
+	return mapOp{a.activate, a.actDerivative}
+}
+
+// softmaxOp normalizes exponents of a vector to sum to one.
+type softmaxOp struct{}
+
+func (softmaxOp) forward(inputs ...*Tensor) (*Tensor, error) {
+	// Shifted by a maximum, exponents do not overflow.
+	max := inputs[0].Max()
+	exp := inputs[0].Apply(func(x float64) float64 { return math.Exp(x - max) })
+	sum := exp.Sum()
+	return exp.Apply(func(e float64) float64 { return e / sum }), nil
+}
+
+func (softmaxOp) gradient(grad, output *Tensor, inputs ...*Tensor) ([]*Tensor, error) {
+	weighted, err := grad.Mul(output)
+	if err != nil {
+		return nil, err
+	}
+	dot := weighted.Sum()
+	shifted := grad.Apply(func(g float64) float64 { return g - dot })
+	in, err := shifted.Mul(output)
+	return []*Tensor{in}, err
+}
+
+var (
+	expOp = mapOp{
+		func(x float64) (float64, error) { return math.Exp(x), nil },
+		func(x float64) (float64, error) { return math.Exp(x), nil },
+	}
+	logOp = mapOp{
+		func(x float64) (float64, error) { return math.Log(x), nil },
+		func(x float64) (float64, error) { return 1 / x, nil },
+	}
+	squareOp = mapOp{
+		func(x float64) (float64, error) { return x * x, nil },
+		func(x float64) (float64, error) { return 2 * x, nil },
+	}
+	reciprocalOp = mapOp{
+		func(x float64) (float64, error) { return 1 / x, nil },
+		func(x float64) (float64, error) { return -1 / (x * x), nil },
+	}
+)
+
+func scaleOp(c float64) mapOp {
+	return mapOp{
+		func(x float64) (float64, error) { return c * x, nil },
+		func(float64) (float64, error) { return c, nil },
+	}
+}
+
+func shiftOp(c float64) mapOp {
+	return mapOp{
+		func(x float64) (float64, error) { return x + c, nil },
+		func(float64) (float64, error) { return 1, nil },
+	}
+}
+
+// Structural operations.
+
+type transposeOp struct{}
+
+func (transposeOp) forward(inputs ...*Tensor) (*Tensor, error) {
+	return inputs[0].Transpose(), nil
+}
+
+func (transposeOp) gradient(grad, output *Tensor, inputs ...*Tensor) ([]*Tensor, error) {
+	return []*Tensor{grad.Transpose()}, nil
+}
+
+type matMulOp struct{}
+
+func (matMulOp) forward(inputs ...*Tensor) (*Tensor, error) {
+	return inputs[0].MatMul(inputs[1])
+}
+
+func (matMulOp) gradient(grad, output *Tensor, inputs ...*Tensor) ([]*Tensor, error) {
+	left, err := grad.MatMul(inputs[1].Transpose())
+	if err != nil {
+		return nil, err
+	}
+	right, err := inputs[0].Transpose().MatMul(grad)
+	return []*Tensor{left, right}, err
+}
+
+// sumAxisOp sums elements along a dimension removing it.
+type sumAxisOp struct {
+	dim int
+}
+
+func (o sumAxisOp) forward(inputs ...*Tensor) (*Tensor, error) {
+	return inputs[0].SumAxis(o.dim)
+}
+
+func (o sumAxisOp) gradient(grad, output *Tensor, inputs ...*Tensor) ([]*Tensor, error) {
+	shape := inputs[0].Shape()
+	shape[o.dim] = 1
+	kept, err := grad.Reshape(shape...)
+	if err != nil {
+		return nil, err
+	}
+	spread, err := kept.broadcastTo(inputs[0].shape)
+	if err != nil {
+		return nil, err
+	}
+	return []*Tensor{spread.Clone()}, nil
+}
+
+// sumOp sums all the elements into a scalar.
+type sumOp struct{}
+
+func (sumOp) forward(inputs ...*Tensor) (*Tensor, error) {
+	return Scalar(inputs[0].Sum()), nil
+}
+
+func (sumOp) gradient(grad, output *Tensor, inputs ...*Tensor) ([]*Tensor, error) {
+	spread, err := grad.broadcastTo(inputs[0].shape)
+	if err != nil {
+		return nil, err
+	}
+	return []*Tensor{spread.Clone()}, nil
+}
+
+// concatOp joins tensors along the first dimension.
+type concatOp struct{}
+
+func (concatOp) forward(inputs ...*Tensor) (*Tensor, error) {
+	var data []float64
+	shape := inputs[0].Shape()
+	if len(shape) == 0 {
+		return nil, &ShapeMismatchError{Op: "Concat", Expected: []int{-1}, Actual: shape}
+	}
+	shape[0] = 0
+	for _, in := range inputs {
+		if len(in.shape) != len(shape) || !sameShape(in.shape[1:], shape[1:]) {
+			return nil, &ShapeMismatchError{Op: "Concat", Expected: inputs[0].Shape(), Actual: in.Shape()}
+		}
+		shape[0] += in.shape[0]
+		data = append(data, in.Data()...)
+	}
+	return newTensor(data, shape), nil
+}
+
+func (concatOp) gradient(grad, output *Tensor, inputs ...*Tensor) ([]*Tensor, error) {
+	grads := make([]*Tensor, len(inputs))
+	var offset int
+	for i, in := range inputs {
+		part, err := grad.Slice(0, offset, offset+in.shape[0])
+		if err != nil {
+			return nil, err
+		}
+		grads[i] = part
+		offset += in.shape[0]
+	}
+	return grads, nil
+}
+
+// costOp measures a cost of a prediction against labels.
+type costOp struct {
+	cost
+}
+
+func (o costOp) forward(inputs ...*Tensor) (*Tensor, error) {
+	if !sameShape(inputs[0].shape, inputs[1].shape) {
+		return nil, &ShapeMismatchError{Op: "Cost", Expected: inputs[0].Shape(), Actual: inputs[1].Shape()}
+	}
+	return Scalar(o.countCost(inputs[0].Data(), inputs[1].Data())), nil
+}
+
+func (o costOp) gradient(grad, output *Tensor, inputs ...*Tensor) ([]*Tensor, error) {
+	g := grad.Data()[0]
+	labels := inputs[1].Data()
+	pred := make([]float64, 0, len(labels))
+	for i, a := range inputs[0].Data() {
+		pred = append(pred, g*o.costDerivative(a, labels[i]))
+	}
+	// Labels are not differentiable.
+	return []*Tensor{newTensor(pred, inputs[0].shape), nil}, nil
+}
+
+// weigh builds a dense connection: every signal of a current layer multiplied by each
+// of its synapses, a row per a next layer neuron.
+func weigh(synapses, signal *variable) (*variable, error) {
+	transposed, err := apply(transposeOp{}, synapses)
+	if err != nil {
+		return nil, err
+	}
+	return apply(mulOp{}, transposed, signal)
+}
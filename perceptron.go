@@ -1,130 +1,401 @@
 package goDeep
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+)
 
 /*
 Perceptron is MLP implementation of a Network interface.
 */
 type Perceptron struct {
+	shapes networkShapes
 	input  inputLayer
 	hidden []hiddenLayer
 	output outputLayer
+	hooks  *layerHooks
 }
 
-func (n *Perceptron) backward(prediction []float64, labels []float64) (err error) {
-	var backpropErrs []float64
+// networkShapes a network is declared by, an input is dense or embedding one.
+type networkShapes struct {
+	input     *InputShape
+	embedding *EmbeddingShape
+	hidden    []HiddenShape
+	output    OutputShape
+}
+
+func (n *Perceptron) backward(prediction, labels *Tensor) (err error) {
+	var backpropErrs *Tensor
 
 	backpropErrs, err = n.output.backward(prediction, labels)
 	if err != nil {
-		return err
+		return atLayer(err, n.outputLayer())
 	}
 
-	for _, l := range n.hidden {
+	for i, l := range n.hidden {
 		backpropErrs, err = l.backward(backpropErrs)
 		if err != nil {
-			return err
+			return atLayer(err, i+2)
 		}
 	}
-	return n.input.backward(backpropErrs)
+	return atLayer(n.input.backward(backpropErrs), 1)
 }
 
 func (n *Perceptron) applyCorrections(batchSize float64) (err error) {
-	for _, l := range n.hidden {
+	for i, l := range n.hidden {
 		if err = l.applyCorrections(batchSize); err != nil {
-			return
+			return atLayer(err, i+2)
 		}
 	}
-	return n.input.applyCorrections(batchSize)
+	return atLayer(n.input.applyCorrections(batchSize), 1)
 }
 
-// Learn generalization of back propagation for all layers defined in the network
-func (n *Perceptron) Learn(set, labels [][]float64, epochs, batchSize int) (costGradient []float64, err error) {
-	// Use Recognize loop to get recognition results and hidden layer intermediate results.
-	// Loop backward using obtained results for learning
-	var batchCounter int
-	var localCost float64
+func (n *Perceptron) discardCorrections() {
+	for _, l := range n.hidden {
+		l.discardCorrections()
+	}
+	n.input.discardCorrections()
+}
 
-	for j := 0; j < epochs; j++ {
-		fmt.Printf("Epochs: %d\n", j+1)
-		for i, v := range set {
-			if batchCounter >= batchSize {
-				if err = n.applyCorrections(float64(batchSize)); err != nil {
-					return
-				}
-				costGradient = append(costGradient, localCost/float64(batchSize))
-				batchCounter = 0
-				localCost = 0
+// parameters of all the layers, named after a layer.
+func (n *Perceptron) parameters() (params []parameter) {
+	for _, layer := range n.layerParameters() {
+		params = append(params, layer...)
+	}
+	return
+}
+
+// layerNames of layers from input to output.
+func (n *Perceptron) layerNames() []string {
+	return append(append([]string{"input"}, hiddenNames(len(n.hidden))...), "output")
+}
+
+// layerHooks of a network, nil if a network is not made by newPerceptron.
+func (n *Perceptron) layerHooks() *layerHooks {
+	return n.hooks
+}
+
+// installHooks into layers of a network once it is made.
+func (n *Perceptron) installHooks() {
+	n.hooks = &layerHooks{batch: -1}
+	n.input.setHook(func(signals *Tensor) error {
+		return n.hooks.forward("input", signals)
+	})
+	for i, l := range n.hidden {
+		name := fmt.Sprintf("hidden[%d]", i)
+		l.setHook(func(activated *Tensor) error {
+			return n.hooks.forward(name, activated)
+		})
+	}
+}
+
+// layerParameters are parameters grouped by layers.
+func (n *Perceptron) layerParameters() [][]parameter {
+	input := n.input.parameters()
+	for i := range input {
+		input[i].name = "input." + input[i].name
+	}
+	layers := [][]parameter{input}
+	for i, l := range n.hidden {
+		params := l.parameters()
+		for j := range params {
+			params[j].name = fmt.Sprintf("hidden[%d].%s", i, params[j].name)
+		}
+		layers = append(layers, params)
+	}
+	return layers
+}
+
+// rates of learning of all the layers.
+func (n *Perceptron) rates() []float64 {
+	rates := []float64{n.input.rate()}
+	for _, l := range n.hidden {
+		rates = append(rates, l.rate())
+	}
+	return rates
+}
+
+// setRates of learning of all the layers to base rates scaled by a factor.
+func (n *Perceptron) setRates(base []float64, factor float64) {
+	n.input.setRate(base[0] * factor)
+	for i, l := range n.hidden {
+		l.setRate(base[i+1] * factor)
+	}
+}
+
+/*
+Learn generalization of back propagation for all layers defined in the network.
+
+Corrections are applied after every batch, the last batch of an epoch may be incomplete.
+Learning rates of layers are restored after learning if a schedule changed them.
+*/
+func (n *Perceptron) Learn(set, labels *Tensor, epochs, batchSize int, options ...LearnOption) (History, error) {
+	return n.LearnContext(context.Background(), set, labels, epochs, batchSize, options...)
+}
+
+/*
+LearnContext is Learn stopped by a context. A context is checked between batches, so
+learning stops with corrections of all the learned batches applied. A history of learned
+batches is returned with an error of a context.
+
+Corrections of a failed batch are discarded.
+*/
+func (n *Perceptron) LearnContext(ctx context.Context, set, labels *Tensor, epochs, batchSize int, options ...LearnOption) (history History, err error) {
+	if batchSize <= 0 {
+		return history, &ConfigError{Field: "batchSize", Value: batchSize, Reason: "must be positive"}
+	}
+	if err = n.checkData(set, labels); err != nil {
+		return
+	}
+	config := newLearnConfig(options)
+	if config.pruning != nil {
+		if err = config.pruning.schedule.validate(); err != nil {
+			return
+		}
+	}
+	if err = checkMultipliers(n.parameters(), config.multipliers); err != nil {
+		return
+	}
+	if config.validationSet != nil {
+		if err = n.checkData(config.validationSet, config.validLabels); err != nil {
+			return
+		}
+	}
+	base := n.rates()
+	defer func() { n.setRates(base, 1) }()
+
+	var epoch, batch, step int
+	if resumed := config.resume; resumed != nil {
+		if err = n.restore(resumed, batchSize, config); err != nil {
+			return
+		}
+		base = resumed.Rates
+		epoch, batch, step = resumed.Epoch, resumed.Batch, resumed.Step
+		history = resumed.History.clone()
+	}
+
+	save := func(epoch, batch int) error {
+		return n.saveCheckpoint(config, &Checkpoint{
+			Epoch:     epoch,
+			Batch:     batch,
+			Step:      step,
+			BatchSize: batchSize,
+			Rates:     base,
+			History:   history,
+		})
+	}
+
+	samples := rowsNumber(set)
+	for ; epoch < epochs; epoch, batch = epoch+1, 0 {
+		fmt.Printf("Epochs: %d\n", epoch+1)
+		order := config.order(epoch, samples)
+		factor := config.rate(epoch)
+		for from := batch * batchSize; from < samples; from += batchSize {
+			if err = ctx.Err(); err != nil {
+				return history, err
 			}
-			prediction, cost, err := n.forwardMeasure(v, labels[i])
-			if err != nil {
-				return nil, err
+			if config.unit == PerBatch {
+				factor = config.rate(step)
 			}
+			n.setRates(base, factor)
 
-			localCost += cost
-			err = n.backward(prediction, labels[i])
+			to := from + batchSize
+			if to > samples {
+				to = samples
+			}
+			cost, clipped, err := n.learnBatch(set, labels, order[from:to], step, config)
 			if err != nil {
-				return nil, err
+				n.discardCorrections()
+				return history, err
+			}
+			history.Cost = append(history.Cost, cost)
+			history.LearningRates = append(history.LearningRates, factor)
+			history.Clipped = append(history.Clipped, clipped)
+			step++
+			if err = config.pruning.prune(n, step); err != nil {
+				return history, err
 			}
 
-			batchCounter++
+			if config.checkpoints.everyBatch(step) {
+				if err = save(epoch, from/batchSize+1); err != nil {
+					return history, err
+				}
+			}
 		}
 
-		// Don't forget to apply correction of the last iteration
-		// in case of incomplete batch.
-		if err = n.applyCorrections(float64(batchCounter)); err != nil {
-			return
+		saved := false
+		if config.validationSet != nil {
+			cost, err := meanCost(n, config.validationSet, config.validLabels)
+			if err != nil {
+				return history, err
+			}
+			history.ValidationCost = append(history.ValidationCost, cost)
+			config.observe(cost)
+			if config.checkpoints.improved(cost) {
+				if err = save(epoch+1, 0); err != nil {
+					return history, err
+				}
+				saved = true
+			}
+		}
+		if !saved && config.checkpoints.everyEpoch(epoch+1) {
+			if err = save(epoch+1, 0); err != nil {
+				return history, err
+			}
 		}
 	}
-	return costGradient, nil
+	return history, nil
 }
 
-func (n *Perceptron) forward(rowInput []float64) ([]float64, error) {
-	var fwdProp [][]float64
-	var err error
+// learnBatch of samples of indices and apply corrections, returns a mean cost of a batch
+// and a number of triggered clippings.
+func (n *Perceptron) learnBatch(set, labels *Tensor, indices []int, batch int, config *learnConfig) (batchCost float64, clipped int, err error) {
+	var v, label, fwdProp, prediction *Tensor
+	var cost float64
+	guard := config.guard
+	hooks := n.layerHooks()
+	defer hooks.at(-1, 0)
+	for _, i := range indices {
+		hooks.at(batch, i)
+		if v, err = set.Index(i); err != nil {
+			return
+		}
+		if label, err = labels.Index(i); err != nil {
+			return
+		}
+		if guard == nil {
+			prediction, cost, err = n.forwardMeasure(v, label)
+		} else if fwdProp, err = n.propagate(v, guard.inspector(batch, v)); err == nil {
+			if prediction, cost, err = n.output.forwardMeasure(fwdProp, label); err == nil {
+				if err = hooks.forward("output", prediction); err == nil {
+					err = guard.activations(n.outputLayer(), prediction, batch, v)
+				}
+			}
+			err = atLayer(err, n.outputLayer())
+		}
+		if err != nil {
+			return
+		}
+		batchCost += cost
+		if err = n.backward(prediction, label); err != nil {
+			return
+		}
+	}
 
-	fwdProp, err = n.input.forward(rowInput)
-	if err != nil {
-		return nil, err
+	batchSize := float64(len(indices))
+	if err = hooks.backward(n.parameters()); err != nil {
+		return
+	}
+	if guard != nil {
+		if err = guard.gradients(n.layerParameters(), batch, set, indices); err != nil {
+			return
+		}
+	}
+	if config.clipping != nil {
+		clipped = config.clipping.clip(n.layerParameters(), batchSize)
+	}
+	if config.multipliers != nil {
+		scaleCorrections(n.parameters(), config.multipliers)
 	}
+	if err = n.applyCorrections(batchSize); err != nil {
+		return
+	}
+	if guard != nil {
+		if err = guard.weights(n.layerParameters(), batch, set, indices); err != nil {
+			return
+		}
+	}
+	return batchCost / batchSize, clipped, nil
+}
 
-	for _, l := range n.hidden {
-		fwdProp, err = l.forward(fwdProp)
-		if err != nil {
+// propagate a sample through input and hidden layers. An output of every layer is passed
+// to an inspect function if it is given.
+func (n *Perceptron) propagate(rowInput *Tensor, inspect func(layer int, output *Tensor) error) (fwdProp *Tensor, err error) {
+	if fwdProp, err = n.input.forward(rowInput); err != nil {
+		return nil, atLayer(err, 1)
+	}
+	if inspect != nil {
+		if err = inspect(1, fwdProp); err != nil {
 			return nil, err
 		}
 	}
-	return n.output.forward(fwdProp)
+
+	for i, l := range n.hidden {
+		if fwdProp, err = l.forward(fwdProp); err != nil {
+			return nil, atLayer(err, i+2)
+		}
+		if inspect != nil {
+			if err = inspect(i+2, fwdProp); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return
 }
 
-func (n *Perceptron) forwardMeasure(rowInput, labels []float64) (prediction []float64, cost float64, err error) {
-	var fwdProp [][]float64
+// outputLayer is a number of the output layer counted from one at the input layer.
+func (n *Perceptron) outputLayer() int {
+	return len(n.hidden) + 2
+}
+
+func (n *Perceptron) forward(rowInput *Tensor) (*Tensor, error) {
+	fwdProp, err := n.propagate(rowInput, nil)
+	if err != nil {
+		return nil, err
+	}
+	output, err := n.output.forward(fwdProp)
+	if err == nil {
+		err = n.layerHooks().forward("output", output)
+	}
+	return output, atLayer(err, n.outputLayer())
+}
 
-	fwdProp, err = n.input.forward(rowInput)
+func (n *Perceptron) forwardMeasure(rowInput, labels *Tensor) (prediction *Tensor, cost float64, err error) {
+	fwdProp, err := n.propagate(rowInput, nil)
 	if err != nil {
 		return nil, 0, err
 	}
+	prediction, cost, err = n.output.forwardMeasure(fwdProp, labels)
+	if err == nil {
+		err = n.layerHooks().forward("output", prediction)
+	}
+	return prediction, cost, atLayer(err, n.outputLayer())
+}
 
-	for _, l := range n.hidden {
-		fwdProp, err = l.forward(fwdProp)
-		if err != nil {
-			return nil, 0, err
-		}
+// checkData dimensions: a sample and a label per row. Labels are not checked if nil.
+func (n *Perceptron) checkData(set, labels *Tensor) error {
+	if shape := []int{rowsNumber(set), n.input.sampleSize()}; set.Dims() != 2 || !sameShape(set.Shape(), shape) {
+		return &ShapeMismatchError{Op: "Set", Layer: 1, Expected: shape, Actual: set.Shape()}
+	}
+	if labels == nil {
+		return nil
 	}
-	return n.output.forwardMeasure(fwdProp, labels)
+	if shape := []int{rowsNumber(set), n.output.labelSize()}; !sameShape(labels.Shape(), shape) {
+		return &ShapeMismatchError{Op: "Labels", Layer: n.outputLayer(), Expected: shape, Actual: labels.Shape()}
+	}
+	return nil
 }
 
 // Recognize is a generalization of forward propagation for all layers defined in the network
-func (n *Perceptron) Recognize(set [][]float64) (prediction [][]float64, err error) {
-	var pred []float64
+func (n *Perceptron) Recognize(set *Tensor) (prediction *Tensor, err error) {
+	if err = n.checkData(set, nil); err != nil {
+		return
+	}
+	var v *Tensor
+	preds := make([]*Tensor, rowsNumber(set))
 
-	for _, v := range set {
-		pred, err = n.forward(v)
-		if err != nil {
+	for i := range preds {
+		if v, err = set.Index(i); err != nil {
+			return nil, err
+		}
+		n.layerHooks().at(-1, i)
+		if preds[i], err = n.forward(v); err != nil {
 			return nil, err
 		}
-		prediction = append(prediction, pred)
 	}
-	return
+	return Stack(preds...)
 }
 
 // InputShape is an intuitive input layer representation. Designed to
@@ -150,27 +421,118 @@ type OutputShape struct {
 	Cost       cost
 }
 
-// NewPerceptron is a MLP initializer
-func NewPerceptron(inputShape InputShape, hiddenShapes []HiddenShape, outputShape OutputShape) Network {
-	return &Perceptron{
-		input: newInputDense(
-			inputShape.Size,
-			hiddenShapes[0].Size,
-			inputShape.LearningRate,
-			inputShape.Bias,
-			hiddenShapes[0].Bias != 0,
-		),
+// validate sizes, learning rate and bias of an input layer.
+func (s InputShape) validate() error {
+	if err := validateSize("InputShape.Size", s.Size, s.Bias); err != nil {
+		return err
+	}
+	return validateRates("InputShape", s.LearningRate, s.Bias)
+}
+
+// validate sizes, learning rate, bias and activation of a hidden layer. A network
+// has a single hidden layer for now.
+func validateHiddenShapes(hiddenShapes []HiddenShape) error {
+	if len(hiddenShapes) != 1 {
+		return &ConfigError{Field: "hiddenShapes", Value: len(hiddenShapes), Reason: "a single hidden layer is supported"}
+	}
+	s := hiddenShapes[0]
+	if err := validateSize("HiddenShape[0].Size", s.Size, s.Bias); err != nil {
+		return err
+	}
+	if err := validateRates("HiddenShape[0]", s.LearningRate, s.Bias); err != nil {
+		return err
+	}
+	if s.Activation == nil {
+		return &ConfigError{Field: "HiddenShape[0].Activation", Value: nil, Reason: "is required"}
+	}
+	return nil
+}
+
+// validate size, activation and cost of an output layer.
+func (s OutputShape) validate() error {
+	if s.Size <= 0 {
+		return &ConfigError{Field: "OutputShape.Size", Value: s.Size, Reason: "must be positive"}
+	}
+	if s.Activation == nil {
+		return &ConfigError{Field: "OutputShape.Activation", Value: nil, Reason: "is required"}
+	}
+	if s.Cost == nil {
+		return &ConfigError{Field: "OutputShape.Cost", Value: nil, Reason: "is required"}
+	}
+	return nil
+}
+
+// validateSize of a layer, a size includes a bias neuron if a layer has a bias.
+func validateSize(field string, size int, bias float64) error {
+	minSize := 1
+	if bias != 0 {
+		minSize++
+	}
+	if size < minSize {
+		return &ConfigError{Field: field, Value: size, Reason: fmt.Sprintf("must be at least %d", minSize)}
+	}
+	return nil
+}
+
+func validateRates(shape string, learningRate, bias float64) error {
+	if !(learningRate > 0) || math.IsInf(learningRate, 0) {
+		return &ConfigError{Field: shape + ".LearningRate", Value: learningRate, Reason: "must be positive and finite"}
+	}
+	if math.IsNaN(bias) || math.IsInf(bias, 0) {
+		return &ConfigError{Field: shape + ".Bias", Value: bias, Reason: "must be finite"}
+	}
+	return nil
+}
+
+// NewPerceptron is a MLP initializer. Shapes are validated, a ConfigError reports
+// an invalid field.
+func NewPerceptron(inputShape InputShape, hiddenShapes []HiddenShape, outputShape OutputShape) (Network, error) {
+	return newDensePerceptron(inputShape, hiddenShapes, outputShape, newRandom(0))
+}
+
+// newDensePerceptron of initial synapses drawn from a source.
+func newDensePerceptron(inputShape InputShape, hiddenShapes []HiddenShape, outputShape OutputShape, r *rand.Rand) (*Perceptron, error) {
+	if err := inputShape.validate(); err != nil {
+		return nil, err
+	}
+	if err := validateHiddenShapes(hiddenShapes); err != nil {
+		return nil, err
+	}
+	if err := outputShape.validate(); err != nil {
+		return nil, err
+	}
+
+	input := newInputDense(
+		inputShape.Size,
+		hiddenShapes[0].Size,
+		inputShape.LearningRate,
+		inputShape.Bias,
+		hiddenShapes[0].Bias != 0,
+		r,
+	)
+	n := newPerceptron(input, inputShape.Size, hiddenShapes, outputShape, r)
+	n.shapes.input = &inputShape
+	return n, nil
+}
+
+func newPerceptron(input inputLayer, inputSize int, hiddenShapes []HiddenShape, outputShape OutputShape, r *rand.Rand) *Perceptron {
+	n := &Perceptron{
+		shapes: networkShapes{hidden: hiddenShapes, output: outputShape},
+		input:  input,
 		hidden: []hiddenLayer{
 			newHiddenDense(
-				inputShape.Size,
+				inputSize,
 				hiddenShapes[0].Size,
 				outputShape.Size,
 				hiddenShapes[0].Bias,
 				hiddenShapes[0].LearningRate,
 				hiddenShapes[0].Activation,
 				false,
+				r,
 			),
 		},
 		output: newOutput(hiddenShapes[0].Size, outputShape.Size, outputShape.Activation, outputShape.Cost),
 	}
+	n.installHooks()
+	return n
 }
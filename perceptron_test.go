@@ -1,6 +1,9 @@
 package goDeep
 
 import (
+	"context"
+	"errors"
+	"math"
 	"reflect"
 	"testing"
 )
@@ -12,8 +15,8 @@ func TestPerceptron_backward(t *testing.T) {
 		output outputLayer
 	}
 	type args struct {
-		prediction []float64
-		labels     []float64
+		prediction *Tensor
+		labels     *Tensor
 	}
 	tests := []struct {
 		name    string
@@ -68,15 +71,32 @@ func TestPerceptron_applyCorrections(t *testing.T) {
 	}
 }
 
-func TestPerceptron_Learn(t *testing.T) {
-	type fields struct {
-		input  inputLayer
-		hidden []hiddenLayer
-		output outputLayer
+type perceptronFields struct {
+	input  inputLayer
+	hidden []hiddenLayer
+	output outputLayer
+}
+
+// mockPerceptronFields are layers of a perceptron of two inputs, two hidden neurons
+// and an output, every layer has a bias but the output one.
+func mockPerceptronFields() perceptronFields {
+	n, err := NewPerceptron(
+		InputShape{Size: 3, LearningRate: .1, Bias: 1},
+		[]HiddenShape{{Size: 3, LearningRate: .1, Bias: 1, Activation: new(mockActivation)}},
+		OutputShape{Size: 1, Activation: new(mockActivation), Cost: new(mockCost)},
+	)
+	if err != nil {
+		panic(err)
 	}
+	p := n.(*Perceptron)
+	return perceptronFields{p.input, p.hidden, p.output}
+}
+
+func TestPerceptron_Learn(t *testing.T) {
+	type fields = perceptronFields
 	type args struct {
-		set       [][]float64
-		labels    [][]float64
+		set       *Tensor
+		labels    *Tensor
 		epochs    int
 		batchSize int
 	}
@@ -87,7 +107,28 @@ func TestPerceptron_Learn(t *testing.T) {
 		wantCostGradient []float64
 		wantErr          bool
 	}{
-	// TODO: Add test cases.
+		{
+			name: "incompleteBatch",
+			fields: mockPerceptronFields(),
+			args: args{
+				set:       rowsOf([][]float64{{1, 0, 0}, {0, 1, 0}, {1, 1, 0}}),
+				labels:    rowsOf([][]float64{{0}, {0}, {0}}),
+				epochs:    2,
+				batchSize: 2,
+			},
+			wantCostGradient: []float64{1, 1, 1, 1},
+		},
+		{
+			name:   "wrongSampleSize",
+			fields: mockPerceptronFields(),
+			args: args{
+				set:       rowsOf([][]float64{{1, 0}}),
+				labels:    rowsOf([][]float64{{0}}),
+				epochs:    1,
+				batchSize: 1,
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -96,13 +137,13 @@ func TestPerceptron_Learn(t *testing.T) {
 				hidden: tt.fields.hidden,
 				output: tt.fields.output,
 			}
-			gotCostGradient, err := n.Learn(tt.args.set, tt.args.labels, tt.args.epochs, tt.args.batchSize)
+			gotHistory, err := n.Learn(tt.args.set, tt.args.labels, tt.args.epochs, tt.args.batchSize)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Perceptron.Learn() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if !reflect.DeepEqual(gotCostGradient, tt.wantCostGradient) {
-				t.Errorf("Perceptron.Learn() = %v, want %v", gotCostGradient, tt.wantCostGradient)
+			if !reflect.DeepEqual(gotHistory.Cost, tt.wantCostGradient) {
+				t.Errorf("Perceptron.Learn() = %v, want %v", gotHistory.Cost, tt.wantCostGradient)
 			}
 		})
 	}
@@ -115,13 +156,13 @@ func TestPerceptron_forward(t *testing.T) {
 		output outputLayer
 	}
 	type args struct {
-		rowInput []float64
+		rowInput *Tensor
 	}
 	tests := []struct {
 		name    string
 		fields  fields
 		args    args
-		want    []float64
+		want    *Tensor
 		wantErr bool
 	}{
 		{
@@ -162,14 +203,14 @@ func TestPerceptron_forwardMeasure(t *testing.T) {
 		output outputLayer
 	}
 	type args struct {
-		rowInput []float64
-		labels   []float64
+		rowInput *Tensor
+		labels   *Tensor
 	}
 	tests := []struct {
 		name           string
 		fields         fields
 		args           args
-		wantPrediction []float64
+		wantPrediction *Tensor
 		wantCost       float64
 		wantErr        bool
 	}{
@@ -204,13 +245,13 @@ func TestPerceptron_Recognize(t *testing.T) {
 		output outputLayer
 	}
 	type args struct {
-		set [][]float64
+		set *Tensor
 	}
 	tests := []struct {
 		name           string
 		fields         fields
 		args           args
-		wantPrediction [][]float64
+		wantPrediction *Tensor
 		wantErr        bool
 	}{
 	// TODO: Add test cases.
@@ -233,3 +274,91 @@ func TestPerceptron_Recognize(t *testing.T) {
 		})
 	}
 }
+
+func TestNewPerceptron(t *testing.T) {
+	input := InputShape{Size: 3, LearningRate: .1, Bias: 1}
+	hidden := []HiddenShape{{Size: 3, LearningRate: .1, Bias: 1, Activation: new(Sigmoid)}}
+	output := OutputShape{Size: 1, Activation: new(Sigmoid), Cost: new(Quadratic)}
+	tests := []struct {
+		name      string
+		input     InputShape
+		hidden    []HiddenShape
+		output    OutputShape
+		wantField string
+	}{
+		{name: "valid", input: input, hidden: hidden, output: output},
+		{name: "noHidden", input: input, output: output, wantField: "hiddenShapes"},
+		{name: "biasOnlyInput", input: InputShape{Size: 1, LearningRate: .1, Bias: 1}, hidden: hidden, output: output, wantField: "InputShape.Size"},
+		{name: "zeroLearningRate", input: InputShape{Size: 3, Bias: 1}, hidden: hidden, output: output, wantField: "InputShape.LearningRate"},
+		{name: "infiniteBias", input: input, hidden: []HiddenShape{{Size: 3, LearningRate: .1, Bias: math.Inf(1), Activation: new(Sigmoid)}}, output: output, wantField: "HiddenShape[0].Bias"},
+		{name: "noActivation", input: input, hidden: []HiddenShape{{Size: 3, LearningRate: .1, Bias: 1}}, output: output, wantField: "HiddenShape[0].Activation"},
+		{name: "noCost", input: input, hidden: hidden, output: OutputShape{Size: 1, Activation: new(Sigmoid)}, wantField: "OutputShape.Cost"},
+		{name: "zeroOutput", input: input, hidden: hidden, output: OutputShape{Activation: new(Sigmoid), Cost: new(Quadratic)}, wantField: "OutputShape.Size"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := NewPerceptron(tt.input, tt.hidden, tt.output)
+			if tt.wantField == "" {
+				if err != nil || n == nil {
+					t.Errorf("NewPerceptron() = %v, %v, want a network", n, err)
+				}
+				return
+			}
+			var configErr *ConfigError
+			if !errors.As(err, &configErr) {
+				t.Fatalf("NewPerceptron() error = %v, want *ConfigError", err)
+			}
+			if configErr.Field != tt.wantField {
+				t.Errorf("ConfigError.Field = %v, want %v", configErr.Field, tt.wantField)
+			}
+		})
+	}
+}
+
+// cancelScheduler cancels a context when a rate of a step is requested.
+type cancelScheduler struct {
+	cancel context.CancelFunc
+	step   int
+}
+
+func (s cancelScheduler) Rate(step int) float64 {
+	if step == s.step {
+		s.cancel()
+	}
+	return 1
+}
+
+func TestPerceptron_LearnContext(t *testing.T) {
+	tests := []struct {
+		name        string
+		cancelStep  int
+		wantBatches int
+		wantErr     error
+	}{
+		{name: "cancelled", cancelStep: 1, wantBatches: 2, wantErr: context.Canceled},
+		{name: "completed", cancelStep: -1, wantBatches: 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields := mockPerceptronFields()
+			n := &Perceptron{input: fields.input, hidden: fields.hidden, output: fields.output}
+			set := rowsOf([][]float64{{1, 0, 0}, {0, 1, 0}, {1, 1, 0}})
+			labels := rowsOf([][]float64{{0}, {0}, {0}})
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			history, err := n.LearnContext(ctx, set, labels, 2, 1, WithScheduler(cancelScheduler{cancel, tt.cancelStep}, PerBatch))
+			if err != tt.wantErr {
+				t.Fatalf("Perceptron.LearnContext() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(history.Cost) != tt.wantBatches {
+				t.Errorf("Perceptron.LearnContext() learned %d batches, want %d", len(history.Cost), tt.wantBatches)
+			}
+			for _, p := range n.parameters() {
+				if p.corrections() != nil {
+					t.Errorf("%s has pending corrections after learning", p.name)
+				}
+			}
+		})
+	}
+}
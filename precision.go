@@ -0,0 +1,390 @@
+package goDeep
+
+// Float is a type of tensor elements.
+type Float interface {
+	float32 | float64
+}
+
+/*
+Precision is a type of elements of a tensor or parameters of a network.
+
+Tensors of float32 halve memory of float64 ones. An operation of float32 and float64
+tensors is of float32: float64 operands of a float32 network are data and constants.
+Operands of sums, differences, products and quotients are converted to a precision of
+a result, so float32 arithmetic is native. Other functions, e.g. activations, are computed
+in float64 and rounded to a precision of a result.
+*/
+type Precision string
+
+// Precisions of tensors, the zero precision of a configuration is Float64.
+const (
+	Float64 Precision = "float64"
+	Float32 Precision = "float32"
+)
+
+// storage of tensor elements addressed by data positions.
+type storage interface {
+	at(pos int) float64
+	set(pos int, v float64)
+	precision() Precision
+}
+
+type elements[T Float] []T
+
+func (e elements[T]) at(pos int) float64 {
+	return float64(e[pos])
+}
+
+func (e elements[T]) set(pos int, v float64) {
+	e[pos] = T(v)
+}
+
+func (e elements[T]) precision() Precision {
+	return precisionOf[T]()
+}
+
+func precisionOf[T Float]() Precision {
+	var zero T
+	if _, ok := interface{}(zero).(float32); ok {
+		return Float32
+	}
+	return Float64
+}
+
+// makeStorage of a precision for a number of elements.
+func makeStorage(p Precision, size int) storage {
+	if p == Float32 {
+		return make(elements[float32], size)
+	}
+	return make(elements[float64], size)
+}
+
+// narrowest precision of operands, a precision of an operation result.
+func narrowest(a, b Precision) Precision {
+	if a == Float32 || b == Float32 {
+		return Float32
+	}
+	return Float64
+}
+
+func (p Precision) validate(field string) error {
+	switch p {
+	case "", Float64, Float32:
+		return nil
+	}
+	return &ConfigError{Field: field, Value: p, Reason: "unknown precision, float32 or float64 is"}
+}
+
+// NewTensorOf creates a tensor of a shape over row-major data of a precision of T.
+// Data is not copied.
+func NewTensorOf[T Float](data []T, shape ...int) (*Tensor, error) {
+	t, err := NewTensor(make([]float64, len(data)), shape...)
+	if err != nil {
+		return nil, err
+	}
+	t.data = elements[T](data)
+	return t, nil
+}
+
+// DataOf returns a copy of tensor elements converted to T in a row-major order.
+func DataOf[T Float](t *Tensor) []T {
+	if t == nil {
+		return nil
+	}
+	data := make([]T, t.Size())
+	t.copyTo(elements[T](data))
+	return data
+}
+
+// Precision returns a type of the tensor elements.
+func (t *Tensor) Precision() Precision {
+	if t == nil || t.data == nil {
+		return Float64
+	}
+	return t.data.precision()
+}
+
+// As returns the tensor if it is of a precision or its contiguous copy converted to it.
+func (t *Tensor) As(p Precision) *Tensor {
+	if t.Precision() == p {
+		return t
+	}
+	return t.cloneAs(p)
+}
+
+func (t *Tensor) cloneAs(p Precision) *Tensor {
+	data := makeStorage(p, t.Size())
+	t.copyTo(data)
+	return &Tensor{data: data, shape: t.Shape(), strides: contiguousStrides(t.shape)}
+}
+
+// copyTo contiguous storage of a size of the tensor, elements are converted to its precision.
+func (t *Tensor) copyTo(data storage) {
+	p, ok := planeOf(t)
+	if !ok {
+		var i int
+		t.each(func(pos int) {
+			data.set(i, t.data.at(pos))
+			i++
+		})
+		return
+	}
+	switch d := data.(type) {
+	case elements[float32]:
+		updateWith(assignment, d, t.data, contiguousPlane(p.rows, p.cols), p)
+	case elements[float64]:
+		updateWith(assignment, d, t.data, contiguousPlane(p.rows, p.cols), p)
+	}
+}
+
+// matMul multiplies matrices of the same precision, accumulating products in T.
+func matMul[T Float](t, o *Tensor) storage {
+	a, b := t.data.(elements[T]), o.data.(elements[T])
+	rows, inner, cols := t.shape[0], t.shape[1], o.shape[1]
+	out := make(elements[T], rows*cols)
+	for i := 0; i < rows; i++ {
+		for k := 0; k < inner; k++ {
+			v := a[t.offset+i*t.strides[0]+k*t.strides[1]]
+			if v == 0 {
+				continue
+			}
+			for j := 0; j < cols; j++ {
+				out[i*cols+j] += v * b[o.offset+k*o.strides[0]+j*o.strides[1]]
+			}
+		}
+	}
+	return out
+}
+
+/*
+plane is a layout of a tensor of at most two dimensions: rows of columns of elements at
+data positions offset + i*row + j*col. Kernels of planes are loops over typed elements,
+tensors of more dimensions are walked element by element.
+*/
+type plane struct {
+	rows, cols       int
+	offset, row, col int
+}
+
+// planeOf a tensor, false if a tensor has more than two dimensions.
+func planeOf(t *Tensor) (plane, bool) {
+	switch len(t.shape) {
+	case 0:
+		return plane{rows: 1, cols: 1, offset: t.offset}, true
+	case 1:
+		return plane{rows: 1, cols: t.shape[0], offset: t.offset, col: t.strides[0]}, true
+	case 2:
+		return plane{rows: t.shape[0], cols: t.shape[1], offset: t.offset, row: t.strides[0], col: t.strides[1]}, true
+	}
+	return plane{}, false
+}
+
+// contiguousPlane of rows of columns.
+func contiguousPlane(rows, cols int) plane {
+	return plane{rows: rows, cols: cols, row: cols, col: 1}
+}
+
+// arithmetic of element-wise kernels, assignment replaces an element by an operand.
+type arithmetic int
+
+const (
+	addition arithmetic = iota
+	subtraction
+	multiplication
+	division
+	assignment
+)
+
+func (op arithmetic) of(a, b float64) float64 {
+	switch op {
+	case addition:
+		return a + b
+	case subtraction:
+		return a - b
+	case multiplication:
+		return a * b
+	case division:
+		return a / b
+	}
+	return b
+}
+
+// zipPlanes of operands converted to T into a contiguous plane of rows of a.
+func zipPlanes[T, A, B Float](op arithmetic, out []T, a []A, b []B, pa, pb plane) {
+	for i := 0; i < pa.rows; i++ {
+		o, x, y := out[i*pa.cols:(i+1)*pa.cols], a[pa.offset+i*pa.row:], b[pb.offset+i*pb.row:]
+		switch op {
+		case addition:
+			for j := range o {
+				o[j] = T(x[j*pa.col]) + T(y[j*pb.col])
+			}
+		case subtraction:
+			for j := range o {
+				o[j] = T(x[j*pa.col]) - T(y[j*pb.col])
+			}
+		case multiplication:
+			for j := range o {
+				o[j] = T(x[j*pa.col]) * T(y[j*pb.col])
+			}
+		case division:
+			for j := range o {
+				o[j] = T(x[j*pa.col]) / T(y[j*pb.col])
+			}
+		default:
+			for j := range o {
+				o[j] = T(y[j*pb.col])
+			}
+		}
+	}
+}
+
+// zipStorage of planes of storages of any precision into elements of T.
+func zipStorage[T Float](op arithmetic, a, b storage, pa, pb plane) elements[T] {
+	out := make(elements[T], pa.rows*pa.cols)
+	switch x := a.(type) {
+	case elements[float32]:
+		zipWith(op, out, x, b, pa, pb)
+	case elements[float64]:
+		zipWith(op, out, x, b, pa, pb)
+	}
+	return out
+}
+
+func zipWith[T, A Float](op arithmetic, out []T, a []A, b storage, pa, pb plane) {
+	switch y := b.(type) {
+	case elements[float32]:
+		zipPlanes(op, out, a, y, pa, pb)
+	case elements[float64]:
+		zipPlanes(op, out, a, y, pa, pb)
+	}
+}
+
+// updatePlanes of dst by an operand converted to T in place.
+func updatePlanes[T, S Float](op arithmetic, dst []T, src []S, pd, ps plane) {
+	for i := 0; i < pd.rows; i++ {
+		d, s := dst[pd.offset+i*pd.row:], src[ps.offset+i*ps.row:]
+		switch op {
+		case addition:
+			for j := 0; j < pd.cols; j++ {
+				d[j*pd.col] += T(s[j*ps.col])
+			}
+		case subtraction:
+			for j := 0; j < pd.cols; j++ {
+				d[j*pd.col] -= T(s[j*ps.col])
+			}
+		case multiplication:
+			for j := 0; j < pd.cols; j++ {
+				d[j*pd.col] *= T(s[j*ps.col])
+			}
+		case division:
+			for j := 0; j < pd.cols; j++ {
+				d[j*pd.col] /= T(s[j*ps.col])
+			}
+		default:
+			for j := 0; j < pd.cols; j++ {
+				d[j*pd.col] = T(s[j*ps.col])
+			}
+		}
+	}
+}
+
+func updateWith[T Float](op arithmetic, dst []T, src storage, pd, ps plane) {
+	switch s := src.(type) {
+	case elements[float32]:
+		updatePlanes(op, dst, s, pd, ps)
+	case elements[float64]:
+		updatePlanes(op, dst, s, pd, ps)
+	}
+}
+
+// updateInPlace applies an arithmetic of a tensor of the same shape to elements of
+// a tensor, false if tensors are not planes.
+func updateInPlace(op arithmetic, t, o *Tensor) bool {
+	pt, ok := planeOf(t)
+	po, planar := planeOf(o)
+	if !ok || !planar {
+		return false
+	}
+	switch d := t.data.(type) {
+	case elements[float32]:
+		updateWith(op, d, o.data, pt, po)
+	case elements[float64]:
+		updateWith(op, d, o.data, pt, po)
+	}
+	return true
+}
+
+// sumPlane of a plane along rows into a sum per column, or along columns into a sum per row.
+func sumPlane[T Float](out, a []T, p plane, alongRows bool) {
+	for i := 0; i < p.rows; i++ {
+		x := a[p.offset+i*p.row:]
+		if alongRows {
+			for j := range out {
+				out[j] += x[j*p.col]
+			}
+			continue
+		}
+		var sum T
+		for j := 0; j < p.cols; j++ {
+			sum += x[j*p.col]
+		}
+		out[i] = sum
+	}
+}
+
+// mapPlane of a function of elements converted to float64, returns a number of mapped
+// elements and an error stopping mapping.
+func mapPlane[T Float](out, a []T, p plane, fn func(float64) (float64, error)) (int, error) {
+	for i := 0; i < p.rows; i++ {
+		x := a[p.offset+i*p.row:]
+		for j := 0; j < p.cols; j++ {
+			v, err := fn(float64(x[j*p.col]))
+			if err != nil {
+				return i*p.cols + j, err
+			}
+			out[i*p.cols+j] = T(v)
+		}
+	}
+	return p.rows * p.cols, nil
+}
+
+// descendPlane of parameters by corrections scaled by a rate, computed in float64.
+func descendPlane[T Float](dst, corrections []T, pd, pc plane, rate, batchSize float64) {
+	for i := 0; i < pd.rows; i++ {
+		d, c := dst[pd.offset+i*pd.row:], corrections[pc.offset+i*pc.row:]
+		for j := 0; j < pd.cols; j++ {
+			d[j*pd.col] = T(float64(d[j*pd.col]) - rate*float64(c[j*pc.col])/batchSize)
+		}
+	}
+}
+
+// convertParameters of a network to a precision in place.
+func convertParameters(params []parameter, p Precision) {
+	for _, param := range params {
+		*param.value = *param.value.As(p)
+	}
+}
+
+/*
+ConvertPrecision returns a copy of a network with parameters of a precision, e.g. a network
+learned in float64 for a float32 inference or a float32 one for a float64 fine-tuning.
+Converting to float32 rounds weights.
+*/
+func ConvertPrecision(n Network, p Precision) (Network, error) {
+	if err := p.validate("Precision"); err != nil {
+		return nil, err
+	}
+	c, err := n.Config()
+	if err != nil {
+		return nil, err
+	}
+	c.Precision = p
+	converted, err := NewFromConfig(c)
+	if err != nil {
+		return nil, err
+	}
+	if err = loadParameters(converted.parameters(), parametersOf(n)); err != nil {
+		return nil, err
+	}
+	return converted, nil
+}
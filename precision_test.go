@@ -0,0 +1,148 @@
+package goDeep
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestTensor_precision(t *testing.T) {
+	single, _ := NewTensorOf([]float32{.1, .2, 3, 4}, 2, 2)
+	double, _ := NewTensor([]float64{.2, .3, 1, 1}, 2, 2)
+	add := func(a, b *Tensor) (*Tensor, error) { return a.Add(b) }
+	matMul := func(a, b *Tensor) (*Tensor, error) { return a.MatMul(b) }
+	tests := []struct {
+		name string
+		op   func(a, b *Tensor) (*Tensor, error)
+		a, b *Tensor
+		want Precision
+	}{
+		{name: "addDouble", op: add, a: double, b: double, want: Float64},
+		{name: "addMixed", op: add, a: double, b: single, want: Float32},
+		{name: "matMulMixed", op: matMul, a: single, b: double, want: Float32},
+		{name: "matMulSingle", op: matMul, a: single, b: single, want: Float32},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.op(tt.a, tt.b)
+			if err != nil {
+				t.Fatalf("operation error = %v", err)
+			}
+			if got.Precision() != tt.want {
+				t.Errorf("Precision() = %s, want %s", got.Precision(), tt.want)
+			}
+		})
+	}
+
+	sum, _ := single.Add(double)
+	if got, want := DataOf[float32](sum)[0], float32(.1)+float32(.2); got != want {
+		t.Errorf("float32 sum = %v, want %v", got, want)
+	}
+	if got := single.Transpose().Clone(); got.Precision() != Float32 || !reflect.DeepEqual(DataOf[float32](got), []float32{.1, 3, .2, 4}) {
+		t.Errorf("Clone() = %v of %s, want a float32 transposition", got.Data(), got.Precision())
+	}
+	if got := double.As(Float32).As(Float64); got.Precision() != Float64 || got.At(0, 0) != float64(float32(.2)) {
+		t.Errorf("As() = %v of %s, want rounded to float32", got.Data(), got.Precision())
+	}
+}
+
+func TestNewFromConfig_float32(t *testing.T) {
+	config := ModelConfig{
+		Input:     &LayerConfig{Size: 3, LearningRate: .5, Bias: 1},
+		Hidden:    []LayerConfig{{Size: 4, LearningRate: .5, Bias: 1, Activation: "sigmoid", Initializer: "xavier"}},
+		Output:    LayerConfig{Size: 1, Activation: "sigmoid", Cost: "quadratic"},
+		Seed:      3,
+		Precision: Float32,
+	}
+	set := rowsOf([][]float64{{0, 0, 0}, {0, 1, 0}, {1, 0, 0}, {1, 1, 0}})
+	labels := rowsOf([][]float64{{0}, {1}, {1}, {0}})
+
+	n, err := NewFromConfig(config)
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	if _, err = n.Learn(set, labels, 20, 2); err != nil {
+		t.Fatalf("Perceptron.Learn() error = %v", err)
+	}
+	for _, p := range n.parameters() {
+		if p.value.Precision() != Float32 {
+			t.Errorf("%s precision = %s, want float32", p.name, p.value.Precision())
+		}
+	}
+	prediction, err := n.Recognize(set)
+	if err != nil {
+		t.Fatalf("Perceptron.Recognize() error = %v", err)
+	}
+	if prediction.Precision() != Float32 {
+		t.Errorf("prediction precision = %s, want float32", prediction.Precision())
+	}
+	if c, _ := n.Config(); c.Precision != Float32 {
+		t.Errorf("Perceptron.Config() precision = %q, want float32", c.Precision)
+	}
+
+	var buf bytes.Buffer
+	if err = SaveModel(&buf, n); err != nil {
+		t.Fatalf("SaveModel() error = %v", err)
+	}
+	loaded, err := LoadModel(&buf)
+	if err != nil {
+		t.Fatalf("LoadModel() error = %v", err)
+	}
+	got, _ := loaded.Recognize(set)
+	if !reflect.DeepEqual(got.Data(), prediction.Data()) {
+		t.Errorf("Recognize() of a loaded model = %v, want %v", got.Data(), prediction.Data())
+	}
+
+	double, err := ConvertPrecision(n, Float64)
+	if err != nil {
+		t.Fatalf("ConvertPrecision() error = %v", err)
+	}
+	if c, _ := double.Config(); c.Precision != "" {
+		t.Errorf("converted Perceptron.Config() precision = %q, want float64", c.Precision)
+	}
+	got, _ = double.Recognize(set)
+	assertPredictions(t, got, prediction, 1e-6)
+
+	config.Precision = "float16"
+	var configErr *ConfigError
+	if _, err = NewFromConfig(config); !errors.As(err, &configErr) {
+		t.Errorf("NewFromConfig() of float16 error = %v, want *ConfigError", err)
+	}
+}
+
+func BenchmarkPerceptron_Learn(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	rows := make([][]float64, 256)
+	labelRows := make([][]float64, len(rows))
+	for i := range rows {
+		rows[i] = make([]float64, 64)
+		for j := range rows[i] {
+			rows[i][j] = r.Float64()
+		}
+		labelRows[i] = make([]float64, 10)
+		labelRows[i][i%10] = 1
+	}
+	set, labels := rowsOf(rows), rowsOf(labelRows)
+	for _, precision := range []Precision{Float64, Float32} {
+		b.Run(string(precision), func(b *testing.B) {
+			n, err := NewFromConfig(ModelConfig{
+				Input:     &LayerConfig{Size: 64, LearningRate: .1, Bias: 1},
+				Hidden:    []LayerConfig{{Size: 64, LearningRate: .1, Bias: 1, Activation: "sigmoid"}},
+				Output:    LayerConfig{Size: 10, Activation: "sigmoid", Cost: "quadratic"},
+				Seed:      1,
+				Precision: precision,
+			})
+			if err != nil {
+				b.Fatalf("NewFromConfig() error = %v", err)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err = n.Learn(set, labels, 1, 32); err != nil {
+					b.Fatalf("Perceptron.Learn() error = %v", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package goDeep
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// Wire types of protocol buffers.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+var errProtoTruncated = errors.New("protobuf: a message is truncated")
+
+// protoBuffer encodes a protocol buffers message field by field.
+type protoBuffer struct {
+	b []byte
+}
+
+func (p *protoBuffer) tag(field, wire int) {
+	p.b = appendVarint(p.b, uint64(field)<<3|uint64(wire))
+}
+
+func (p *protoBuffer) varint(field int, v int64) {
+	p.tag(field, wireVarint)
+	p.b = appendVarint(p.b, uint64(v))
+}
+
+func (p *protoBuffer) bytes(field int, b []byte) {
+	p.tag(field, wireBytes)
+	p.b = appendVarint(p.b, uint64(len(b)))
+	p.b = append(p.b, b...)
+}
+
+func (p *protoBuffer) string(field int, s string) {
+	p.bytes(field, []byte(s))
+}
+
+func (p *protoBuffer) message(field int, m *protoBuffer) {
+	p.bytes(field, m.b)
+}
+
+func (p *protoBuffer) float32(field int, v float32) {
+	p.tag(field, wireFixed32)
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], math.Float32bits(v))
+	p.b = append(p.b, b[:]...)
+}
+
+func (p *protoBuffer) packedVarints(field int, vs []int64) {
+	var packed []byte
+	for _, v := range vs {
+		packed = appendVarint(packed, uint64(v))
+	}
+	p.bytes(field, packed)
+}
+
+func appendVarint(b []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	return append(b, buf[:binary.PutUvarint(buf[:], v)]...)
+}
+
+// protoField is a decoded field. A value of fixed size fields is kept in varint.
+type protoField struct {
+	number, wire int
+	varint       uint64
+	bytes        []byte
+}
+
+// protoFields decodes fields of a message in order.
+func protoFields(b []byte, fn func(f protoField) error) error {
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		if n <= 0 {
+			return errProtoTruncated
+		}
+		b = b[n:]
+		f := protoField{number: int(key >> 3), wire: int(key & 7)}
+		switch f.wire {
+		case wireVarint:
+			if f.varint, n = binary.Uvarint(b); n <= 0 {
+				return errProtoTruncated
+			}
+		case wireFixed64:
+			if n = 8; len(b) < n {
+				return errProtoTruncated
+			}
+			f.varint = binary.LittleEndian.Uint64(b)
+		case wireFixed32:
+			if n = 4; len(b) < n {
+				return errProtoTruncated
+			}
+			f.varint = uint64(binary.LittleEndian.Uint32(b))
+		case wireBytes:
+			size, m := binary.Uvarint(b)
+			if m <= 0 || uint64(len(b)-m) < size {
+				return errProtoTruncated
+			}
+			f.bytes, n = b[m:m+int(size)], m+int(size)
+		default:
+			return errors.New("protobuf: unsupported wire type")
+		}
+		b = b[n:]
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// varints of a repeated field, packed or not.
+func (f protoField) varints() ([]int64, error) {
+	if f.wire == wireVarint {
+		return []int64{int64(f.varint)}, nil
+	}
+	var vs []int64
+	for b := f.bytes; len(b) > 0; {
+		v, n := binary.Uvarint(b)
+		if n <= 0 {
+			return nil, errProtoTruncated
+		}
+		vs, b = append(vs, int64(v)), b[n:]
+	}
+	return vs, nil
+}
+
+// float32s of a repeated field, packed or not.
+func (f protoField) float32s() ([]float64, error) {
+	if f.wire == wireFixed32 {
+		return []float64{float64(math.Float32frombits(uint32(f.varint)))}, nil
+	}
+	return littleEndianFloats(f.bytes, 4)
+}
+
+// float64s of a repeated field, packed or not.
+func (f protoField) float64s() ([]float64, error) {
+	if f.wire == wireFixed64 {
+		return []float64{math.Float64frombits(f.varint)}, nil
+	}
+	return littleEndianFloats(f.bytes, 8)
+}
+
+// littleEndianFloats decodes floats of a size of 4 or 8 bytes.
+func littleEndianFloats(b []byte, size int) ([]float64, error) {
+	if len(b)%size != 0 {
+		return nil, errProtoTruncated
+	}
+	vs := make([]float64, len(b)/size)
+	for i := range vs {
+		if size == 4 {
+			vs[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(b[i*4:])))
+		} else {
+			vs[i] = math.Float64frombits(binary.LittleEndian.Uint64(b[i*8:]))
+		}
+	}
+	return vs, nil
+}
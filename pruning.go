@@ -0,0 +1,282 @@
+package goDeep
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// PruneOptions of a magnitude pruning. Sparsity is a fraction of pruned synapses in [0, 1)
+// of every layer or of all the layers together if Global is set.
+type PruneOptions struct {
+	Sparsity float64
+	Global   bool
+}
+
+func (o PruneOptions) validate() error {
+	if !(o.Sparsity >= 0 && o.Sparsity < 1) {
+		return &ConfigError{Field: "PruneOptions.Sparsity", Value: o.Sparsity, Reason: "must be in [0, 1)"}
+	}
+	return nil
+}
+
+// prunable synapses of a parameter, a bias row excluded.
+type prunable struct {
+	parameter
+	rows int
+}
+
+// magnitude of a prunable synapse at a row-major position of a parameter.
+type magnitude struct {
+	synapses *prunable
+	pos      int
+	value    float64
+}
+
+// prunables are synapses of a network grouped by layers.
+func prunables(n Network) ([]*prunable, error) {
+	c, err := n.Config()
+	if err != nil {
+		return nil, err
+	}
+	biases := map[string]bool{"input.synapses": c.Input != nil && c.Input.Bias != 0 || c.Embedding != nil && c.Embedding.Bias != 0}
+	for i, l := range c.Hidden {
+		biases[fmt.Sprintf("hidden[%d].synapses", i)] = l.Bias != 0
+	}
+
+	var layers []*prunable
+	for _, p := range n.parameters() {
+		if p.mask == nil {
+			continue
+		}
+		rows := p.value.Shape()[0]
+		if biases[p.name] {
+			rows--
+		}
+		layers = append(layers, &prunable{parameter: p, rows: rows})
+	}
+	return layers, nil
+}
+
+/*
+Prune zeroes synapses of the smallest absolute values and masks them: pruned synapses stay
+zero while learning and by next pruning. Biases and embeddings are not pruned.
+
+Saved networks keep pruned synapses zero but not masks, pruning a loaded network by the same
+options restores them. Checkpoints keep masks, so resumed learning prunes the same synapses.
+*/
+func Prune(n Network, options PruneOptions) error {
+	if err := options.validate(); err != nil {
+		return err
+	}
+	layers, err := prunables(n)
+	if err != nil {
+		return err
+	}
+	groups := make([][]*prunable, len(layers))
+	for i, l := range layers {
+		groups[i] = []*prunable{l}
+	}
+	if options.Global {
+		groups = [][]*prunable{layers}
+	}
+
+	for _, group := range groups {
+		var magnitudes []magnitude
+		for _, l := range group {
+			cols := l.value.Shape()[1]
+			data := l.value.Data()
+			for pos := 0; pos < l.rows*cols; pos++ {
+				magnitudes = append(magnitudes, magnitude{l, pos, math.Abs(data[pos])})
+			}
+		}
+		sort.SliceStable(magnitudes, func(i, j int) bool { return magnitudes[i].value < magnitudes[j].value })
+		pruned := int(math.Round(options.Sparsity * float64(len(magnitudes))))
+		for _, m := range magnitudes[:pruned] {
+			m.synapses.pruneAt(m.pos)
+		}
+	}
+	for _, l := range layers {
+		if *l.mask != nil {
+			if err = l.value.mulInPlace(*l.mask); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// pruneAt masks a synapse at a row-major position.
+func (l *prunable) pruneAt(pos int) {
+	if *l.mask == nil {
+		*l.mask = Zeros(l.value.shape...).Apply(func(float64) float64 { return 1 })
+	}
+	(*l.mask).data.set(pos, 0)
+}
+
+// Sparsity is a fraction of pruned synapses of a network.
+func Sparsity(n Network) (float64, error) {
+	layers, err := prunables(n)
+	if err != nil {
+		return 0, err
+	}
+	var pruned, synapses int
+	for _, l := range layers {
+		cols := l.value.Shape()[1]
+		synapses += l.rows * cols
+		if mask := *l.mask; mask != nil {
+			for pos := 0; pos < l.rows*cols; pos++ {
+				if mask.data.at(pos) == 0 {
+					pruned++
+				}
+			}
+		}
+	}
+	if synapses == 0 {
+		return 0, nil
+	}
+	return float64(pruned) / float64(synapses), nil
+}
+
+/*
+PruningSchedule prunes synapses gradually while learning. A sparsity grows from zero at
+a Begin step to a Sparsity of options at an End step by a cubic schedule, synapses are
+pruned every Frequency steps. Steps are learned batches of all the epochs as of Checkpoint.
+*/
+type PruningSchedule struct {
+	PruneOptions
+	Begin, End, Frequency int
+}
+
+func (s PruningSchedule) validate() error {
+	switch {
+	case s.Begin < 0:
+		return &ConfigError{Field: "PruningSchedule.Begin", Value: s.Begin, Reason: "must not be negative"}
+	case s.End <= s.Begin:
+		return &ConfigError{Field: "PruningSchedule.End", Value: s.End, Reason: "must be after a begin"}
+	case s.Frequency <= 0:
+		return &ConfigError{Field: "PruningSchedule.Frequency", Value: s.Frequency, Reason: "must be positive"}
+	}
+	return s.PruneOptions.validate()
+}
+
+// sparsity of a step.
+func (s PruningSchedule) sparsity(step int) float64 {
+	if step >= s.End {
+		return s.Sparsity
+	}
+	progress := float64(step-s.Begin) / float64(s.End-s.Begin)
+	return s.Sparsity * (1 - math.Pow(1-progress, 3))
+}
+
+type pruningConfig struct {
+	schedule PruningSchedule
+}
+
+// WithPruning prunes synapses gradually by a schedule. Masks are kept after the end of
+// a schedule, so pruned synapses stay zero, and by checkpoints.
+func WithPruning(s PruningSchedule) LearnOption {
+	return func(c *learnConfig) {
+		c.pruning = &pruningConfig{schedule: s}
+	}
+}
+
+// prune a network after a learned step if it is due.
+func (c *pruningConfig) prune(n Network, step int) error {
+	if c == nil || step < c.schedule.Begin {
+		return nil
+	}
+	s := c.schedule
+	if step > s.End || (step-s.Begin)%s.Frequency != 0 && step != s.End {
+		return nil
+	}
+	options := s.PruneOptions
+	options.Sparsity = s.sparsity(step)
+	return Prune(n, options)
+}
+
+/*
+Sparse is a network of pruned synapses for inference. Nonzero synapses of every signal
+are stored compressed, zero synapses are neither stored nor multiplied, as well as zero
+signals. Only networks of dense inputs are supported.
+*/
+type Sparse struct {
+	inputSize int
+	layers    []sparseLayer
+}
+
+type sparseLayer struct {
+	denseLayer
+	// Neurons and synapses of a signal s are at offsets[s]:offsets[s+1].
+	offsets []int
+	targets []int
+	weights []float64
+}
+
+// NewSparse compresses nonzero synapses of a network.
+func NewSparse(n Network) (*Sparse, error) {
+	inputSize, layers, err := denseLayers("NewSparse", n)
+	if err != nil {
+		return nil, err
+	}
+	s := &Sparse{inputSize: inputSize}
+	for _, dense := range layers {
+		l := sparseLayer{denseLayer: dense, offsets: []int{0}}
+		for _, row := range dense.synapses {
+			for j, w := range row {
+				if w != 0 {
+					l.targets = append(l.targets, j)
+					l.weights = append(l.weights, w)
+				}
+			}
+			l.offsets = append(l.offsets, len(l.weights))
+		}
+		l.synapses = nil
+		s.layers = append(s.layers, l)
+	}
+	return s, nil
+}
+
+// Synapses is a number of stored nonzero synapses, biases excluded.
+func (s *Sparse) Synapses() (synapses int) {
+	for _, l := range s.layers {
+		synapses += len(l.weights)
+	}
+	return
+}
+
+func (l *sparseLayer) forward(signal []float64) ([]float64, error) {
+	summed := make([]float64, l.neurons)
+	for s, x := range signal {
+		if x == 0 {
+			continue
+		}
+		for k := l.offsets[s]; k < l.offsets[s+1]; k++ {
+			summed[l.targets[k]] += x * l.weights[k]
+		}
+	}
+	return l.activate(summed)
+}
+
+// Recognize predicts a set of a sample per row by nonzero synapses.
+func (s *Sparse) Recognize(set *Tensor) (*Tensor, error) {
+	if err := checkWidth(set, s.inputSize); err != nil {
+		return nil, err
+	}
+	rows := set.Rows()
+	preds := make([][]float64, len(rows))
+	for i, row := range rows {
+		signal := row[:len(s.layers[0].offsets)-1]
+		for k := range s.layers {
+			var err error
+			if signal, err = s.layers[k].forward(signal); err != nil {
+				return nil, atLayer(err, k+2)
+			}
+		}
+		preds[i] = signal
+	}
+	if len(preds) == 0 {
+		return Zeros(0, s.layers[len(s.layers)-1].neurons), nil
+	}
+	return FromRows(preds)
+}
@@ -0,0 +1,173 @@
+package goDeep
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// prunedNetwork of known synapses: input of 2 and a bias, hidden of 2 and a bias, output of 1.
+func prunedNetwork(t *testing.T) Network {
+	t.Helper()
+	n, err := NewFromConfig(ModelConfig{
+		Input:  &LayerConfig{Size: 3, LearningRate: .5, Bias: 1},
+		Hidden: []LayerConfig{{Size: 3, LearningRate: .5, Bias: 1, Activation: "sigmoid"}},
+		Output: LayerConfig{Size: 1, Activation: "sigmoid", Cost: "quadratic"},
+	})
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	input, _ := FromRows([][]float64{{.1, -.8}, {.5, -.05}, {.01, .02}})
+	hidden, _ := FromRows([][]float64{{-.3}, {.9}, {.04}})
+	if err = loadParameters(n.parameters(), map[string]*Tensor{"input.synapses": input, "hidden[0].synapses": hidden}); err != nil {
+		t.Fatalf("loadParameters() error = %v", err)
+	}
+	return n
+}
+
+func TestPrune(t *testing.T) {
+	tests := []struct {
+		name                  string
+		options               PruneOptions
+		wantInput, wantHidden [][]float64
+		wantSparsity          float64
+	}{
+		{
+			name:         "perLayer",
+			options:      PruneOptions{Sparsity: .5},
+			wantInput:    [][]float64{{0, -.8}, {.5, 0}, {.01, .02}},
+			wantHidden:   [][]float64{{0}, {.9}, {.04}},
+			wantSparsity: 3. / 6,
+		},
+		{
+			name:         "global",
+			options:      PruneOptions{Sparsity: .34, Global: true},
+			wantInput:    [][]float64{{0, -.8}, {.5, 0}, {.01, .02}},
+			wantHidden:   [][]float64{{-.3}, {.9}, {.04}},
+			wantSparsity: 2. / 6,
+		},
+		{
+			name:         "none",
+			options:      PruneOptions{},
+			wantInput:    [][]float64{{.1, -.8}, {.5, -.05}, {.01, .02}},
+			wantHidden:   [][]float64{{-.3}, {.9}, {.04}},
+			wantSparsity: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := prunedNetwork(t)
+			if err := Prune(n, tt.options); err != nil {
+				t.Fatalf("Prune() error = %v", err)
+			}
+			params := parametersOf(n)
+			if got := params["input.synapses"].Rows(); !reflect.DeepEqual(got, tt.wantInput) {
+				t.Errorf("input synapses = %v, want %v", got, tt.wantInput)
+			}
+			if got := params["hidden[0].synapses"].Rows(); !reflect.DeepEqual(got, tt.wantHidden) {
+				t.Errorf("hidden synapses = %v, want %v", got, tt.wantHidden)
+			}
+			if got, _ := Sparsity(n); got != tt.wantSparsity {
+				t.Errorf("Sparsity() = %v, want %v", got, tt.wantSparsity)
+			}
+		})
+	}
+
+	var configErr *ConfigError
+	if err := Prune(prunedNetwork(t), PruneOptions{Sparsity: 1}); !errors.As(err, &configErr) {
+		t.Errorf("Prune() of a full sparsity error = %v, want *ConfigError", err)
+	}
+}
+
+func TestPrune_learning(t *testing.T) {
+	n := prunedNetwork(t)
+	if err := Prune(n, PruneOptions{Sparsity: .5}); err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	set := rowsOf([][]float64{{0, 0, 0}, {0, 1, 0}, {1, 0, 0}, {1, 1, 0}})
+	labels := rowsOf([][]float64{{0}, {1}, {1}, {0}})
+	if _, err := n.Learn(set, labels, 5, 2); err != nil {
+		t.Fatalf("Perceptron.Learn() error = %v", err)
+	}
+	params := parametersOf(n)
+	for _, pruned := range []struct {
+		name     string
+		row, col int
+	}{{"input.synapses", 0, 0}, {"input.synapses", 1, 1}, {"hidden[0].synapses", 0, 0}} {
+		if v := params[pruned.name].At(pruned.row, pruned.col); v != 0 {
+			t.Errorf("%s[%d][%d] = %v after learning, want pruned 0", pruned.name, pruned.row, pruned.col, v)
+		}
+	}
+	// The first hidden neuron has no synapses to the output, so only the second one learns.
+	if params["input.synapses"].At(2, 1) == .02 {
+		t.Error("a bias synapse is not learned")
+	}
+}
+
+func TestWithPruning(t *testing.T) {
+	s := PruningSchedule{PruneOptions: PruneOptions{Sparsity: .8}, Begin: 2, End: 10, Frequency: 2}
+	var previous float64
+	for step := s.Begin; step <= s.End; step++ {
+		got := s.sparsity(step)
+		if got < previous || got > s.Sparsity {
+			t.Fatalf("sparsity(%d) = %v after %v, want growing to %v", step, got, previous, s.Sparsity)
+		}
+		previous = got
+	}
+	if got := s.sparsity(s.Begin); got != 0 {
+		t.Errorf("sparsity(Begin) = %v, want 0", got)
+	}
+
+	n, err := NewFromConfig(ModelConfig{
+		Input:  &LayerConfig{Size: 3, LearningRate: .5, Bias: 1, Initializer: "xavier"},
+		Hidden: []LayerConfig{{Size: 8, LearningRate: .5, Bias: 1, Activation: "tanh", Initializer: "xavier"}},
+		Output: LayerConfig{Size: 3, Activation: "softmax", Cost: "quadratic"},
+		Seed:   5,
+	})
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	set, labels := blobs(60, 1)
+	// 15 batches an epoch.
+	if _, err = n.Learn(set, labels, 2, 4, WithPruning(PruningSchedule{PruneOptions: PruneOptions{Sparsity: .5, Global: true}, End: 20, Frequency: 5})); err != nil {
+		t.Fatalf("Perceptron.Learn() error = %v", err)
+	}
+	if got, _ := Sparsity(n); math.Abs(got-.5) > .02 {
+		t.Errorf("Sparsity() = %v, want .5", got)
+	}
+
+	_, err = n.Learn(set, labels, 1, 4, WithPruning(PruningSchedule{End: 10}))
+	var configErr *ConfigError
+	if !errors.As(err, &configErr) {
+		t.Errorf("Learn() of a zero frequency error = %v, want *ConfigError", err)
+	}
+}
+
+func TestNewSparse(t *testing.T) {
+	n := prunedNetwork(t)
+	if err := Prune(n, PruneOptions{Sparsity: .5}); err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	s, err := NewSparse(n)
+	if err != nil {
+		t.Fatalf("NewSparse() error = %v", err)
+	}
+	if got := s.Synapses(); got != 3 {
+		t.Errorf("Sparse.Synapses() = %d, want 3", got)
+	}
+	set := rowsOf([][]float64{{0, 0, 0}, {0, 1, 0}, {1, 0, 0}, {.5, -2, 0}})
+	want, err := n.Recognize(set)
+	if err != nil {
+		t.Fatalf("Perceptron.Recognize() error = %v", err)
+	}
+	got, err := s.Recognize(set)
+	if err != nil {
+		t.Fatalf("Sparse.Recognize() error = %v", err)
+	}
+	assertPredictions(t, got, want, 1e-12)
+
+	if _, err = s.Recognize(rowsOf([][]float64{{1, 0}})); err == nil {
+		t.Error("Sparse.Recognize() of a wrong width error = nil")
+	}
+}
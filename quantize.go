@@ -0,0 +1,303 @@
+package goDeep
+
+import (
+	"fmt"
+	"math"
+)
+
+const int8Range = 127
+
+// QuantizeOptions of a post-training quantization. PerChannel scales synapses of every
+// neuron separately, synapses of a layer share a scale otherwise.
+type QuantizeOptions struct {
+	PerChannel bool
+}
+
+/*
+Quantized is a network of int8 synapses for inference.
+
+Synapses and signals of dense connections are quantized symmetrically: a value is an int8
+multiple of a scale in [-127, 127]. Scales of signals are calibrated by the largest
+absolute signals of a sample set, signals out of a calibrated range saturate. Products
+are summed in int32, biases and activations stay float64.
+*/
+type Quantized struct {
+	inputSize int
+	layers    []quantizedLayer
+}
+
+// denseLayer is a dense connection to a layer of neurons out of network parameters.
+type denseLayer struct {
+	// Synapses of a signal per row, a bias excluded.
+	synapses   [][]float64
+	bias       []float64
+	neurons    int
+	activation activation
+}
+
+// denseLayers of a network of a dense input, an operation names errors.
+func denseLayers(op string, n Network) (inputSize int, layers []denseLayer, err error) {
+	c, err := n.Config()
+	if err != nil {
+		return
+	}
+	if err = denseInputOnly(op, c); err != nil {
+		return
+	}
+	params := parametersOf(n)
+	biases := []bool{c.Input.Bias != 0}
+	activations := make([]string, 0, len(c.Hidden)+1)
+	for _, h := range c.Hidden {
+		biases = append(biases, h.Bias != 0)
+		activations = append(activations, h.Activation)
+	}
+	activations = append(activations, c.Output.Activation)
+	for i, name := range append([]string{"input"}, hiddenNames(len(c.Hidden))...) {
+		act, err := activationByName(fmt.Sprintf("layer %d activation", i+2), activations[i])
+		if err != nil {
+			return 0, nil, err
+		}
+		synapses := params[name+".synapses"]
+		l := denseLayer{synapses: synapses.Rows(), neurons: synapses.Shape()[1], activation: act}
+		if biases[i] {
+			last := len(l.synapses) - 1
+			l.synapses, l.bias = l.synapses[:last], l.synapses[last]
+		}
+		layers = append(layers, l)
+	}
+	return c.Input.Size, layers, nil
+}
+
+// activate summed signals of neurons adding biases.
+func (l *denseLayer) activate(summed []float64) ([]float64, error) {
+	if l.bias != nil {
+		for j := range summed {
+			summed[j] += l.bias[j]
+		}
+	}
+	activated, err := activationOp(l.activation).forward(Vector(summed))
+	if err != nil {
+		return nil, err
+	}
+	return activated.Data(), nil
+}
+
+// quantizedLayer is a dense connection to a layer of neurons. Float synapses are kept
+// until quantized.
+type quantizedLayer struct {
+	denseLayer
+	// Synapses of a signal per row.
+	weights []int8
+	signals int
+	// Scales of synapses of every neuron and of signals.
+	weightScales []float64
+	signalScale  float64
+}
+
+/*
+Quantize quantizes synapses of a network to int8, calibrating scales of signals by
+a sample set of the network input size. Only networks of dense inputs are quantized.
+*/
+func Quantize(n Network, calibration *Tensor, options QuantizeOptions) (*Quantized, error) {
+	inputSize, layers, err := denseLayers("Quantize", n)
+	if err != nil {
+		return nil, err
+	}
+	q := &Quantized{inputSize: inputSize}
+	if err = checkWidth(calibration, q.inputSize); err != nil {
+		return nil, err
+	}
+	if rowsNumber(calibration) == 0 {
+		return nil, &ConfigError{Field: "calibration", Value: calibration.Shape(), Reason: "a calibration set is empty"}
+	}
+	for _, l := range layers {
+		q.layers = append(q.layers, quantizedLayer{denseLayer: l, signals: len(l.synapses)})
+	}
+
+	if err = q.calibrate(calibration); err != nil {
+		return nil, err
+	}
+	for i := range q.layers {
+		q.layers[i].quantize(options.PerChannel)
+	}
+	return q, nil
+}
+
+// checkWidth of a set of samples of an input size.
+func checkWidth(set *Tensor, inputSize int) error {
+	if set.Dims() != 2 || set.Shape()[1] != inputSize {
+		return &ShapeMismatchError{Op: "Set", Layer: 1, Expected: []int{rowsNumber(set), inputSize}, Actual: set.Shape()}
+	}
+	return nil
+}
+
+// calibrate scales of signals by the largest absolute signals of float propagation.
+func (q *Quantized) calibrate(set *Tensor) error {
+	ranges := make([]float64, len(q.layers))
+	for _, row := range set.Rows() {
+		signal := row[:q.layers[0].signals]
+		for i := range q.layers {
+			l := &q.layers[i]
+			summed := make([]float64, l.neurons)
+			for s, x := range signal {
+				ranges[i] = math.Max(ranges[i], math.Abs(x))
+				for j := range summed {
+					summed[j] += x * l.synapses[s][j]
+				}
+			}
+			var err error
+			if signal, err = l.activate(summed); err != nil {
+				return err
+			}
+		}
+	}
+	for i := range q.layers {
+		q.layers[i].signalScale = scaleOf(ranges[i])
+	}
+	return nil
+}
+
+// scaleOf values of the largest absolute value.
+func scaleOf(max float64) float64 {
+	if max == 0 {
+		return 1
+	}
+	return max / int8Range
+}
+
+func quantizeValue(x, scale float64) int8 {
+	return int8(math.Max(-int8Range, math.Min(int8Range, math.Round(x/scale))))
+}
+
+func (l *quantizedLayer) quantize(perChannel bool) {
+	ranges := make([]float64, l.neurons)
+	var layerRange float64
+	for _, row := range l.synapses {
+		for j, w := range row {
+			ranges[j] = math.Max(ranges[j], math.Abs(w))
+			layerRange = math.Max(layerRange, math.Abs(w))
+		}
+	}
+	l.weightScales = make([]float64, l.neurons)
+	for j := range l.weightScales {
+		if perChannel {
+			l.weightScales[j] = scaleOf(ranges[j])
+		} else {
+			l.weightScales[j] = scaleOf(layerRange)
+		}
+	}
+
+	l.weights = make([]int8, l.signals*l.neurons)
+	for s, row := range l.synapses {
+		for j, w := range row {
+			l.weights[s*l.neurons+j] = quantizeValue(w, l.weightScales[j])
+		}
+	}
+	l.synapses = nil
+}
+
+// forward a sample of quantized signals through int8 synapses.
+func (l *quantizedLayer) forward(signal []float64) ([]float64, error) {
+	acc := make([]int32, l.neurons)
+	for s, x := range signal {
+		qx := int32(quantizeValue(x, l.signalScale))
+		if qx == 0 {
+			continue
+		}
+		row := l.weights[s*l.neurons : (s+1)*l.neurons]
+		for j, w := range row {
+			acc[j] += qx * int32(w)
+		}
+	}
+	summed := make([]float64, l.neurons)
+	for j, a := range acc {
+		summed[j] = float64(a) * l.signalScale * l.weightScales[j]
+	}
+	return l.activate(summed)
+}
+
+// Recognize predicts a set of a sample per row by int8 synapses.
+func (q *Quantized) Recognize(set *Tensor) (*Tensor, error) {
+	if err := checkWidth(set, q.inputSize); err != nil {
+		return nil, err
+	}
+	rows := set.Rows()
+	preds := make([][]float64, len(rows))
+	for i, row := range rows {
+		signal := row[:q.layers[0].signals]
+		for k := range q.layers {
+			var err error
+			if signal, err = q.layers[k].forward(signal); err != nil {
+				return nil, atLayer(err, k+2)
+			}
+		}
+		preds[i] = signal
+	}
+	if len(preds) == 0 {
+		return Zeros(0, q.layers[len(q.layers)-1].neurons), nil
+	}
+	return FromRows(preds)
+}
+
+// Bytes is a size of quantized parameters: synapses, scales and biases.
+func (q *Quantized) Bytes() (size int) {
+	for _, l := range q.layers {
+		size += len(l.weights) + 8*(len(l.weightScales)+1+len(l.bias))
+	}
+	return
+}
+
+// QuantizationReport compares a quantized network to a float one on a labeled set.
+// Errors are absolute differences of predictions.
+type QuantizationReport struct {
+	FloatAccuracy, QuantizedAccuracy, AccuracyDrop float64
+	MaxError, MeanError                            float64
+	FloatBytes, QuantizedBytes                     int
+}
+
+// Compare a quantized network to a float one it is quantized of.
+func (q *Quantized) Compare(n Network, set, labels *Tensor) (r QuantizationReport, err error) {
+	e, err := Evaluate(n, set, labels)
+	if err != nil {
+		return
+	}
+	want, err := n.Recognize(set)
+	if err != nil {
+		return
+	}
+	got, err := q.Recognize(set)
+	if err != nil {
+		return
+	}
+
+	wantRows, labelRows := want.Rows(), labels.Rows()
+	var correct, outputs int
+	for i, row := range got.Rows() {
+		prediction, label := Vector(row), Vector(labelRows[i])
+		if correctPrediction(prediction, label) {
+			correct++
+		}
+		for j, v := range row {
+			diff := math.Abs(v - wantRows[i][j])
+			r.MaxError = math.Max(r.MaxError, diff)
+			r.MeanError += diff
+			outputs++
+		}
+	}
+	if samples := rowsNumber(set); samples > 0 {
+		r.QuantizedAccuracy = float64(correct) / float64(samples)
+		r.MeanError /= float64(outputs)
+	}
+	r.FloatAccuracy = e.Accuracy
+	r.AccuracyDrop = r.FloatAccuracy - r.QuantizedAccuracy
+
+	for _, p := range n.parameters() {
+		size := 8
+		if p.value.Precision() == Float32 {
+			size = 4
+		}
+		r.FloatBytes += p.value.Size() * size
+	}
+	r.QuantizedBytes = q.Bytes()
+	return
+}
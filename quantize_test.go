@@ -0,0 +1,142 @@
+package goDeep
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// blobs are samples of three classes around separate centers, labels are one-hot.
+func blobs(samples int, seed int64) (set, labels *Tensor) {
+	r := rand.New(rand.NewSource(seed))
+	centers := [][]float64{{-1, -1}, {1, -1}, {0, 1}}
+	var rows, labelRows [][]float64
+	for i := 0; i < samples; i++ {
+		class := i % len(centers)
+		label := make([]float64, len(centers))
+		label[class] = 1
+		rows = append(rows, []float64{centers[class][0] + r.NormFloat64()*.3, centers[class][1] + r.NormFloat64()*.3, 0})
+		labelRows = append(labelRows, label)
+	}
+	return rowsOf(rows), rowsOf(labelRows)
+}
+
+func TestQuantize(t *testing.T) {
+	n, err := NewFromConfig(ModelConfig{
+		Input:  &LayerConfig{Size: 3, LearningRate: .5, Bias: 1, Initializer: "xavier"},
+		Hidden: []LayerConfig{{Size: 8, LearningRate: .5, Bias: 1, Activation: "tanh", Initializer: "xavier"}},
+		Output: LayerConfig{Size: 3, Activation: "softmax", Cost: "quadratic"},
+		Seed:   5,
+	})
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	set, labels := blobs(60, 1)
+	if _, err = n.Learn(set, labels, 30, 4); err != nil {
+		t.Fatalf("Perceptron.Learn() error = %v", err)
+	}
+	test, testLabels := blobs(30, 2)
+
+	for _, options := range []QuantizeOptions{{}, {PerChannel: true}} {
+		q, err := Quantize(n, set, options)
+		if err != nil {
+			t.Fatalf("Quantize(%+v) error = %v", options, err)
+		}
+		r, err := q.Compare(n, test, testLabels)
+		if err != nil {
+			t.Fatalf("Quantized.Compare() error = %v", err)
+		}
+		if r.FloatAccuracy < .9 {
+			t.Fatalf("float accuracy = %v, the network is not learned", r.FloatAccuracy)
+		}
+		if r.MaxError > .05 || r.AccuracyDrop > .05 || r.AccuracyDrop != r.FloatAccuracy-r.QuantizedAccuracy {
+			t.Errorf("Quantize(%+v) report = %+v, want close predictions", options, r)
+		}
+		if r.QuantizedBytes >= r.FloatBytes {
+			t.Errorf("Quantize(%+v) size = %d bytes, want less than %d", options, r.QuantizedBytes, r.FloatBytes)
+		}
+	}
+}
+
+func TestQuantize_perChannel(t *testing.T) {
+	config := ModelConfig{
+		Input:  &LayerConfig{Size: 2, LearningRate: .1},
+		Hidden: []LayerConfig{{Size: 2, LearningRate: .1, Activation: "relu"}},
+		Output: LayerConfig{Size: 1, Activation: "sigmoid", Cost: "quadratic"},
+	}
+	n, err := NewFromConfig(config)
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	// Synapses of the second hidden neuron are much smaller than of the first one.
+	input, _ := FromRows([][]float64{{10, .01}, {-5, .02}})
+	hidden, _ := FromRows([][]float64{{.5}, {1}})
+	if err = loadParameters(n.parameters(), map[string]*Tensor{"input.synapses": input, "hidden[0].synapses": hidden}); err != nil {
+		t.Fatalf("loadParameters() error = %v", err)
+	}
+	sample := rowsOf([][]float64{{0, 1}})
+
+	layer, err := Quantize(n, sample, QuantizeOptions{})
+	if err != nil {
+		t.Fatalf("Quantize() error = %v", err)
+	}
+	channel, err := Quantize(n, sample, QuantizeOptions{PerChannel: true})
+	if err != nil {
+		t.Fatalf("Quantize() error = %v", err)
+	}
+	// The first input is zero, so the first hidden neuron is off and the second one
+	// sums a single product of .02.
+	want := 1 / (1 + math.Exp(-.02))
+	got, _ := channel.Recognize(sample)
+	if math.Abs(got.At(0, 0)-want) > 1e-4 {
+		t.Errorf("per channel Recognize() = %v, want %v", got.At(0, 0), want)
+	}
+	// Per layer .02 is rounded to zero by a scale of 10/127.
+	got, _ = layer.Recognize(sample)
+	if got.At(0, 0) != .5 {
+		t.Errorf("per layer Recognize() = %v, want .5", got.At(0, 0))
+	}
+}
+
+func TestQuantize_errors(t *testing.T) {
+	embedded, err := NewFromConfig(ModelConfig{
+		Embedding: &EmbeddingConfig{Vocabulary: 5, Dimension: 2, Fields: 2, LearningRate: .1},
+		Hidden:    []LayerConfig{{Size: 3, LearningRate: .1, Bias: 1, Activation: "sigmoid"}},
+		Output:    LayerConfig{Size: 2, Activation: "sigmoid", Cost: "quadratic"},
+	})
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	dense, err := NewFromConfig(ModelConfig{
+		Input:  &LayerConfig{Size: 3, LearningRate: .1, Bias: 1},
+		Hidden: []LayerConfig{{Size: 3, LearningRate: .1, Bias: 1, Activation: "sigmoid"}},
+		Output: LayerConfig{Size: 2, Activation: "sigmoid", Cost: "quadratic"},
+	})
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	tests := []struct {
+		name        string
+		n           Network
+		calibration *Tensor
+	}{
+		{name: "embedding", n: embedded, calibration: rowsOf([][]float64{{1, 2}})},
+		{name: "wrongWidth", n: dense, calibration: rowsOf([][]float64{{1, 2}})},
+		{name: "empty", n: dense, calibration: Zeros(0, 3)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Quantize(tt.n, tt.calibration, QuantizeOptions{}); err == nil {
+				t.Error("Quantize() error = nil")
+			}
+		})
+	}
+
+	q, err := Quantize(dense, rowsOf([][]float64{{1, 0, 0}}), QuantizeOptions{})
+	if err != nil {
+		t.Fatalf("Quantize() error = %v", err)
+	}
+	if _, err = q.Recognize(rowsOf([][]float64{{1, 0}})); err == nil {
+		t.Error("Quantized.Recognize() of a wrong width error = nil")
+	}
+}
@@ -0,0 +1,207 @@
+package goDeep
+
+import "math"
+
+/*
+Scheduler adjusts learning rates of all the layers during learning.
+
+Rate returns a factor of learning rates set by layer shapes for a step counted from
+zero. A step is a batch or an epoch depending on a schedule unit of WithScheduler.
+*/
+type Scheduler interface {
+	Rate(step int) float64
+}
+
+// validationObserver is a scheduler driven by a validation cost of every epoch.
+type validationObserver interface {
+	observe(validationCost float64)
+}
+
+// ScheduleUnit is a step of a learning rate schedule.
+type ScheduleUnit int
+
+const (
+	// PerEpoch changes learning rates at the start of every epoch.
+	PerEpoch ScheduleUnit = iota
+	// PerBatch changes learning rates before every batch.
+	PerBatch
+)
+
+// StepDecay multiplies learning rates by Gamma every Step steps.
+type StepDecay struct {
+	Step  int
+	Gamma float64
+}
+
+// Rate of a step decay schedule.
+func (s StepDecay) Rate(step int) float64 {
+	if s.Step <= 0 {
+		return 1
+	}
+	return math.Pow(s.Gamma, float64(step/s.Step))
+}
+
+// ExponentialDecay multiplies learning rates by Gamma every step.
+type ExponentialDecay struct {
+	Gamma float64
+}
+
+// Rate of an exponential decay schedule.
+func (s ExponentialDecay) Rate(step int) float64 {
+	return math.Pow(s.Gamma, float64(step))
+}
+
+/*
+CosineAnnealing anneals learning rates from a full rate down to Min factor along a half
+of a cosine period of Period steps and restarts. Every next period is PeriodMult times
+longer, a zero multiplier keeps periods equal.
+*/
+type CosineAnnealing struct {
+	Period     int
+	PeriodMult float64
+	Min        float64
+}
+
+// Rate of a cosine annealing schedule with warm restarts.
+func (s CosineAnnealing) Rate(step int) float64 {
+	if s.Period <= 0 {
+		return 1
+	}
+	period := s.Period
+	for step >= period {
+		step -= period
+		if s.PeriodMult > 1 {
+			period = int(math.Ceil(float64(period) * s.PeriodMult))
+		}
+	}
+	return cosineRamp(1, s.Min, float64(step)/float64(period))
+}
+
+/*
+OneCycle raises learning rates from Start factor up to a full rate during Warmup share of
+Steps and anneals them down to End factor during the rest of steps. Learning rates of
+layer shapes are peak rates of a cycle, Warmup is 0.3 when zero.
+*/
+type OneCycle struct {
+	Steps      int
+	Warmup     float64
+	Start, End float64
+}
+
+// Rate of a one cycle schedule.
+func (s OneCycle) Rate(step int) float64 {
+	if s.Steps <= 0 || step >= s.Steps {
+		return s.End
+	}
+	warmup := s.Warmup
+	if warmup == 0 {
+		warmup = .3
+	}
+	up := int(math.Ceil(warmup * float64(s.Steps)))
+	if step < up {
+		return cosineRamp(s.Start, 1, float64(step)/float64(up))
+	}
+	return cosineRamp(1, s.End, float64(step-up)/float64(s.Steps-up))
+}
+
+/*
+LinearWarmup raises learning rates linearly from Start factor up to a full rate during
+Steps steps and follows Then schedule afterwards, counting its steps from zero. Without
+Then schedule learning rates stay full after a warmup.
+*/
+type LinearWarmup struct {
+	Steps int
+	Start float64
+	Then  Scheduler
+}
+
+// Rate of a linear warmup schedule.
+func (s LinearWarmup) Rate(step int) float64 {
+	if step < s.Steps {
+		return s.Start + (1-s.Start)*float64(step)/float64(s.Steps)
+	}
+	if s.Then == nil {
+		return 1
+	}
+	return s.Then.Rate(step - s.Steps)
+}
+
+func (s LinearWarmup) observe(validationCost float64) {
+	if o, ok := s.Then.(validationObserver); ok {
+		o.observe(validationCost)
+	}
+}
+
+func (s LinearWarmup) state() []float64 {
+	if st, ok := s.Then.(statefulScheduler); ok {
+		return st.state()
+	}
+	return nil
+}
+
+func (s LinearWarmup) restore(state []float64) {
+	if st, ok := s.Then.(statefulScheduler); ok {
+		st.restore(state)
+	}
+}
+
+/*
+ReduceOnPlateau multiplies learning rates by Factor when a validation cost has not improved
+by more than Threshold for Patience epochs. Rates are never reduced below Min factor.
+A validation set is passed to Learn with WithValidation option, without it rates stay full.
+
+A schedule keeps a state, so use a new one for every Learn call.
+*/
+type ReduceOnPlateau struct {
+	Factor    float64
+	Patience  int
+	Threshold float64
+	Min       float64
+
+	reductions int
+	best       float64
+	wait       int
+	seen       bool
+}
+
+// NewReduceOnPlateau is a reduce on plateau schedule initializer.
+func NewReduceOnPlateau(factor float64, patience int, threshold, min float64) *ReduceOnPlateau {
+	return &ReduceOnPlateau{Factor: factor, Patience: patience, Threshold: threshold, Min: min}
+}
+
+// Rate of a reduce on plateau schedule does not depend on a step.
+func (s *ReduceOnPlateau) Rate(step int) float64 {
+	return math.Max(math.Pow(s.Factor, float64(s.reductions)), s.Min)
+}
+
+func (s *ReduceOnPlateau) observe(validationCost float64) {
+	if !s.seen || validationCost < s.best-s.Threshold {
+		s.best, s.wait, s.seen = validationCost, 0, true
+		return
+	}
+	s.wait++
+	if s.wait > s.Patience {
+		s.reductions++
+		s.wait = 0
+	}
+}
+
+func (s *ReduceOnPlateau) state() []float64 {
+	var seen float64
+	if s.seen {
+		seen = 1
+	}
+	return []float64{float64(s.reductions), s.best, float64(s.wait), seen}
+}
+
+func (s *ReduceOnPlateau) restore(state []float64) {
+	if len(state) != 4 {
+		return
+	}
+	s.reductions, s.best, s.wait, s.seen = int(state[0]), state[1], int(state[2]), state[3] != 0
+}
+
+// cosineRamp moves from a factor to another one along a half of a cosine period.
+func cosineRamp(from, to, progress float64) float64 {
+	return to + (from-to)*(1+math.Cos(math.Pi*progress))/2
+}
@@ -0,0 +1,106 @@
+package goDeep
+
+import (
+	"math"
+	"testing"
+)
+
+func TestScheduler_Rate(t *testing.T) {
+	tests := []struct {
+		name      string
+		scheduler Scheduler
+		steps     []int
+		want      []float64
+	}{
+		{
+			name:      "stepDecay",
+			scheduler: StepDecay{Step: 2, Gamma: .5},
+			steps:     []int{0, 1, 2, 5},
+			want:      []float64{1, 1, .5, .25},
+		},
+		{
+			name:      "exponentialDecay",
+			scheduler: ExponentialDecay{Gamma: .9},
+			steps:     []int{0, 1, 2},
+			want:      []float64{1, .9, .81},
+		},
+		{
+			name:      "cosineAnnealing",
+			scheduler: CosineAnnealing{Period: 4, Min: .2},
+			steps:     []int{0, 2, 4, 6},
+			want:      []float64{1, .6, 1, .6},
+		},
+		{
+			name:      "cosineAnnealingLongerPeriods",
+			scheduler: CosineAnnealing{Period: 2, PeriodMult: 2},
+			steps:     []int{1, 2, 4, 6},
+			want:      []float64{.5, 1, .5, 1},
+		},
+		{
+			name:      "oneCycle",
+			scheduler: OneCycle{Steps: 10, Warmup: .2, Start: .1, End: .01},
+			steps:     []int{0, 1, 2, 6, 10},
+			want:      []float64{.1, .55, 1, .505, .01},
+		},
+		{
+			name:      "linearWarmup",
+			scheduler: LinearWarmup{Steps: 4, Then: StepDecay{Step: 1, Gamma: .5}},
+			steps:     []int{0, 2, 4, 5},
+			want:      []float64{0, .5, 1, .5},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i, step := range tt.steps {
+				if got := tt.scheduler.Rate(step); math.Abs(got-tt.want[i]) > 1e-12 {
+					t.Errorf("%T.Rate(%d) = %v, want %v", tt.scheduler, step, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestReduceOnPlateau(t *testing.T) {
+	s := NewReduceOnPlateau(.5, 1, .01, .3)
+	costs := []float64{1, .5, .495, .6, .4, .4, .4, .4, .4}
+	want := []float64{1, 1, 1, .5, .5, .5, .3, .3, .3}
+	for i, c := range costs {
+		s.observe(c)
+		if got := s.Rate(i); got != want[i] {
+			t.Errorf("ReduceOnPlateau.Rate() after %v = %v, want %v", costs[:i+1], got, want[i])
+		}
+	}
+}
+
+func TestPerceptron_Learn_scheduled(t *testing.T) {
+	fields := mockPerceptronFields()
+	n := &Perceptron{input: fields.input, hidden: fields.hidden, output: fields.output}
+	set := rowsOf([][]float64{{1, 0, 0}, {0, 1, 0}, {1, 1, 0}})
+	labels := rowsOf([][]float64{{0}, {0}, {0}})
+
+	history, err := n.Learn(set, labels, 2, 2, WithScheduler(ExponentialDecay{Gamma: .5}, PerBatch), WithValidation(set, labels))
+	if err != nil {
+		t.Fatalf("Perceptron.Learn() error = %v", err)
+	}
+	if want := []float64{1, .5, .25, .125}; !floatsEqual(history.LearningRates, want) {
+		t.Errorf("History.LearningRates = %v, want %v", history.LearningRates, want)
+	}
+	if want := []float64{1, 1}; !floatsEqual(history.ValidationCost, want) {
+		t.Errorf("History.ValidationCost = %v, want %v", history.ValidationCost, want)
+	}
+	if got := n.rates(); !floatsEqual(got, []float64{.1, .1}) {
+		t.Errorf("Perceptron.rates() after learning = %v, want restored rates", got)
+	}
+}
+
+func floatsEqual(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if math.Abs(a[i]-b[i]) > 1e-12 {
+			return false
+		}
+	}
+	return true
+}
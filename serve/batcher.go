@@ -0,0 +1,236 @@
+package serve
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	goDeep "github.com/I159/go_deep"
+)
+
+// ErrClosed is returned by predictions of a closed batcher.
+var ErrClosed = errors.New("serve: batcher is closed")
+
+/*
+BatchOptions of a batcher. A batch is run once it has MaxBatch samples or MaxDelay after
+its first request is queued. A request of more than MaxBatch samples is run alone.
+QueueSize is a number of requests waiting for a batch before Predict blocks.
+*/
+type BatchOptions struct {
+	MaxBatch  int
+	MaxDelay  time.Duration
+	QueueSize int
+}
+
+// BatchMetrics of a batcher. QueueDepth is a number of requests waiting for a batch,
+// batch sizes are numbers of samples.
+type BatchMetrics struct {
+	QueueDepth    int64   `json:"queueDepth"`
+	Batches       int64   `json:"batches"`
+	Requests      int64   `json:"requests"`
+	Samples       int64   `json:"samples"`
+	LastBatchSize int64   `json:"lastBatchSize"`
+	MaxBatchSize  int64   `json:"maxBatchSize"`
+	MeanBatchSize float64 `json:"meanBatchSize"`
+}
+
+type batchRequest struct {
+	ctx    context.Context
+	set    *goDeep.Tensor
+	result chan batchResult
+}
+
+type batchResult struct {
+	prediction *goDeep.Tensor
+	err        error
+}
+
+/*
+Batcher groups concurrent predictions into a single Recognize call of a network and
+fans predictions back to callers. A network is used by a batcher only. If a batch fails,
+its requests are predicted one by one, so an error is answered to its request only.
+*/
+type Batcher struct {
+	network goDeep.Network
+	options BatchOptions
+	// A width of samples of an input layer, 0 if a network is not described by a config.
+	width  int
+	queue  chan *batchRequest
+	closed chan struct{}
+	close  sync.Once
+
+	queued, batches, requests, samples, last, max int64
+}
+
+// NewBatcher starts a batcher of a network. Close stops it.
+func NewBatcher(n goDeep.Network, options BatchOptions) *Batcher {
+	if options.MaxBatch < 1 {
+		options.MaxBatch = 1
+	}
+	b := &Batcher{
+		network: n,
+		options: options,
+		queue:   make(chan *batchRequest, options.QueueSize),
+		closed:  make(chan struct{}),
+	}
+	if config, err := n.Config(); err == nil {
+		if config.Input != nil {
+			b.width = config.Input.Size
+		} else if config.Embedding != nil {
+			b.width = config.Embedding.Fields
+		}
+	}
+	go b.run()
+	return b
+}
+
+// Predict samples of a set, a sample per row, in a batch with concurrent requests.
+// A set of another width than an input layer is an error before it is queued.
+func (b *Batcher) Predict(ctx context.Context, set *goDeep.Tensor) (*goDeep.Tensor, error) {
+	if set.Dims() != 2 || b.width > 0 && set.Shape()[1] != b.width {
+		expected := []int{1, b.width}
+		if set.Dims() > 0 {
+			expected[0] = set.Shape()[0]
+		}
+		return nil, &goDeep.ShapeMismatchError{Op: "Set", Layer: 1, Expected: expected, Actual: set.Shape()}
+	}
+	req := &batchRequest{ctx: ctx, set: set, result: make(chan batchResult, 1)}
+	atomic.AddInt64(&b.queued, 1)
+	select {
+	case b.queue <- req:
+	case <-ctx.Done():
+		atomic.AddInt64(&b.queued, -1)
+		return nil, ctx.Err()
+	case <-b.closed:
+		atomic.AddInt64(&b.queued, -1)
+		return nil, ErrClosed
+	}
+
+	select {
+	case r := <-req.result:
+		return r.prediction, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-b.closed:
+		select {
+		case r := <-req.result:
+			return r.prediction, r.err
+		default:
+			return nil, ErrClosed
+		}
+	}
+}
+
+// Close stops a batcher. Queued requests are answered with ErrClosed.
+func (b *Batcher) Close() {
+	b.close.Do(func() { close(b.closed) })
+}
+
+// Metrics of a batcher so far.
+func (b *Batcher) Metrics() BatchMetrics {
+	m := BatchMetrics{
+		QueueDepth:    atomic.LoadInt64(&b.queued),
+		Batches:       atomic.LoadInt64(&b.batches),
+		Requests:      atomic.LoadInt64(&b.requests),
+		Samples:       atomic.LoadInt64(&b.samples),
+		LastBatchSize: atomic.LoadInt64(&b.last),
+		MaxBatchSize:  atomic.LoadInt64(&b.max),
+	}
+	if m.Batches > 0 {
+		m.MeanBatchSize = float64(m.Samples) / float64(m.Batches)
+	}
+	return m
+}
+
+func (b *Batcher) run() {
+	var next *batchRequest
+	for {
+		if next == nil {
+			select {
+			case next = <-b.queue:
+				atomic.AddInt64(&b.queued, -1)
+			case <-b.closed:
+				return
+			}
+		}
+		batch, size := []*batchRequest{next}, rowsOf(next.set)
+		next = nil
+
+		timer := time.NewTimer(b.options.MaxDelay)
+	collect:
+		for size < b.options.MaxBatch {
+			select {
+			case req := <-b.queue:
+				atomic.AddInt64(&b.queued, -1)
+				if size+rowsOf(req.set) > b.options.MaxBatch {
+					next = req
+					break collect
+				}
+				batch, size = append(batch, req), size+rowsOf(req.set)
+			case <-timer.C:
+				break collect
+			case <-b.closed:
+				timer.Stop()
+				return
+			}
+		}
+		timer.Stop()
+		b.predict(batch)
+	}
+}
+
+// predict a batch by a single Recognize call. Requests cancelled while queued are skipped.
+func (b *Batcher) predict(batch []*batchRequest) {
+	var rows [][]float64
+	live := batch[:0]
+	for _, req := range batch {
+		if req.ctx.Err() != nil {
+			continue
+		}
+		live = append(live, req)
+		rows = append(rows, req.set.Rows()...)
+	}
+	if len(live) == 0 {
+		return
+	}
+
+	size := int64(len(rows))
+	atomic.AddInt64(&b.batches, 1)
+	atomic.AddInt64(&b.requests, int64(len(live)))
+	atomic.AddInt64(&b.samples, size)
+	atomic.StoreInt64(&b.last, size)
+	if size > atomic.LoadInt64(&b.max) {
+		atomic.StoreInt64(&b.max, size)
+	}
+
+	set, err := goDeep.FromRows(rows)
+	var prediction *goDeep.Tensor
+	if err == nil {
+		prediction, err = b.network.Recognize(set)
+	}
+	if err != nil && len(live) > 1 {
+		// A failed request is found by predicting requests alone.
+		for _, req := range live {
+			var r batchResult
+			r.prediction, r.err = b.network.Recognize(req.set)
+			req.result <- r
+		}
+		return
+	}
+	from := 0
+	for _, req := range live {
+		to := from + rowsOf(req.set)
+		r := batchResult{err: err}
+		if err == nil {
+			r.prediction, r.err = prediction.Slice(0, from, to)
+		}
+		req.result <- r
+		from = to
+	}
+}
+
+func rowsOf(set *goDeep.Tensor) int {
+	return set.Shape()[0]
+}
@@ -0,0 +1,162 @@
+package serve
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	goDeep "github.com/I159/go_deep"
+)
+
+// countingNetwork counts Recognize calls.
+type countingNetwork struct {
+	goDeep.Network
+	calls int32
+}
+
+func (n *countingNetwork) Recognize(set *goDeep.Tensor) (*goDeep.Tensor, error) {
+	atomic.AddInt32(&n.calls, 1)
+	return n.Network.Recognize(set)
+}
+
+func TestBatcher_Predict(t *testing.T) {
+	n := &countingNetwork{Network: newNetwork(t)}
+	b := NewBatcher(n, BatchOptions{MaxBatch: 4, MaxDelay: time.Minute, QueueSize: 4})
+	defer b.Close()
+
+	samples := [][]float64{{0, 0, 0}, {0, 1, 0}, {1, 0, 0}, {1, 1, 0}}
+	got := make([][]float64, len(samples))
+	errs := make([]error, len(samples))
+	var wg sync.WaitGroup
+	for i := range samples {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			set, _ := goDeep.FromRows(samples[i : i+1])
+			prediction, err := b.Predict(context.Background(), set)
+			if errs[i] = err; err == nil {
+				got[i] = prediction.Data()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	set, _ := goDeep.FromRows(samples)
+	want, err := n.Network.Recognize(set)
+	if err != nil {
+		t.Fatalf("Recognize() error = %v", err)
+	}
+	for i, row := range want.Rows() {
+		if errs[i] != nil {
+			t.Fatalf("Batcher.Predict() error = %v", errs[i])
+		}
+		if !reflect.DeepEqual(got[i], row) {
+			t.Errorf("Batcher.Predict() of sample %d = %v, want %v", i, got[i], row)
+		}
+	}
+	if n.calls != 1 {
+		t.Errorf("Recognize calls = %d, want 1", n.calls)
+	}
+	wantMetrics := BatchMetrics{Batches: 1, Requests: 4, Samples: 4, LastBatchSize: 4, MaxBatchSize: 4, MeanBatchSize: 4}
+	if m := b.Metrics(); m != wantMetrics {
+		t.Errorf("Batcher.Metrics() = %+v, want %+v", m, wantMetrics)
+	}
+}
+
+// failingNetwork fails sets of a negative value.
+type failingNetwork struct {
+	goDeep.Network
+}
+
+func (n failingNetwork) Recognize(set *goDeep.Tensor) (*goDeep.Tensor, error) {
+	for _, v := range set.Data() {
+		if v < 0 {
+			return nil, errors.New("a negative value")
+		}
+	}
+	return n.Network.Recognize(set)
+}
+
+func TestBatcher_Predict_malformed(t *testing.T) {
+	n := &countingNetwork{Network: failingNetwork{newNetwork(t)}}
+	b := NewBatcher(n, BatchOptions{MaxBatch: 3, MaxDelay: time.Minute, QueueSize: 4})
+	defer b.Close()
+
+	wide, _ := goDeep.FromRows([][]float64{{0, 1, 0, 1}})
+	var shapeErr *goDeep.ShapeMismatchError
+	if _, err := b.Predict(context.Background(), wide); !errors.As(err, &shapeErr) {
+		t.Errorf("Batcher.Predict() of a wide set error = %v, want *ShapeMismatchError", err)
+	}
+
+	samples := [][]float64{{0, 0, 0}, {-1, 1, 0}, {1, 0, 0}}
+	got := make([]*goDeep.Tensor, len(samples))
+	errs := make([]error, len(samples))
+	var wg sync.WaitGroup
+	for i := range samples {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			set, _ := goDeep.FromRows(samples[i : i+1])
+			got[i], errs[i] = b.Predict(context.Background(), set)
+		}(i)
+	}
+	wg.Wait()
+
+	for i, sample := range samples {
+		if wantErr := sample[0] < 0; (errs[i] != nil) != wantErr {
+			t.Fatalf("Batcher.Predict() of sample %d error = %v, wantErr %v", i, errs[i], wantErr)
+		}
+		if errs[i] != nil {
+			continue
+		}
+		set, _ := goDeep.FromRows(samples[i : i+1])
+		want, err := n.Network.Recognize(set)
+		if err != nil {
+			t.Fatalf("Recognize() error = %v", err)
+		}
+		if !reflect.DeepEqual(got[i].Data(), want.Data()) {
+			t.Errorf("Batcher.Predict() of sample %d = %v, want %v", i, got[i].Data(), want.Data())
+		}
+	}
+	// A failed batch and a prediction of every request alone.
+	if n.calls != 4 {
+		t.Errorf("Recognize calls = %d, want 4", n.calls)
+	}
+}
+
+func TestBatcher_delay(t *testing.T) {
+	b := NewBatcher(newNetwork(t), BatchOptions{MaxBatch: 8, MaxDelay: time.Millisecond})
+	defer b.Close()
+
+	set, _ := goDeep.FromRows([][]float64{{0, 1, 0}, {1, 1, 0}})
+	prediction, err := b.Predict(context.Background(), set)
+	if err != nil {
+		t.Fatalf("Batcher.Predict() error = %v", err)
+	}
+	if got := prediction.Shape(); !reflect.DeepEqual(got, []int{2, 2}) {
+		t.Errorf("Batcher.Predict() shape = %v, want [2 2]", got)
+	}
+	if m := b.Metrics(); m.LastBatchSize != 2 || m.QueueDepth != 0 {
+		t.Errorf("Batcher.Metrics() = %+v, want a batch of 2 and an empty queue", m)
+	}
+}
+
+func TestBatcher_stopped(t *testing.T) {
+	set, _ := goDeep.FromRows([][]float64{{0, 1, 0}})
+
+	b := NewBatcher(newNetwork(t), BatchOptions{MaxBatch: 8, MaxDelay: time.Minute})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	if _, err := b.Predict(ctx, set); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Batcher.Predict() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	b.Close()
+	if _, err := b.Predict(context.Background(), set); !errors.Is(err, ErrClosed) {
+		t.Errorf("Batcher.Predict() error = %v, want %v", err, ErrClosed)
+	}
+}
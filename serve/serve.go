@@ -0,0 +1,227 @@
+/*
+Package serve serves predictions of a trained network over HTTP.
+
+Endpoints of a handler:
+
+	POST /predict   {"input": [0, 1]} or a batch {"inputs": [[0, 1], [1, 1]]}
+	                answers {"output": [...]} or {"outputs": [[...], ...]}
+	GET  /health    answers {"status": "ok"}
+	GET  /metadata  answers a model config and widths of inputs and outputs
+	GET  /metrics   answers BatchMetrics of a handler with batching
+
+Inputs are samples of an input layer width. A sample of a dense input with a bias may
+lack a bias slot, it is appended. Errors are answered as {"error": "..."}.
+*/
+package serve
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	goDeep "github.com/I159/go_deep"
+)
+
+// maxBodySize limits a size of a prediction request.
+const maxBodySize = 8 << 20
+
+// Metadata describes a served model.
+type Metadata struct {
+	Config      goDeep.ModelConfig `json:"config"`
+	InputWidth  int                `json:"inputWidth"`
+	OutputWidth int                `json:"outputWidth"`
+}
+
+// PredictRequest is a single sample or a batch of samples.
+type PredictRequest struct {
+	Input  []float64   `json:"input,omitempty"`
+	Inputs [][]float64 `json:"inputs,omitempty"`
+}
+
+// PredictResponse is a prediction of a single sample or predictions of a batch.
+type PredictResponse struct {
+	Output  []float64   `json:"output,omitempty"`
+	Outputs [][]float64 `json:"outputs,omitempty"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+/*
+Handler serves predictions of a network. Predictions are made one at a time because
+a network keeps a state of propagation, or by batches of concurrent requests with
+WithBatching option.
+*/
+type Handler struct {
+	network  goDeep.Network
+	metadata Metadata
+	bias     bool
+	mu       sync.Mutex
+	mux      *http.ServeMux
+	batching *BatchOptions
+	batcher  *Batcher
+}
+
+// HandlerOption configures a handler.
+type HandlerOption func(*Handler)
+
+// WithBatching predicts concurrent requests by batches, see Batcher.
+func WithBatching(options BatchOptions) HandlerOption {
+	return func(h *Handler) {
+		h.batching = &options
+	}
+}
+
+// NewHandler is a handler initializer. A network must be declared by shapes to be
+// described by metadata. Close stops a handler with batching.
+func NewHandler(n goDeep.Network, options ...HandlerOption) (*Handler, error) {
+	config, err := n.Config()
+	if err != nil {
+		return nil, err
+	}
+	h := &Handler{network: n, metadata: Metadata{Config: config, OutputWidth: config.Output.Size}}
+	for _, option := range options {
+		option(h)
+	}
+	if config.Input != nil {
+		h.metadata.InputWidth = config.Input.Size
+		h.bias = config.Input.Bias != 0
+	} else {
+		h.metadata.InputWidth = config.Embedding.Fields
+	}
+
+	h.mux = http.NewServeMux()
+	h.mux.HandleFunc("/predict", h.predict)
+	h.mux.HandleFunc("/health", h.health)
+	h.mux.HandleFunc("/metadata", h.describe)
+	if h.batching != nil {
+		h.batcher = NewBatcher(n, *h.batching)
+		h.mux.HandleFunc("/metrics", h.metrics)
+	}
+	return h, nil
+}
+
+// NewServer is a server of a handler of a network listening to an address. A handler
+// is closed on a shutdown of a server.
+func NewServer(addr string, n goDeep.Network, options ...HandlerOption) (*http.Server, error) {
+	h, err := NewHandler(n, options...)
+	if err != nil {
+		return nil, err
+	}
+	server := &http.Server{
+		Addr:         addr,
+		Handler:      h,
+		ReadTimeout:  30 * time.Second,
+		WriteTimeout: 30 * time.Second,
+	}
+	server.RegisterOnShutdown(h.Close)
+	return server, nil
+}
+
+// Close stops a batcher of a handler.
+func (h *Handler) Close() {
+	if h.batcher != nil {
+		h.batcher.Close()
+	}
+}
+
+// ServeHTTP routes a request to an endpoint.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *Handler) predict(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("%s is not allowed, use POST", r.Method))
+		return
+	}
+	var req PredictRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	batched := req.Inputs != nil
+	samples := req.Inputs
+	if !batched {
+		samples = [][]float64{req.Input}
+	}
+	set, err := h.samples(samples)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	prediction, err := h.recognize(r.Context(), set)
+	if errors.Is(err, ErrClosed) {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if batched {
+		writeJSON(w, http.StatusOK, PredictResponse{Outputs: prediction.Rows()})
+	} else {
+		writeJSON(w, http.StatusOK, PredictResponse{Output: prediction.Data()})
+	}
+}
+
+func (h *Handler) recognize(ctx context.Context, set *goDeep.Tensor) (*goDeep.Tensor, error) {
+	if h.batcher != nil {
+		return h.batcher.Predict(ctx, set)
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.network.Recognize(set)
+}
+
+// samples of a request validated against an input width.
+func (h *Handler) samples(samples [][]float64) (*goDeep.Tensor, error) {
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("no samples")
+	}
+	width := h.metadata.InputWidth
+	rows := make([][]float64, len(samples))
+	for i, sample := range samples {
+		switch {
+		case len(sample) == width:
+			rows[i] = sample
+		case h.bias && len(sample) == width-1:
+			rows[i] = append(sample[:len(sample):len(sample)], 0)
+		default:
+			return nil, fmt.Errorf("sample %d has %d values, an input layer has %d", i, len(sample), width)
+		}
+	}
+	return goDeep.FromRows(rows)
+}
+
+func (h *Handler) health(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (h *Handler) describe(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.metadata)
+}
+
+func (h *Handler) metrics(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.batcher.Metrics())
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
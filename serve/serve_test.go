@@ -0,0 +1,119 @@
+package serve
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	goDeep "github.com/I159/go_deep"
+)
+
+func newNetwork(t *testing.T) goDeep.Network {
+	n, err := goDeep.NewFromConfig(goDeep.ModelConfig{
+		Input:  &goDeep.LayerConfig{Size: 3, LearningRate: .1, Bias: 1},
+		Hidden: []goDeep.LayerConfig{{Size: 4, LearningRate: .1, Bias: 1, Activation: "sigmoid"}},
+		Output: goDeep.LayerConfig{Size: 2, Activation: "sigmoid", Cost: "quadratic"},
+	})
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	return n
+}
+
+func TestHandler(t *testing.T) {
+	h, err := NewHandler(newNetwork(t))
+	if err != nil {
+		t.Fatalf("NewHandler() error = %v", err)
+	}
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "single", method: http.MethodPost, path: "/predict", body: `{"input": [0, 1, 0]}`, wantStatus: http.StatusOK, wantBody: `"output":[`},
+		{name: "withoutBiasSlot", method: http.MethodPost, path: "/predict", body: `{"input": [0, 1]}`, wantStatus: http.StatusOK, wantBody: `"output":[`},
+		{name: "batch", method: http.MethodPost, path: "/predict", body: `{"inputs": [[0, 1], [1, 1]]}`, wantStatus: http.StatusOK, wantBody: `"outputs":[[`},
+		{name: "wrongWidth", method: http.MethodPost, path: "/predict", body: `{"inputs": [[0, 1], [1, 1, 1, 1]]}`, wantStatus: http.StatusBadRequest, wantBody: "sample 1 has 4 values"},
+		{name: "noSamples", method: http.MethodPost, path: "/predict", body: `{"inputs": []}`, wantStatus: http.StatusBadRequest, wantBody: "no samples"},
+		{name: "malformed", method: http.MethodPost, path: "/predict", body: `{"input": "0, 1"}`, wantStatus: http.StatusBadRequest, wantBody: `"error"`},
+		{name: "wrongMethod", method: http.MethodGet, path: "/predict", wantStatus: http.StatusMethodNotAllowed, wantBody: "use POST"},
+		{name: "health", method: http.MethodGet, path: "/health", wantStatus: http.StatusOK, wantBody: `"status":"ok"`},
+		{name: "metadata", method: http.MethodGet, path: "/metadata", wantStatus: http.StatusOK, wantBody: `"inputWidth":3,"outputWidth":2`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("body = %s, want it containing %s", rec.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestHandler_predictBatch(t *testing.T) {
+	n := newNetwork(t)
+	h, err := NewHandler(n)
+	if err != nil {
+		t.Fatalf("NewHandler() error = %v", err)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/predict", strings.NewReader(`{"inputs": [[0, 1, 0], [1, 1, 0]]}`)))
+
+	var got PredictResponse
+	if err = json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("decoding a response error = %v", err)
+	}
+	set, _ := goDeep.FromRows([][]float64{{0, 1, 0}, {1, 1, 0}})
+	want, err := n.Recognize(set)
+	if err != nil {
+		t.Fatalf("Recognize() error = %v", err)
+	}
+	for i, row := range want.Rows() {
+		for j, v := range row {
+			if got.Outputs[i][j] != v {
+				t.Fatalf("outputs = %v, want %v", got.Outputs, want.Rows())
+			}
+		}
+	}
+}
+
+func TestHandler_batching(t *testing.T) {
+	h, err := NewHandler(newNetwork(t), WithBatching(BatchOptions{MaxBatch: 4, MaxDelay: time.Millisecond}))
+	if err != nil {
+		t.Fatalf("NewHandler() error = %v", err)
+	}
+	defer h.Close()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/predict", strings.NewReader(`{"inputs": [[0, 1], [1, 1]]}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	var got BatchMetrics
+	if err = json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("decoding metrics error = %v", err)
+	}
+	if got.Batches != 1 || got.LastBatchSize != 2 {
+		t.Errorf("metrics = %+v, want a batch of 2", got)
+	}
+
+	h.Close()
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/predict", strings.NewReader(`{"input": [0, 1]}`)))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status of a closed handler = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+}
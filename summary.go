@@ -0,0 +1,133 @@
+package goDeep
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+/*
+LayerSummary describes a layer of a network. An output shape is a shape of signals of
+a sample a layer passes on, a bias signal included. Parameters of a layer are its synapses
+to a next layer and embeddings, Bytes is a memory of parameters of the layer.
+*/
+type LayerSummary struct {
+	Name        string
+	Type        string
+	OutputShape []int
+	Bias        bool
+	Activation  string
+	Cost        string
+
+	Trainable, NonTrainable int
+	Bytes                   int
+}
+
+// Parameters is a number of parameters of a layer.
+func (l LayerSummary) Parameters() int {
+	return l.Trainable + l.NonTrainable
+}
+
+/*
+ModelSummary describes layers of a network. Bytes is a memory of parameters, TrainingBytes
+adds corrections accumulated for trainable parameters while learning.
+*/
+type ModelSummary struct {
+	Layers    []LayerSummary
+	Precision Precision
+
+	Trainable, NonTrainable int
+	Bytes, TrainingBytes    int
+}
+
+// Summary describes layers of a network, parameter counts and memory estimates.
+func Summary(n Network) (s ModelSummary, err error) {
+	c, err := n.Config()
+	if err != nil {
+		return
+	}
+	s.Precision = Float64
+	if c.Precision != "" {
+		s.Precision = c.Precision
+	}
+	size := 8
+	if s.Precision == Float32 {
+		size = 4
+	}
+
+	input := LayerSummary{Name: "input", Type: "dense"}
+	if c.Input != nil {
+		input.Bias = c.Input.Bias != 0
+	} else {
+		input.Type = "embedding"
+		input.Bias = c.Embedding.Bias != 0
+	}
+	layers := []LayerSummary{input}
+	for i, h := range c.Hidden {
+		layers = append(layers, LayerSummary{Name: fmt.Sprintf("hidden[%d]", i), Type: "dense", Bias: h.Bias != 0, Activation: h.Activation})
+	}
+
+	params := n.parameters()
+	for i := range layers {
+		l := &layers[i]
+		for _, p := range params {
+			if !inGroup(p.name, l.Name) {
+				continue
+			}
+			if *p.frozen {
+				l.NonTrainable += p.value.Size()
+			} else {
+				l.Trainable += p.value.Size()
+			}
+			if strings.HasSuffix(p.name, ".synapses") {
+				l.OutputShape = []int{p.value.Shape()[0]}
+			}
+		}
+		l.Bytes = l.Parameters() * size
+	}
+	layers = append(layers, LayerSummary{
+		Name:        "output",
+		Type:        "output",
+		OutputShape: []int{c.Output.Size},
+		Activation:  c.Output.Activation,
+		Cost:        c.Output.Cost,
+	})
+
+	s.Layers = layers
+	for _, l := range layers {
+		s.Trainable += l.Trainable
+		s.NonTrainable += l.NonTrainable
+		s.Bytes += l.Bytes
+	}
+	s.TrainingBytes = s.Bytes + s.Trainable*size
+	return s, nil
+}
+
+// String renders a summary as a table of layers and totals.
+func (s ModelSummary) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "LAYER\tTYPE\tOUTPUT\tBIAS\tACTIVATION\tCOST\tTRAINABLE\tNON-TRAINABLE\tMEMORY")
+	for _, l := range s.Layers {
+		fmt.Fprintf(w, "%s\t%s\t%v\t%t\t%s\t%s\t%d\t%d\t%s\n",
+			l.Name, l.Type, l.OutputShape, l.Bias, l.Activation, l.Cost, l.Trainable, l.NonTrainable, byteSize(l.Bytes))
+	}
+	w.Flush()
+	fmt.Fprintf(&b, "parameters: %d, trainable: %d, non-trainable: %d\n", s.Trainable+s.NonTrainable, s.Trainable, s.NonTrainable)
+	fmt.Fprintf(&b, "memory: %s of %s parameters, %s learning\n", byteSize(s.Bytes), s.Precision, byteSize(s.TrainingBytes))
+	return b.String()
+}
+
+// byteSize of a memory in binary units.
+func byteSize(bytes int) string {
+	value, units := float64(bytes), []string{"B", "KiB", "MiB", "GiB"}
+	unit := 0
+	for value >= 1024 && unit < len(units)-1 {
+		value /= 1024
+		unit++
+	}
+	if unit == 0 {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	return fmt.Sprintf("%.1f %s", value, units[unit])
+}
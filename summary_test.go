@@ -0,0 +1,87 @@
+package goDeep
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSummary(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     ModelConfig
+		frozen     []string
+		wantLayers []LayerSummary
+		wantBytes  [2]int
+	}{
+		{
+			name: "dense",
+			config: ModelConfig{
+				Input:  &LayerConfig{Size: 3, LearningRate: .5, Bias: 1},
+				Hidden: []LayerConfig{{Size: 4, LearningRate: .5, Bias: 1, Activation: "sigmoid"}},
+				Output: LayerConfig{Size: 2, Activation: "softmax", Cost: "quadratic"},
+			},
+			frozen: []string{"input"},
+			wantLayers: []LayerSummary{
+				{Name: "input", Type: "dense", OutputShape: []int{3}, Bias: true, NonTrainable: 9, Bytes: 72},
+				{Name: "hidden[0]", Type: "dense", OutputShape: []int{4}, Bias: true, Activation: "sigmoid", Trainable: 8, Bytes: 64},
+				{Name: "output", Type: "output", OutputShape: []int{2}, Activation: "softmax", Cost: "quadratic"},
+			},
+			wantBytes: [2]int{136, 200},
+		},
+		{
+			name: "embeddingFloat32",
+			config: ModelConfig{
+				Embedding: &EmbeddingConfig{Vocabulary: 5, Dimension: 2, Fields: 2, LearningRate: .1, Frozen: true},
+				Hidden:    []LayerConfig{{Size: 3, LearningRate: .1, Activation: "relu"}},
+				Output:    LayerConfig{Size: 1, Activation: "sigmoid", Cost: "quadratic"},
+				Precision: Float32,
+			},
+			wantLayers: []LayerSummary{
+				{Name: "input", Type: "embedding", OutputShape: []int{4}, Trainable: 12, NonTrainable: 10, Bytes: 88},
+				{Name: "hidden[0]", Type: "dense", OutputShape: []int{3}, Activation: "relu", Trainable: 3, Bytes: 12},
+				{Name: "output", Type: "output", OutputShape: []int{1}, Activation: "sigmoid", Cost: "quadratic"},
+			},
+			wantBytes: [2]int{100, 160},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := NewFromConfig(tt.config)
+			if err != nil {
+				t.Fatalf("NewFromConfig() error = %v", err)
+			}
+			if err = SetTrainable(n, false, tt.frozen...); err != nil {
+				t.Fatalf("SetTrainable() error = %v", err)
+			}
+			s, err := Summary(n)
+			if err != nil {
+				t.Fatalf("Summary() error = %v", err)
+			}
+			if !reflect.DeepEqual(s.Layers, tt.wantLayers) {
+				t.Errorf("Summary() layers = %+v, want %+v", s.Layers, tt.wantLayers)
+			}
+			if got := [2]int{s.Bytes, s.TrainingBytes}; got != tt.wantBytes {
+				t.Errorf("Summary() bytes = %v, want %v", got, tt.wantBytes)
+			}
+			var parameters int
+			for _, p := range n.parameters() {
+				parameters += p.value.Size()
+			}
+			if s.Trainable+s.NonTrainable != parameters {
+				t.Errorf("Summary() parameters = %d, want %d", s.Trainable+s.NonTrainable, parameters)
+			}
+			if table := s.String(); !strings.Contains(table, "hidden[0]") || !strings.Contains(table, "trainable: ") {
+				t.Errorf("ModelSummary.String() = %q, want a table of layers and totals", table)
+			}
+		})
+	}
+}
+
+func TestByteSize(t *testing.T) {
+	for bytes, want := range map[int]string{0: "0 B", 1023: "1023 B", 1536: "1.5 KiB", 3 << 20: "3.0 MiB"} {
+		if got := byteSize(bytes); got != want {
+			t.Errorf("byteSize(%d) = %q, want %q", bytes, got, want)
+		}
+	}
+}
@@ -8,8 +8,16 @@ import (
 
 const scalingBase = .7
 
+// newRandom is a source of initial weights of a seed, a zero seed is a current time.
+func newRandom(seed int64) *rand.Rand {
+	if seed == 0 {
+		seed = time.Now().UTC().UnixNano()
+	}
+	return rand.New(rand.NewSource(seed))
+}
+
 type synapseInitializer interface {
-	init() [][]float64
+	init() *Tensor
 }
 
 type denseSynapses struct {
@@ -17,6 +25,7 @@ type denseSynapses struct {
 	synapses         [][]float64
 	bias             float64
 	nextBias         bool
+	random           *rand.Rand
 }
 
 func (s *denseSynapses) randomInit() {
@@ -29,11 +38,10 @@ func (s *denseSynapses) randomInit() {
 		next--
 	}
 
-	rand.Seed(time.Now().UTC().UnixNano())
 	for i := 0; i < curr; i++ {
 		s.synapses = append(s.synapses, []float64{})
 		for j := 0; j < next; j++ {
-			s.synapses[i] = append(s.synapses[i], rand.Float64()-0.5)
+			s.synapses[i] = append(s.synapses[i], s.random.Float64()-0.5)
 		}
 	}
 }
@@ -53,12 +61,18 @@ func (s *denseSynapses) addBiases() {
 	}
 }
 
-func (s *denseSynapses) init() [][]float64 {
+func (s *denseSynapses) init() *Tensor {
 	s.randomInit()
 	if s.bias != 0 {
 		s.addBiases()
 	}
-	return s.synapses
+	return s.tensor()
+}
+
+func (s *denseSynapses) tensor() *Tensor {
+	// Rows are of the same size by construction.
+	t, _ := FromRows(s.synapses)
+	return t
 }
 
 type hiddenDenseSynapses struct {
@@ -90,8 +104,8 @@ func (s *hiddenDenseSynapses) nguyenWiderow() {
 	}
 }
 
-func (s hiddenDenseSynapses) init() [][]float64 {
+func (s hiddenDenseSynapses) init() *Tensor {
 	s.denseSynapses.init()
 	s.nguyenWiderow()
-	return s.synapses
+	return s.tensor()
 }
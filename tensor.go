@@ -0,0 +1,669 @@
+package goDeep
+
+import (
+	"fmt"
+	"math"
+)
+
+/*
+Tensor is an n-dimensional array of float64 or float32 values, see Precision.
+
+Elements are stored in a flat slice addressed by strides, so reshaping, slicing,
+transposition and broadcasting produce views sharing memory with an original tensor
+wherever it is possible. Element-wise operations broadcast their operands as NumPy does:
+shapes are aligned by trailing dimensions, a dimension of size one stretches to match
+the other operand.
+
+A tensor of no dimensions is a scalar. At and Set out of range panic like slices do, Index
+and Slice return errors. Operations on tensors of inconsistent shapes return
+a *ShapeMismatchError.
+*/
+type Tensor struct {
+	data    storage
+	shape   []int
+	strides []int
+	offset  int
+}
+
+// NewTensor creates a tensor of a shape over row-major data. Data is not copied.
+func NewTensor(data []float64, shape ...int) (*Tensor, error) {
+	for _, d := range shape {
+		if d < 0 {
+			return nil, &ShapeMismatchError{Op: "NewTensor", Expected: []int{len(data)}, Actual: shape}
+		}
+	}
+	if len(data) != shapeSize(shape) {
+		return nil, &ShapeMismatchError{Op: "NewTensor", Expected: []int{shapeSize(shape)}, Actual: []int{len(data)}}
+	}
+	return newTensor(data, shape), nil
+}
+
+func newTensor(data []float64, shape []int) *Tensor {
+	shape = append([]int{}, shape...)
+	return &Tensor{data: elements[float64](data), shape: shape, strides: contiguousStrides(shape)}
+}
+
+// Zeros creates a tensor of a shape filled with zeros.
+func Zeros(shape ...int) *Tensor {
+	return newTensor(make([]float64, shapeSize(shape)), shape)
+}
+
+// Scalar creates a tensor of no dimensions.
+func Scalar(v float64) *Tensor {
+	return newTensor([]float64{v}, nil)
+}
+
+// Vector creates a one dimensional tensor. Data is not copied.
+func Vector(data []float64) *Tensor {
+	return newTensor(data, []int{len(data)})
+}
+
+// FromRows creates a two dimensional tensor out of rows of the same size.
+func FromRows(rows [][]float64) (*Tensor, error) {
+	var cols int
+	if len(rows) > 0 {
+		cols = len(rows[0])
+	}
+	data := make([]float64, 0, len(rows)*cols)
+	for _, row := range rows {
+		if len(row) != cols {
+			return nil, &ShapeMismatchError{Op: "FromRows", Expected: []int{cols}, Actual: []int{len(row)}}
+		}
+		data = append(data, row...)
+	}
+	return newTensor(data, []int{len(rows), cols}), nil
+}
+
+// Stack joins tensors of the same shape along a new first dimension. A result is of
+// a precision of the first tensor.
+func Stack(tensors ...*Tensor) (*Tensor, error) {
+	if len(tensors) == 0 {
+		return Zeros(0), nil
+	}
+	shape := tensors[0].shape
+	data := makeStorage(tensors[0].Precision(), len(tensors)*shapeSize(shape))
+	var i int
+	for _, t := range tensors {
+		if !sameShape(t.shape, shape) {
+			return nil, &ShapeMismatchError{Op: "Stack", Expected: tensors[0].Shape(), Actual: t.Shape()}
+		}
+		t.each(func(pos int) {
+			data.set(i, t.data.at(pos))
+			i++
+		})
+	}
+	shape = append([]int{len(tensors)}, shape...)
+	return &Tensor{data: data, shape: shape, strides: contiguousStrides(shape)}, nil
+}
+
+// Shape returns sizes of the tensor dimensions.
+func (t *Tensor) Shape() []int {
+	if t == nil {
+		return nil
+	}
+	return append([]int{}, t.shape...)
+}
+
+// Strides returns steps in the underlying data between neighbour elements of each dimension.
+func (t *Tensor) Strides() []int {
+	if t == nil {
+		return nil
+	}
+	return append([]int{}, t.strides...)
+}
+
+// Dims returns a number of the tensor dimensions.
+func (t *Tensor) Dims() int {
+	if t == nil {
+		return 0
+	}
+	return len(t.shape)
+}
+
+// Size returns a number of the tensor elements.
+func (t *Tensor) Size() int {
+	if t == nil {
+		return 0
+	}
+	return shapeSize(t.shape)
+}
+
+func (t *Tensor) position(idx []int) int {
+	if len(idx) != len(t.shape) {
+		panic(fmt.Sprintf("goDeep: %d indices for a tensor of %d dimensions", len(idx), len(t.shape)))
+	}
+	pos := t.offset
+	for d, i := range idx {
+		if i < 0 || i >= t.shape[d] {
+			panic(fmt.Sprintf("goDeep: index %d out of range [0:%d] of dimension %d", i, t.shape[d], d))
+		}
+		pos += i * t.strides[d]
+	}
+	return pos
+}
+
+// At returns an element by its indices, one per dimension.
+func (t *Tensor) At(idx ...int) float64 {
+	return t.data.at(t.position(idx))
+}
+
+// Set assigns an element by its indices, one per dimension.
+func (t *Tensor) Set(v float64, idx ...int) {
+	t.data.set(t.position(idx), v)
+}
+
+func (t *Tensor) isContiguous() bool {
+	stride := 1
+	for d := len(t.shape) - 1; d >= 0; d-- {
+		if t.shape[d] != 1 && t.strides[d] != stride {
+			return false
+		}
+		stride *= t.shape[d]
+	}
+	return true
+}
+
+// Data returns a copy of the tensor elements in a row-major order.
+func (t *Tensor) Data() []float64 {
+	if t == nil {
+		return nil
+	}
+	data := make([]float64, t.Size())
+	t.copyTo(elements[float64](data))
+	return data
+}
+
+// Clone returns a contiguous copy of the tensor of the same precision.
+func (t *Tensor) Clone() *Tensor {
+	return t.cloneAs(t.Precision())
+}
+
+// Rows returns a two dimensional representation of the tensor: trailing dimensions
+// are flattened into rows, a vector or a scalar is a single row.
+func (t *Tensor) Rows() [][]float64 {
+	if t == nil {
+		return nil
+	}
+	data := t.Data()
+	if len(t.shape) < 2 {
+		return [][]float64{data}
+	}
+	rows := make([][]float64, t.shape[0])
+	if t.shape[0] == 0 {
+		return rows
+	}
+	cols := len(data) / t.shape[0]
+	for i := range rows {
+		rows[i] = data[i*cols : (i+1)*cols : (i+1)*cols]
+	}
+	return rows
+}
+
+// Reshape returns a tensor of the same elements in a new shape. A single dimension
+// may be -1, its size is inferred. The result shares data with a contiguous tensor.
+func (t *Tensor) Reshape(shape ...int) (*Tensor, error) {
+	shape = append([]int{}, shape...)
+	infer, known := -1, 1
+	for d, s := range shape {
+		switch {
+		case s == -1 && infer == -1:
+			infer = d
+		case s < 0:
+			return nil, &ShapeMismatchError{Op: "Reshape", Expected: t.Shape(), Actual: shape}
+		default:
+			known *= s
+		}
+	}
+	if infer != -1 {
+		if known == 0 {
+			// Any size of an inferred dimension fits no elements.
+			return nil, &ShapeMismatchError{Op: "Reshape", Expected: t.Shape(), Actual: shape}
+		}
+		shape[infer] = t.Size() / known
+	}
+	if shapeSize(shape) != t.Size() {
+		return nil, &ShapeMismatchError{Op: "Reshape", Expected: t.Shape(), Actual: shape}
+	}
+
+	if !t.isContiguous() {
+		t = t.Clone()
+	}
+	return &Tensor{
+		data:    t.data,
+		shape:   shape,
+		strides: contiguousStrides(shape),
+		offset:  t.offset,
+	}, nil
+}
+
+// Slice returns a view of elements from-to of a dimension.
+func (t *Tensor) Slice(dim, from, to int) (*Tensor, error) {
+	if dim < 0 || dim >= len(t.shape) || from < 0 || to < from || to > t.shape[dim] {
+		return nil, locatedError{
+			fmt.Sprintf("Slice is out of a tensor range.\nShape: %v\nDimension: %d\nRange: %d:%d", t.shape, dim, from, to),
+		}
+	}
+	shape := t.Shape()
+	shape[dim] = to - from
+	return &Tensor{
+		data:    t.data,
+		shape:   shape,
+		strides: t.Strides(),
+		offset:  t.offset + from*t.strides[dim],
+	}, nil
+}
+
+// Index returns a view of an i-th sub-tensor along the first dimension,
+// e.g. a row of a matrix or a sample of a dataset.
+func (t *Tensor) Index(i int) (*Tensor, error) {
+	if len(t.shape) == 0 || i < 0 || i >= t.shape[0] {
+		return nil, locatedError{
+			fmt.Sprintf("Index is out of a tensor range.\nShape: %v\nIndex: %d", t.shape, i),
+		}
+	}
+	return &Tensor{
+		data:    t.data,
+		shape:   t.Shape()[1:],
+		strides: t.Strides()[1:],
+		offset:  t.offset + i*t.strides[0],
+	}, nil
+}
+
+// gather sub-tensors of indices along the first dimension into a new tensor.
+func (t *Tensor) gather(indices []int) (*Tensor, error) {
+	var err error
+	rows := make([]*Tensor, len(indices))
+	for i, idx := range indices {
+		if rows[i], err = t.Index(idx); err != nil {
+			return nil, err
+		}
+	}
+	return Stack(rows...)
+}
+
+// Transpose returns a view of the tensor with a reversed order of dimensions.
+func (t *Tensor) Transpose() *Tensor {
+	n := len(t.shape)
+	shape, strides := make([]int, n), make([]int, n)
+	for d := range t.shape {
+		shape[n-1-d] = t.shape[d]
+		strides[n-1-d] = t.strides[d]
+	}
+	return &Tensor{data: t.data, shape: shape, strides: strides, offset: t.offset}
+}
+
+// broadcastTo returns a view of the tensor stretched to a shape.
+func (t *Tensor) broadcastTo(shape []int) (*Tensor, error) {
+	lead := len(shape) - len(t.shape)
+	if lead < 0 {
+		return nil, &ShapeMismatchError{Op: "Broadcast", Expected: shape, Actual: t.Shape()}
+	}
+	strides := make([]int, len(shape))
+	for d := range t.shape {
+		switch t.shape[d] {
+		case shape[lead+d]:
+			strides[lead+d] = t.strides[d]
+		case 1:
+			strides[lead+d] = 0
+		default:
+			return nil, &ShapeMismatchError{Op: "Broadcast", Expected: shape, Actual: t.Shape()}
+		}
+	}
+	return &Tensor{data: t.data, shape: append([]int{}, shape...), strides: strides, offset: t.offset}, nil
+}
+
+func broadcastShapes(op string, a, b []int) ([]int, error) {
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+	shape := append([]int{}, a...)
+	lead := len(a) - len(b)
+	for d, s := range b {
+		switch {
+		case s == shape[lead+d] || s == 1:
+		case shape[lead+d] == 1:
+			shape[lead+d] = s
+		default:
+			return nil, &ShapeMismatchError{Op: op, Expected: a, Actual: b}
+		}
+	}
+	return shape, nil
+}
+
+// zip applies an arithmetic to pairs of elements of broadcast tensors, a result is of
+// the narrowest precision of them.
+func (t *Tensor) zip(name string, o *Tensor, op arithmetic) (*Tensor, error) {
+	shape, err := broadcastShapes(name, t.shape, o.shape)
+	if err != nil {
+		return nil, err
+	}
+	a, err := t.broadcastTo(shape)
+	if err != nil {
+		return nil, err
+	}
+	b, err := o.broadcastTo(shape)
+	if err != nil {
+		return nil, err
+	}
+
+	p := narrowest(t.Precision(), o.Precision())
+	pa, ok := planeOf(a)
+	pb, planar := planeOf(b)
+	var out storage
+	switch {
+	case ok && planar && p == Float32:
+		out = zipStorage[float32](op, a.data, b.data, pa, pb)
+	case ok && planar:
+		out = zipStorage[float64](op, a.data, b.data, pa, pb)
+	default:
+		out = makeStorage(p, shapeSize(shape))
+		round := func(v float64) float64 { return v }
+		if p == Float32 {
+			round = func(v float64) float64 { return float64(float32(v)) }
+		}
+		var i int
+		walk(shape, []int{a.offset, b.offset}, [][]int{a.strides, b.strides}, func(pos []int) {
+			out.set(i, op.of(round(a.data.at(pos[0])), round(b.data.at(pos[1]))))
+			i++
+		})
+	}
+	return &Tensor{data: out, shape: shape, strides: contiguousStrides(shape)}, nil
+}
+
+// Add sums tensors element-wise.
+func (t *Tensor) Add(o *Tensor) (*Tensor, error) {
+	return t.zip("Add", o, addition)
+}
+
+// Sub subtracts a tensor element-wise.
+func (t *Tensor) Sub(o *Tensor) (*Tensor, error) {
+	return t.zip("Sub", o, subtraction)
+}
+
+// Mul multiplies tensors element-wise.
+func (t *Tensor) Mul(o *Tensor) (*Tensor, error) {
+	return t.zip("Mul", o, multiplication)
+}
+
+// Div divides by a tensor element-wise.
+func (t *Tensor) Div(o *Tensor) (*Tensor, error) {
+	return t.zip("Div", o, division)
+}
+
+// Apply returns a tensor of a function results for every element.
+func (t *Tensor) Apply(fn func(float64) float64) *Tensor {
+	out, _ := t.applyErr(func(v float64) (float64, error) { return fn(v), nil })
+	return out
+}
+
+func (t *Tensor) applyErr(fn func(float64) (float64, error)) (*Tensor, error) {
+	out := makeStorage(t.Precision(), t.Size())
+	var err error
+	var i int
+	if p, ok := planeOf(t); ok {
+		switch d := out.(type) {
+		case elements[float32]:
+			i, err = mapPlane(d, t.data.(elements[float32]), p, fn)
+		case elements[float64]:
+			i, err = mapPlane(d, t.data.(elements[float64]), p, fn)
+		}
+	} else {
+		var v float64
+		t.each(func(pos int) {
+			if err == nil {
+				if v, err = fn(t.data.at(pos)); err == nil {
+					out.set(i, v)
+					i++
+				}
+			}
+		})
+	}
+	if e, ok := err.(*NumericError); ok {
+		// A value of a vector of activations is a value of a neuron.
+		e.Neuron = i
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Tensor{data: out, shape: t.Shape(), strides: contiguousStrides(t.shape)}, nil
+}
+
+// MatMul is a matrix product of two dimensional tensors of the narrowest precision of them.
+func (t *Tensor) MatMul(o *Tensor) (*Tensor, error) {
+	if len(t.shape) != 2 || len(o.shape) != 2 || t.shape[1] != o.shape[0] {
+		return nil, &ShapeMismatchError{Op: "MatMul", Expected: t.Shape(), Actual: o.Shape()}
+	}
+	shape := []int{t.shape[0], o.shape[1]}
+	var out storage
+	if p := narrowest(t.Precision(), o.Precision()); p == Float32 {
+		out = matMul[float32](t.As(p), o.As(p))
+	} else {
+		out = matMul[float64](t, o)
+	}
+	return &Tensor{data: out, shape: shape, strides: contiguousStrides(shape)}, nil
+}
+
+// Sum returns a sum of all the elements.
+func (t *Tensor) Sum() (sum float64) {
+	t.each(func(pos int) {
+		sum += t.data.at(pos)
+	})
+	return
+}
+
+// Mean returns an arithmetic mean of all the elements.
+func (t *Tensor) Mean() float64 {
+	return t.Sum() / float64(t.Size())
+}
+
+// Max returns the largest element, -Inf for an empty tensor.
+func (t *Tensor) Max() float64 {
+	max := math.Inf(-1)
+	t.each(func(pos int) {
+		max = math.Max(max, t.data.at(pos))
+	})
+	return max
+}
+
+// ArgMax returns a row-major position of the largest element, -1 for an empty tensor.
+func (t *Tensor) ArgMax() int {
+	arg, i, max := -1, 0, math.Inf(-1)
+	t.each(func(pos int) {
+		if v := t.data.at(pos); arg == -1 || v > max {
+			arg, max = i, v
+		}
+		i++
+	})
+	return arg
+}
+
+// SumAxis sums elements along a dimension removing it.
+func (t *Tensor) SumAxis(dim int) (*Tensor, error) {
+	summed, err := t.sumAxis(dim)
+	if err != nil {
+		return nil, err
+	}
+	shape := t.Shape()
+	return summed.Reshape(append(shape[:dim], shape[dim+1:]...)...)
+}
+
+// sumAxis sums elements along a dimension keeping it of size one. Sums are accumulated
+// in a precision of the tensor.
+func (t *Tensor) sumAxis(dim int) (*Tensor, error) {
+	if dim < 0 || dim >= len(t.shape) {
+		return nil, locatedError{
+			fmt.Sprintf("Dimension is out of a tensor range.\nShape: %v\nDimension: %d", t.shape, dim),
+		}
+	}
+	shape := t.Shape()
+	shape[dim] = 1
+	out := &Tensor{data: makeStorage(t.Precision(), shapeSize(shape)), shape: shape, strides: contiguousStrides(shape)}
+	if p, ok := planeOf(t); ok {
+		// A matrix is summed along rows by its first dimension, other planes along columns.
+		alongRows := dim == 0 && len(t.shape) == 2
+		switch d := out.data.(type) {
+		case elements[float32]:
+			sumPlane(d, t.data.(elements[float32]), p, alongRows)
+		case elements[float64]:
+			sumPlane(d, t.data.(elements[float64]), p, alongRows)
+		}
+		return out, nil
+	}
+	// Walk the source and the result together, the result doesn't move along the dimension.
+	strides := out.Strides()
+	strides[dim] = 0
+	walk(t.shape, []int{t.offset, 0}, [][]int{t.strides, strides}, func(pos []int) {
+		out.data.set(pos[1], out.data.at(pos[1])+t.data.at(pos[0]))
+	})
+	return out, nil
+}
+
+// reduceTo sums a broadcast tensor back to a shape it was stretched from.
+func (t *Tensor) reduceTo(shape []int) (out *Tensor, err error) {
+	out = t
+	for len(out.shape) > len(shape) {
+		if out, err = out.SumAxis(0); err != nil {
+			return
+		}
+	}
+	for d, s := range shape {
+		if s == 1 && out.shape[d] != 1 {
+			if out, err = out.sumAxis(d); err != nil {
+				return
+			}
+		}
+	}
+	if !sameShape(out.shape, shape) {
+		return nil, &ShapeMismatchError{Op: "Reduce", Expected: shape, Actual: out.Shape()}
+	}
+	return
+}
+
+// addInPlace accumulates a tensor of the same shape.
+func (t *Tensor) addInPlace(o *Tensor) error {
+	if !sameShape(t.shape, o.shape) {
+		return &ShapeMismatchError{Op: "Add", Expected: t.Shape(), Actual: o.Shape()}
+	}
+	if updateInPlace(addition, t, o) {
+		return nil
+	}
+	walk(t.shape, []int{t.offset, o.offset}, [][]int{t.strides, o.strides}, func(pos []int) {
+		t.data.set(pos[0], t.data.at(pos[0])+o.data.at(pos[1]))
+	})
+	return nil
+}
+
+// mulInPlace multiplies by a tensor of the same shape element-wise.
+func (t *Tensor) mulInPlace(o *Tensor) error {
+	if !sameShape(t.shape, o.shape) {
+		return &ShapeMismatchError{Op: "Mul", Expected: t.Shape(), Actual: o.Shape()}
+	}
+	if updateInPlace(multiplication, t, o) {
+		return nil
+	}
+	walk(t.shape, []int{t.offset, o.offset}, [][]int{t.strides, o.strides}, func(pos []int) {
+		t.data.set(pos[0], t.data.at(pos[0])*o.data.at(pos[1]))
+	})
+	return nil
+}
+
+// copyFrom a tensor of the same shape in place, converting it to a precision of the tensor.
+func (t *Tensor) copyFrom(o *Tensor) error {
+	if !sameShape(t.shape, o.shape) {
+		return &ShapeMismatchError{Op: "Copy", Expected: t.Shape(), Actual: o.Shape()}
+	}
+	if updateInPlace(assignment, t, o) {
+		return nil
+	}
+	walk(t.shape, []int{t.offset, o.offset}, [][]int{t.strides, o.strides}, func(pos []int) {
+		t.data.set(pos[0], o.data.at(pos[1]))
+	})
+	return nil
+}
+
+// descend by corrections of the same shape scaled by a learning rate and a batch size:
+// an element is decreased by rate*correction/batchSize computed in float64.
+func (t *Tensor) descend(corrections *Tensor, rate, batchSize float64) {
+	corrections = corrections.As(t.Precision())
+	pt, ok := planeOf(t)
+	pc, planar := planeOf(corrections)
+	if !ok || !planar {
+		walk(t.shape, []int{t.offset, corrections.offset}, [][]int{t.strides, corrections.strides}, func(pos []int) {
+			t.data.set(pos[0], t.data.at(pos[0])-rate*corrections.data.at(pos[1])/batchSize)
+		})
+		return
+	}
+	switch d := t.data.(type) {
+	case elements[float32]:
+		descendPlane(d, corrections.data.(elements[float32]), pt, pc, rate, batchSize)
+	case elements[float64]:
+		descendPlane(d, corrections.data.(elements[float64]), pt, pc, rate, batchSize)
+	}
+}
+
+// applyInPlace replaces every element by a function of it.
+func (t *Tensor) applyInPlace(fn func(float64) float64) {
+	t.each(func(pos int) {
+		t.data.set(pos, fn(t.data.at(pos)))
+	})
+}
+
+// each calls a function with a data position of every element in a row-major order.
+func (t *Tensor) each(fn func(pos int)) {
+	walk(t.shape, []int{t.offset}, [][]int{t.strides}, func(pos []int) {
+		fn(pos[0])
+	})
+}
+
+// walk iterates a shape in a row-major order moving data positions of several tensors.
+func walk(shape []int, offsets []int, strides [][]int, fn func(pos []int)) {
+	size := shapeSize(shape)
+	pos := append([]int{}, offsets...)
+	idx := make([]int, len(shape))
+	for n := 0; n < size; n++ {
+		fn(pos)
+		for d := len(shape) - 1; d >= 0; d-- {
+			idx[d]++
+			for k := range pos {
+				pos[k] += strides[k][d]
+			}
+			if idx[d] < shape[d] {
+				break
+			}
+			for k := range pos {
+				pos[k] -= strides[k][d] * shape[d]
+			}
+			idx[d] = 0
+		}
+	}
+}
+
+func contiguousStrides(shape []int) []int {
+	strides := make([]int, len(shape))
+	stride := 1
+	for d := len(shape) - 1; d >= 0; d-- {
+		strides[d] = stride
+		stride *= shape[d]
+	}
+	return strides
+}
+
+func shapeSize(shape []int) int {
+	size := 1
+	for _, s := range shape {
+		size *= s
+	}
+	return size
+}
+
+func sameShape(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
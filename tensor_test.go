@@ -0,0 +1,231 @@
+package goDeep
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// rowsOf builds a matrix tensor out of test table rows.
+func rowsOf(rows [][]float64) *Tensor {
+	t, err := FromRows(rows)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestNewTensor(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        []float64
+		shape       []int
+		wantStrides []int
+		wantErr     bool
+	}{
+		{name: "matrix", data: []float64{1, 2, 3, 4, 5, 6}, shape: []int{2, 3}, wantStrides: []int{3, 1}},
+		{name: "cube", data: make([]float64, 24), shape: []int{2, 3, 4}, wantStrides: []int{12, 4, 1}},
+		{name: "scalar", data: []float64{1}, shape: nil, wantStrides: []int{}},
+		{name: "wrongSize", data: []float64{1, 2, 3}, shape: []int{2, 2}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewTensor(tt.data, tt.shape...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewTensor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if _, ok := err.(*ShapeMismatchError); !ok {
+					t.Errorf("NewTensor() error = %T, want *ShapeMismatchError", err)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got.Strides(), tt.wantStrides) {
+				t.Errorf("Tensor.Strides() = %v, want %v", got.Strides(), tt.wantStrides)
+			}
+		})
+	}
+}
+
+func TestTensor_views(t *testing.T) {
+	m := rowsOf([][]float64{{1, 2, 3}, {4, 5, 6}})
+
+	transposed := m.Transpose()
+	if want := [][]float64{{1, 4}, {2, 5}, {3, 6}}; !reflect.DeepEqual(transposed.Rows(), want) {
+		t.Errorf("Tensor.Transpose() = %v, want %v", transposed.Rows(), want)
+	}
+
+	column, err := m.Slice(1, 1, 2)
+	if err != nil {
+		t.Fatalf("Tensor.Slice() error = %v", err)
+	}
+	if want := [][]float64{{2}, {5}}; !reflect.DeepEqual(column.Rows(), want) {
+		t.Errorf("Tensor.Slice() = %v, want %v", column.Rows(), want)
+	}
+
+	row, err := m.Index(1)
+	if err != nil {
+		t.Fatalf("Tensor.Index() error = %v", err)
+	}
+	if want := []float64{4, 5, 6}; !reflect.DeepEqual(row.Data(), want) {
+		t.Errorf("Tensor.Index() = %v, want %v", row.Data(), want)
+	}
+
+	// Views share memory with an original tensor.
+	row.Set(50, 1)
+	if got := m.At(1, 1); got != 50 {
+		t.Errorf("Tensor.At() after a view change = %v, want 50", got)
+	}
+
+	reshaped, err := transposed.Reshape(-1)
+	if err != nil {
+		t.Fatalf("Tensor.Reshape() error = %v", err)
+	}
+	if want := []float64{1, 4, 2, 50, 3, 6}; !reflect.DeepEqual(reshaped.Data(), want) {
+		t.Errorf("Tensor.Reshape() = %v, want %v", reshaped.Data(), want)
+	}
+	if _, err = m.Reshape(4); err == nil {
+		t.Errorf("Tensor.Reshape() to a wrong size error = nil, want error")
+	}
+	var shapeErr *ShapeMismatchError
+	if _, err = Zeros(0, 3).Reshape(0, -1); !errors.As(err, &shapeErr) {
+		t.Errorf("Tensor.Reshape() of an unresolved dimension error = %v, want *ShapeMismatchError", err)
+	}
+	if _, err = m.Index(2); err == nil {
+		t.Errorf("Tensor.Index() out of range error = nil, want error")
+	}
+}
+
+func TestTensor_broadcasting(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    *Tensor
+		want    [][]float64
+		wantErr bool
+	}{
+		{
+			name: "rowVector",
+			a:    rowsOf([][]float64{{1, 2, 3}, {4, 5, 6}}),
+			b:    Vector([]float64{10, 20, 30}),
+			want: [][]float64{{10, 40, 90}, {40, 100, 180}},
+		},
+		{
+			name: "column",
+			a:    rowsOf([][]float64{{1, 2, 3}, {4, 5, 6}}),
+			b:    rowsOf([][]float64{{2}, {3}}),
+			want: [][]float64{{2, 4, 6}, {12, 15, 18}},
+		},
+		{
+			name: "scalar",
+			a:    rowsOf([][]float64{{1, 2}}),
+			b:    Scalar(3),
+			want: [][]float64{{3, 6}},
+		},
+		{
+			name:    "inconsistent",
+			a:       rowsOf([][]float64{{1, 2, 3}}),
+			b:       Vector([]float64{1, 2}),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.a.Mul(tt.b)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Tensor.Mul() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(got.Rows(), tt.want) {
+				t.Errorf("Tensor.Mul() = %v, want %v", got.Rows(), tt.want)
+			}
+		})
+	}
+}
+
+func TestTensor_reductions(t *testing.T) {
+	m := rowsOf([][]float64{{1, 2, 3}, {4, 5, 6}})
+
+	if got := m.Sum(); got != 21 {
+		t.Errorf("Tensor.Sum() = %v, want 21", got)
+	}
+	if got := m.Mean(); got != 3.5 {
+		t.Errorf("Tensor.Mean() = %v, want 3.5", got)
+	}
+	if got := m.Max(); got != 6 {
+		t.Errorf("Tensor.Max() = %v, want 6", got)
+	}
+	if got := m.ArgMax(); got != 5 {
+		t.Errorf("Tensor.ArgMax() = %v, want 5", got)
+	}
+
+	rows, err := m.SumAxis(1)
+	if err != nil {
+		t.Fatalf("Tensor.SumAxis() error = %v", err)
+	}
+	if want := []float64{6, 15}; !reflect.DeepEqual(rows.Data(), want) || rows.Dims() != 1 {
+		t.Errorf("Tensor.SumAxis(1) = %v, want %v", rows.Data(), want)
+	}
+	cols, err := m.SumAxis(0)
+	if err != nil {
+		t.Fatalf("Tensor.SumAxis() error = %v", err)
+	}
+	if want := []float64{5, 7, 9}; !reflect.DeepEqual(cols.Data(), want) {
+		t.Errorf("Tensor.SumAxis(0) = %v, want %v", cols.Data(), want)
+	}
+}
+
+func TestTensor_MatMul(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    *Tensor
+		want    [][]float64
+		wantErr bool
+	}{
+		{
+			name: "matrices",
+			a:    rowsOf([][]float64{{1, 2}, {3, 4}}),
+			b:    rowsOf([][]float64{{5, 6, 7}, {8, 9, 10}}),
+			want: [][]float64{{21, 24, 27}, {47, 54, 61}},
+		},
+		{
+			name: "transposedView",
+			a:    rowsOf([][]float64{{1, 3}, {2, 4}}).Transpose(),
+			b:    rowsOf([][]float64{{1}, {1}}),
+			want: [][]float64{{3}, {7}},
+		},
+		{
+			name:    "inconsistent",
+			a:       rowsOf([][]float64{{1, 2}}),
+			b:       rowsOf([][]float64{{1, 2}}),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.a.MatMul(tt.b)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Tensor.MatMul() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(got.Rows(), tt.want) {
+				t.Errorf("Tensor.MatMul() = %v, want %v", got.Rows(), tt.want)
+			}
+		})
+	}
+}
+
+func TestStack(t *testing.T) {
+	got, err := Stack(Vector([]float64{1, 2}), Vector([]float64{3, 4}))
+	if err != nil {
+		t.Fatalf("Stack() error = %v", err)
+	}
+	if want := [][]float64{{1, 2}, {3, 4}}; !reflect.DeepEqual(got.Rows(), want) {
+		t.Errorf("Stack() = %v, want %v", got.Rows(), want)
+	}
+	_, err = Stack(Vector([]float64{1, 2}), Vector([]float64{3}))
+	var shapeErr *ShapeMismatchError
+	if !errors.As(err, &shapeErr) {
+		t.Fatalf("Stack() of different shapes error = %v, want *ShapeMismatchError", err)
+	}
+	if !reflect.DeepEqual(shapeErr.Expected, []int{2}) || !reflect.DeepEqual(shapeErr.Actual, []int{1}) {
+		t.Errorf("Stack() error shapes = %v, %v, want [2], [1]", shapeErr.Expected, shapeErr.Actual)
+	}
+}
@@ -0,0 +1,97 @@
+package goDeep
+
+import (
+	"fmt"
+	"strings"
+)
+
+// inGroup reports a parameter of a name belongs to a group: a parameter name, e.g.
+// "input.embeddings", or a layer name of all its parameters, e.g. "hidden[0]".
+func inGroup(name, group string) bool {
+	return name == group || strings.HasPrefix(name, group+".")
+}
+
+// groupParameters of a network by groups, a group must match parameters.
+func groupParameters(field string, params []parameter, groups []string) ([][]parameter, error) {
+	grouped := make([][]parameter, len(groups))
+	for i, group := range groups {
+		for _, p := range params {
+			if inGroup(p.name, group) {
+				grouped[i] = append(grouped[i], p)
+			}
+		}
+		if grouped[i] == nil {
+			return nil, &ConfigError{Field: field, Value: group, Reason: "matches no parameters"}
+		}
+	}
+	return grouped, nil
+}
+
+/*
+SetTrainable freezes or unfreezes groups of parameters of a network: layers, e.g. "input"
+or "hidden[0]", or parameters, e.g. "input.embeddings". Frozen parameters are not
+corrected by learning while errors are still propagated through them, so a pretrained
+network is fine-tuned by lower layers frozen.
+*/
+func SetTrainable(n Network, trainable bool, groups ...string) error {
+	grouped, err := groupParameters("groups", n.parameters(), groups)
+	if err != nil {
+		return err
+	}
+	for _, params := range grouped {
+		for _, p := range params {
+			*p.frozen = !trainable
+		}
+	}
+	return nil
+}
+
+// frozen reports a parameter of a name is frozen.
+func frozen(params []parameter, name string) bool {
+	for _, p := range params {
+		if p.name == name {
+			return *p.frozen
+		}
+	}
+	return false
+}
+
+/*
+WithRateMultipliers scales learning rates of groups of parameters, named as by SetTrainable,
+e.g. {"input": 0.1} learns the input layer ten times slower. A parameter name takes
+precedence over a layer one, parameters of no group learn by a full rate of a layer.
+*/
+func WithRateMultipliers(multipliers map[string]float64) LearnOption {
+	return func(c *learnConfig) {
+		c.multipliers = multipliers
+	}
+}
+
+// checkMultipliers of groups of network parameters.
+func checkMultipliers(params []parameter, multipliers map[string]float64) error {
+	for group, m := range multipliers {
+		field := fmt.Sprintf("multipliers[%q]", group)
+		if m < 0 {
+			return &ConfigError{Field: field, Value: m, Reason: "must not be negative"}
+		}
+		if _, err := groupParameters(field, params, []string{group}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scaleCorrections of parameters by multipliers of their groups, so corrections are applied
+// by scaled learning rates.
+func scaleCorrections(params []parameter, multipliers map[string]float64) {
+	for _, p := range params {
+		m, ok := multipliers[p.name]
+		if !ok {
+			layer := strings.SplitN(p.name, ".", 2)[0]
+			if m, ok = multipliers[layer]; !ok {
+				continue
+			}
+		}
+		p.mapCorrections(func(c float64) float64 { return c * m })
+	}
+}
@@ -0,0 +1,122 @@
+package goDeep
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestSetTrainable(t *testing.T) {
+	tests := []struct {
+		name       string
+		groups     []string
+		wantFrozen []string
+	}{
+		{name: "layer", groups: []string{"input"}, wantFrozen: []string{"input.synapses"}},
+		{name: "parameter", groups: []string{"hidden[0].synapses"}, wantFrozen: []string{"hidden[0].synapses"}},
+		{name: "all", groups: []string{"input", "hidden[0]"}, wantFrozen: []string{"input.synapses", "hidden[0].synapses"}},
+	}
+	set := rowsOf([][]float64{{0, 0, 0}, {0, 1, 0}, {1, 0, 0}, {1, 1, 0}})
+	labels := rowsOf([][]float64{{0}, {1}, {1}, {0}})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := prunedNetwork(t)
+			if err := SetTrainable(n, false, tt.groups...); err != nil {
+				t.Fatalf("SetTrainable() error = %v", err)
+			}
+			before := parametersOf(n)
+			for name, value := range before {
+				before[name] = value.Clone()
+			}
+			if _, err := n.Learn(set, labels, 5, 2); err != nil {
+				t.Fatalf("Perceptron.Learn() error = %v", err)
+			}
+			for name, value := range parametersOf(n) {
+				wantFrozen := false
+				for _, f := range tt.wantFrozen {
+					wantFrozen = wantFrozen || f == name
+				}
+				if unchanged := reflect.DeepEqual(value.Rows(), before[name].Rows()); unchanged != wantFrozen {
+					t.Errorf("%s unchanged = %v after learning, want %v", name, unchanged, wantFrozen)
+				}
+			}
+		})
+	}
+
+	n := prunedNetwork(t)
+	if err := SetTrainable(n, false, "input"); err != nil {
+		t.Fatalf("SetTrainable() error = %v", err)
+	}
+	if err := SetTrainable(n, true, "input.synapses"); err != nil {
+		t.Fatalf("SetTrainable() error = %v", err)
+	}
+	if frozen(n.parameters(), "input.synapses") {
+		t.Error("input synapses are frozen after unfreezing")
+	}
+	var configErr *ConfigError
+	if err := SetTrainable(n, false, "output"); !errors.As(err, &configErr) {
+		t.Errorf("SetTrainable() of an unknown group error = %v, want *ConfigError", err)
+	}
+}
+
+func TestWithRateMultipliers(t *testing.T) {
+	set := rowsOf([][]float64{{0, 0, 0}, {0, 1, 0}, {1, 0, 0}, {1, 1, 0}})
+	labels := rowsOf([][]float64{{0}, {1}, {1}, {0}})
+	// A change of synapses of a single batch by multipliers.
+	changes := func(multipliers map[string]float64) map[string]float64 {
+		n := prunedNetwork(t)
+		if _, err := n.Learn(set, labels, 1, 4, WithRateMultipliers(multipliers)); err != nil {
+			t.Fatalf("Perceptron.Learn() error = %v", err)
+		}
+		return map[string]float64{
+			"input":  parametersOf(n)["input.synapses"].At(0, 0) - .1,
+			"hidden": parametersOf(n)["hidden[0].synapses"].At(0, 0) + .3,
+		}
+	}
+	base := changes(nil)
+	got := changes(map[string]float64{"input": 0, "hidden[0].synapses": 2, "hidden[0]": 5})
+	if got["input"] != 0 {
+		t.Errorf("input change = %v, want 0 by a zero multiplier", got["input"])
+	}
+	if want := 2 * base["hidden"]; math.Abs(got["hidden"]-want) > 1e-12 {
+		t.Errorf("hidden change = %v, want %v by a parameter multiplier", got["hidden"], want)
+	}
+
+	n := prunedNetwork(t)
+	for _, multipliers := range []map[string]float64{{"output": 1}, {"input": -1}} {
+		_, err := n.Learn(set, labels, 1, 4, WithRateMultipliers(multipliers))
+		var configErr *ConfigError
+		if !errors.As(err, &configErr) {
+			t.Errorf("Learn() of multipliers %v error = %v, want *ConfigError", multipliers, err)
+		}
+	}
+}
+
+func TestConfig_frozen(t *testing.T) {
+	c := ModelConfig{
+		Input:  &LayerConfig{Size: 3, LearningRate: .5, Bias: 1, Frozen: true},
+		Hidden: []LayerConfig{{Size: 3, LearningRate: .5, Bias: 1, Activation: "sigmoid"}},
+		Output: LayerConfig{Size: 1, Activation: "sigmoid", Cost: "quadratic"},
+	}
+	n, err := NewFromConfig(c)
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	if err = SetTrainable(n, false, "hidden[0]"); err != nil {
+		t.Fatalf("SetTrainable() error = %v", err)
+	}
+	got, err := n.Config()
+	if err != nil {
+		t.Fatalf("Perceptron.Config() error = %v", err)
+	}
+	if !got.Input.Frozen || !got.Hidden[0].Frozen {
+		t.Errorf("Config() = %+v, want frozen input and hidden layers", got)
+	}
+
+	c.Output.Frozen = true
+	var configErr *ConfigError
+	if _, err = NewFromConfig(c); !errors.As(err, &configErr) {
+		t.Errorf("NewFromConfig() of a frozen output error = %v, want *ConfigError", err)
+	}
+}
@@ -1,40 +1,26 @@
 package goDeep
 
-import "fmt"
-
-func areCorrsConsistent(corrSize, layerSize, synapsesSize int) error {
-	if corrSize != layerSize || layerSize != corrSize {
-		return locatedError{
-			fmt.Sprintf(
-				"Synapses, corrections and a current layer size are not consistent.\nCorrections: %d\nSynapses:%d\nLayer: %d\n",
-				corrSize, layerSize, synapsesSize,
-			),
-		}
+func areCorrsConsistent(corrections, synapses *Tensor, currLayerSize, nextLayerSize int) error {
+	shape := []int{currLayerSize, nextLayerSize}
+	if !sameShape(synapses.Shape(), shape) {
+		return &ShapeMismatchError{Op: "Synapses", Expected: shape, Actual: synapses.Shape()}
+	}
+	if !sameShape(corrections.Shape(), shape) {
+		return &ShapeMismatchError{Op: "Corrections", Expected: shape, Actual: corrections.Shape()}
 	}
 	return nil
 }
+
 func checkSynapsesSize(layerSize, synapsesSize int) error {
 	if layerSize == 0 || synapsesSize == 0 || synapsesSize != layerSize {
-		return locatedError{
-			fmt.Sprintf(
-				"Synapses vector is not appropriate size to a current layer size.\nLayer size: %d\nSynapses size: %d",
-				synapsesSize,
-				layerSize,
-			),
-		}
+		return &ShapeMismatchError{Op: "Synapses", Expected: []int{layerSize}, Actual: []int{synapsesSize}}
 	}
 	return nil
 }
 
 func checkInputSize(inputSize, layerSize int) error {
 	if inputSize != layerSize {
-		return locatedError{
-			fmt.Sprintf(
-				"Input is not appropriate size to a current layer size.\nLayer size: %d\nInput size: %d",
-				layerSize,
-				inputSize,
-			),
-		}
+		return &ShapeMismatchError{Op: "Input", Expected: []int{layerSize}, Actual: []int{inputSize}}
 	}
 	return nil
 }
@@ -48,3 +34,26 @@ func areSizesConsistent(inputSize, layerSize, synapsesSize int, bias bool) (err
 	}
 	return
 }
+
+func checkForwarded(graph *variable) error {
+	if graph == nil {
+		return locatedError{"Backward pass requires a forward pass of a layer."}
+	}
+	return nil
+}
+
+// rowsNumber is a size of the first dimension: a number of neurons or samples.
+func rowsNumber(t *Tensor) int {
+	if t.Dims() == 0 {
+		return 0
+	}
+	return t.shape[0]
+}
+
+// colsNumber is a size of the second dimension: a number of synapses of a neuron.
+func colsNumber(t *Tensor) int {
+	if t.Dims() < 2 {
+		return 0
+	}
+	return t.shape[1]
+}
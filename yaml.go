@@ -0,0 +1,420 @@
+package goDeep
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+/*
+ReadModelConfigYAML decodes a YAML declaration of a network as ReadModelConfig does
+a JSON one:
+
+	input: {size: 3, learningRate: 0.1, bias: 1}
+	hidden:
+	  - size: 4
+	    learningRate: 0.1
+	    bias: 1
+	    activation: sigmoid
+	output: {size: 1, activation: sigmoid, cost: quadratic}
+	seed: 7
+
+A subset of YAML a configuration needs is read: block and flow mappings and sequences,
+plain and quoted scalars and comments. Anchors, tags, block scalars and several documents
+are errors.
+*/
+func ReadModelConfigYAML(r io.Reader) (c ModelConfig, err error) {
+	var lines []yamlLine
+	scanner := bufio.NewScanner(r)
+	for number := 1; scanner.Scan(); number++ {
+		var l yamlLine
+		if l, err = newYAMLLine(scanner.Text(), number); err != nil {
+			return
+		}
+		if l.text == "" || number == 1 && l.text == "---" {
+			continue
+		}
+		lines = append(lines, l)
+	}
+	if err = scanner.Err(); err != nil {
+		return
+	}
+
+	p := &yamlParser{lines: lines}
+	var value interface{}
+	if len(lines) > 0 {
+		if value, err = p.block(lines[0].indent); err != nil {
+			return
+		}
+	}
+	if p.pos < len(lines) {
+		return c, p.errorf("unexpected indentation")
+	}
+	if _, ok := value.(map[string]interface{}); !ok {
+		return c, &yamlError{line: 1, reason: "a configuration is not a mapping"}
+	}
+
+	doc, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	return ReadModelConfig(bytes.NewReader(doc))
+}
+
+// yamlError of a line of a document.
+type yamlError struct {
+	line   int
+	reason string
+}
+
+func (e *yamlError) Error() string {
+	return fmt.Sprintf("yaml: line %d: %s", e.line, e.reason)
+}
+
+// yamlLine is a significant text of a line without indentation and a comment.
+type yamlLine struct {
+	number, indent int
+	text           string
+}
+
+func newYAMLLine(text string, number int) (l yamlLine, err error) {
+	l.number = number
+	trimmed := strings.TrimLeft(text, " ")
+	l.indent = len(text) - len(trimmed)
+	if strings.HasPrefix(trimmed, "\t") {
+		return l, &yamlError{line: number, reason: "tabs are not an indentation"}
+	}
+	l.text = strings.TrimRight(withoutComment(trimmed), " \t")
+	if l.text == "..." || l.text == "---" && number > 1 {
+		return l, &yamlError{line: number, reason: "only a single document is read"}
+	}
+	return l, nil
+}
+
+// withoutComment of a text: a comment starts a line or follows a space out of quotes.
+func withoutComment(text string) string {
+	var quote rune
+	for i, c := range text {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || text[i-1] == ' ' || text[i-1] == '\t'):
+			return text[:i]
+		}
+	}
+	return text
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+func (p *yamlParser) errorf(format string, args ...interface{}) error {
+	number := 0
+	if p.pos < len(p.lines) {
+		number = p.lines[p.pos].number
+	} else if len(p.lines) > 0 {
+		number = p.lines[len(p.lines)-1].number
+	}
+	return &yamlError{line: number, reason: fmt.Sprintf(format, args...)}
+}
+
+// block of lines of an indentation: a mapping or a sequence.
+func (p *yamlParser) block(indent int) (interface{}, error) {
+	if isSequenceItem(p.lines[p.pos].text) {
+		return p.sequence(indent)
+	}
+	return p.mapping(indent)
+}
+
+func isSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+func (p *yamlParser) sequence(indent int) (interface{}, error) {
+	items := []interface{}{}
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent && isSequenceItem(p.lines[p.pos].text) {
+		l := p.lines[p.pos]
+		item := strings.TrimLeft(strings.TrimPrefix(l.text, "-"), " ")
+		var value interface{}
+		var err error
+		switch {
+		case item == "":
+			p.pos++
+			value, err = p.nested(indent)
+		case isSequenceItem(item) || mappingKey(item) >= 0:
+			// An item of a block collection is a collection indented by its position.
+			p.lines[p.pos] = yamlLine{number: l.number, indent: indent + len(l.text) - len(item), text: item}
+			value, err = p.block(p.lines[p.pos].indent)
+		default:
+			value, err = p.scalar(item)
+			p.pos++
+		}
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, value)
+	}
+	return items, nil
+}
+
+func (p *yamlParser) mapping(indent int) (interface{}, error) {
+	values := make(map[string]interface{})
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent && !isSequenceItem(p.lines[p.pos].text) {
+		text := p.lines[p.pos].text
+		colon := mappingKey(text)
+		if colon < 0 {
+			return nil, p.errorf("%q is not a key of a mapping", text)
+		}
+		key, err := p.key(strings.TrimSpace(text[:colon]))
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := values[key]; ok {
+			return nil, p.errorf("a duplicate key %q", key)
+		}
+
+		var value interface{}
+		if rest := strings.TrimSpace(text[colon+1:]); rest != "" {
+			value, err = p.scalar(rest)
+			p.pos++
+		} else if p.pos++; p.pos < len(p.lines) && p.lines[p.pos].indent == indent && isSequenceItem(p.lines[p.pos].text) {
+			// Items of a sequence of a key may be indented as the key.
+			value, err = p.sequence(indent)
+		} else {
+			value, err = p.nested(indent)
+		}
+		if err != nil {
+			return nil, err
+		}
+		values[key] = value
+	}
+	return values, nil
+}
+
+// nested block of a deeper indentation or null.
+func (p *yamlParser) nested(indent int) (interface{}, error) {
+	if p.pos < len(p.lines) && p.lines[p.pos].indent > indent {
+		return p.block(p.lines[p.pos].indent)
+	}
+	return nil, nil
+}
+
+// mappingKey is an index of a colon ending a key of a text or -1.
+func mappingKey(text string) int {
+	var quote rune
+	depth := 0
+	for i, c := range text {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+		case c == ':' && depth == 0 && (i == len(text)-1 || text[i+1] == ' '):
+			return i
+		}
+	}
+	return -1
+}
+
+func (p *yamlParser) key(text string) (string, error) {
+	value, err := p.scalar(text)
+	if err != nil {
+		return "", err
+	}
+	if key, ok := value.(string); ok {
+		return key, nil
+	}
+	// Keys of JSON objects are strings.
+	return text, nil
+}
+
+// scalar or a flow collection of a single line.
+func (p *yamlParser) scalar(text string) (interface{}, error) {
+	f := &yamlFlow{text: text}
+	value, err := f.value(false)
+	if err != nil {
+		return nil, p.errorf("%v", err)
+	}
+	if f.skipSpaces(); f.pos < len(f.text) {
+		return nil, p.errorf("unexpected %q after a value", f.text[f.pos:])
+	}
+	return value, nil
+}
+
+// yamlFlow scans values of a line.
+type yamlFlow struct {
+	text string
+	pos  int
+}
+
+func (f *yamlFlow) skipSpaces() {
+	for f.pos < len(f.text) && f.text[f.pos] == ' ' {
+		f.pos++
+	}
+}
+
+// value of a flow collection if inFlow is set or of a block one.
+func (f *yamlFlow) value(inFlow bool) (interface{}, error) {
+	f.skipSpaces()
+	if f.pos == len(f.text) {
+		return nil, fmt.Errorf("a value is missing")
+	}
+	switch c := f.text[f.pos]; c {
+	case '[':
+		return f.sequence()
+	case '{':
+		return f.mapping()
+	case '"', '\'':
+		return f.quoted(c)
+	case '|', '>':
+		return nil, fmt.Errorf("block scalars are not supported")
+	case '&', '*', '!':
+		return nil, fmt.Errorf("anchors, aliases and tags are not supported")
+	}
+	return f.plain(inFlow), nil
+}
+
+func (f *yamlFlow) sequence() (interface{}, error) {
+	f.pos++
+	items := []interface{}{}
+	for {
+		if f.skipSpaces(); f.pos < len(f.text) && f.text[f.pos] == ']' {
+			f.pos++
+			return items, nil
+		}
+		item, err := f.value(true)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		if err = f.separator(']'); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (f *yamlFlow) mapping() (interface{}, error) {
+	f.pos++
+	values := make(map[string]interface{})
+	for {
+		if f.skipSpaces(); f.pos < len(f.text) && f.text[f.pos] == '}' {
+			f.pos++
+			return values, nil
+		}
+		key, err := f.value(true)
+		if err != nil {
+			return nil, err
+		}
+		if f.skipSpaces(); f.pos == len(f.text) || f.text[f.pos] != ':' {
+			return nil, fmt.Errorf("a key %v of a flow mapping has no value", key)
+		}
+		f.pos++
+		name := fmt.Sprint(key)
+		if _, ok := values[name]; ok {
+			return nil, fmt.Errorf("a duplicate key %q", name)
+		}
+		if values[name], err = f.value(true); err != nil {
+			return nil, err
+		}
+		if err = f.separator('}'); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// separator of flow items: a comma or an end of a collection, which is not consumed.
+func (f *yamlFlow) separator(end byte) error {
+	f.skipSpaces()
+	switch {
+	case f.pos == len(f.text):
+		return fmt.Errorf("a flow collection is not closed by %q on a line", end)
+	case f.text[f.pos] == ',':
+		f.pos++
+	case f.text[f.pos] != end:
+		return fmt.Errorf("unexpected %q in a flow collection", f.text[f.pos])
+	}
+	return nil
+}
+
+func (f *yamlFlow) quoted(quote byte) (interface{}, error) {
+	start := f.pos
+	for f.pos++; f.pos < len(f.text); f.pos++ {
+		switch f.text[f.pos] {
+		case '\\':
+			if quote == '"' {
+				f.pos++
+			}
+		case quote:
+			if quote == '\'' && f.pos+1 < len(f.text) && f.text[f.pos+1] == '\'' {
+				f.pos++
+				continue
+			}
+			f.pos++
+			if quote == '\'' {
+				return strings.ReplaceAll(f.text[start+1:f.pos-1], "''", "'"), nil
+			}
+			return strconv.Unquote(f.text[start:f.pos])
+		}
+	}
+	return nil, fmt.Errorf("a quoted scalar is not closed on a line")
+}
+
+// plain scalar up to an end of a line or, in a flow collection, to an indicator of it.
+func (f *yamlFlow) plain(inFlow bool) interface{} {
+	start := f.pos
+	depth := 0
+	for ; f.pos < len(f.text); f.pos++ {
+		c := f.text[f.pos]
+		if !inFlow {
+			continue
+		}
+		// Brackets of a scalar, e.g. of "hidden[0]", don't end it.
+		if c == '[' {
+			depth++
+		} else if c == ']' && depth > 0 {
+			depth--
+		} else if c == ',' || c == ']' || c == '}' || c == ':' && (f.pos+1 == len(f.text) || f.text[f.pos+1] == ' ') {
+			break
+		}
+	}
+	return plainValue(strings.TrimSpace(f.text[start:f.pos]))
+}
+
+// plainValue is a null, a boolean, a number or a string. Numbers are kept as they are
+// written, so large seeds are exact.
+func plainValue(text string) interface{} {
+	switch text {
+	case "", "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	}
+	if json.Valid([]byte(text)) {
+		if _, err := strconv.ParseFloat(text, 64); err == nil {
+			return json.Number(text)
+		}
+	}
+	if v, err := strconv.ParseFloat(strings.TrimPrefix(text, "+"), 64); err == nil && !math.IsInf(v, 0) && !math.IsNaN(v) && !strings.ContainsAny(text, "xXpP_") {
+		return json.Number(strconv.FormatFloat(v, 'g', -1, 64))
+	}
+	return text
+}
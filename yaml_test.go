@@ -0,0 +1,90 @@
+package goDeep
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadModelConfigYAML(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		want    ModelConfig
+		wantErr bool
+	}{
+		{
+			name: "declaration",
+			yaml: `---
+# A declaration of ReadModelConfig in YAML.
+input: {size: 3, learningRate: 0.1, bias: 1, initializer: xavier}
+hidden:
+- size: 4
+  learningRate: .1   # a leading dot
+  bias: 1
+  activation: "sigmoid"
+  initializer: 'he'
+output:
+  size: 1
+  activation: sigmoid
+  cost: quadratic
+seed: 9007199254740993
+training:
+  epochs: 10
+  batchSize: 4
+  optimizer: sgd
+  schedule:
+    name: warmup
+    unit: batch
+    steps: 5
+    then: {name: exponential, gamma: 0.9}
+  clipping: {globalNorm: 5}
+  rateMultipliers: {hidden[0]: 2, "input.synapses": 0.5}
+`,
+			want: ModelConfig{
+				Input:  &LayerConfig{Size: 3, LearningRate: .1, Bias: 1, Initializer: "xavier"},
+				Hidden: []LayerConfig{{Size: 4, LearningRate: .1, Bias: 1, Activation: "sigmoid", Initializer: "he"}},
+				Output: LayerConfig{Size: 1, Activation: "sigmoid", Cost: "quadratic"},
+				Seed:   9007199254740993,
+				Training: &TrainingConfig{
+					Epochs:    10,
+					BatchSize: 4,
+					Optimizer: "sgd",
+					Schedule: &ScheduleConfig{
+						Name:  "warmup",
+						Unit:  "batch",
+						Steps: 5,
+						Then:  &ScheduleConfig{Name: "exponential", Gamma: .9},
+					},
+					Clipping:        &Clipping{GlobalNorm: 5},
+					RateMultipliers: map[string]float64{"hidden[0]": 2, "input.synapses": .5},
+				},
+			},
+		},
+		{
+			name: "nestedSequences",
+			yaml: "hidden:\n  -\n    size: 2\n  - {size: 3}\noutput: {size: 1}\n",
+			want: ModelConfig{Hidden: []LayerConfig{{Size: 2}, {Size: 3}}, Output: LayerConfig{Size: 1}},
+		},
+		{name: "unknownField", yaml: "input:\n  size: 3\n  rate: 0.1\n", wantErr: true},
+		{name: "tabs", yaml: "input:\n\tsize: 3\n", wantErr: true},
+		{name: "indentation", yaml: "input:\n    size: 3\n  bias: 1\n", wantErr: true},
+		{name: "duplicateKey", yaml: "seed: 1\nseed: 2\n", wantErr: true},
+		{name: "blockScalar", yaml: "output:\n  cost: |\n    quadratic\n", wantErr: true},
+		{name: "alias", yaml: "input: &layer {size: 3}\n", wantErr: true},
+		{name: "unclosedFlow", yaml: "input: {size: 3\n", wantErr: true},
+		{name: "documents", yaml: "seed: 1\n---\nseed: 2\n", wantErr: true},
+		{name: "sequence", yaml: "- seed: 1\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadModelConfigYAML(strings.NewReader(tt.yaml))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadModelConfigYAML() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadModelConfigYAML() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}